@@ -14,11 +14,17 @@ import { initKuzu, loadGraphToKuzu, getKuzuStats, executeQuery, executeWithReuse
 // loaded when embeddings are not requested. This avoids crashes on Node
 // versions whose ABI is not yet supported by the native binary (#89).
 // disposeEmbedder intentionally not called — ONNX Runtime segfaults on cleanup (see #38)
-import { getStoragePaths, saveMeta, loadMeta, addToGitignore, registerRepo, getGlobalRegistryPath } from '../storage/repo-manager.js';
-import { getCurrentCommit, isGitRepo, getGitRoot } from '../storage/git.js';
+import { getStoragePaths, getCommitStoragePaths, saveMeta, loadMeta, addToGitignore, registerRepo, getGlobalRegistryPath, sameIndexOptions, IndexOptions } from '../storage/repo-manager.js';
+import { getCurrentCommit, isGitRepo, getGitRoot, resolveCommit } from '../storage/git.js';
+import { createCommitSource } from '../core/ingestion/filesystem-walker.js';
 import { generateAIContextFiles } from './ai-context.js';
 import fs from 'fs/promises';
 import { registerClaudeHook } from './claude-hooks.js';
+import { writeGraphJSON } from '../core/graph/json-export.js';
+import type { SymbolIdScheme } from '../core/graph/symbol-ids.js';
+import { writeCallGraphDOT } from '../core/graph/dot-export.js';
+import { searchSymbolsByName } from '../core/search/name-search.js';
+import { createWriteStream } from 'fs';
 
 const HEAP_MB = 8192;
 const HEAP_FLAG = `--max-old-space-size=${HEAP_MB}`;
@@ -45,6 +51,37 @@ function ensureHeap(): boolean {
 export interface AnalyzeOptions {
   force?: boolean;
   embeddings?: boolean;
+  /** Also write the full graph as JSON to this path */
+  exportJson?: string;
+  /** Node ids in the JSON export: 'path' (default) or 'qualified' (see symbol-ids) */
+  idScheme?: string;
+  /** Also write the call graph as Graphviz DOT to this path */
+  exportDot?: string;
+  /** Limit the DOT export to what this symbol (name or node id) calls */
+  dotRoot?: string;
+  /** Max call depth from dotRoot (commander passes the raw string) */
+  dotDepth?: string;
+  /** Analyze this commit from the object store instead of the working tree; indexed under .gitnexus/commits/<sha> */
+  commit?: string;
+  /** Parse worker threads (commander passes the raw string); 0 = sequential */
+  workers?: string;
+  /** Only index paths matching these globs */
+  include?: string[];
+  /** Skip paths matching these globs (wins over include) */
+  exclude?: string[];
+  /** Skip files marked `// Code generated ... DO NOT EDIT.` */
+  skipGenerated?: boolean;
+  /** Target GOOS/GOARCH for Go build constraints (default: the host) */
+  goos?: string;
+  goarch?: string;
+  /** Go build tags, comma-separated as for `go build -tags` */
+  tags?: string;
+  /** Index every build variant, tagging symbols with their constraint */
+  allVariants?: boolean;
+  /** Only analyze this subtree */
+  root?: string;
+  /** Only analyze Go packages matching these `go list` patterns */
+  packages?: string[];
 }
 
 /** Threshold: auto-skip embeddings for repos with more nodes than this */
@@ -93,11 +130,55 @@ export const analyzeCommand = async (
     return;
   }
 
-  const { storagePath, kuzuPath } = getStoragePaths(repoPath);
-  const currentCommit = getCurrentCommit(repoPath);
+  if (options?.idScheme && options.idScheme !== 'path' && options.idScheme !== 'qualified') {
+    console.log(`  Unknown id scheme: ${options.idScheme} (expected path or qualified)\n`);
+    process.exitCode = 1;
+    return;
+  }
+
+  if (options?.workers !== undefined && !/^\d+$/.test(options.workers)) {
+    console.log(`  Invalid --workers: ${options.workers} (expected a non-negative integer)\n`);
+    process.exitCode = 1;
+    return;
+  }
+
+  if (options?.dotDepth !== undefined && !/^[1-9]\d*$/.test(options.dotDepth)) {
+    console.log(`  Invalid --dot-depth: ${options.dotDepth} (expected a positive integer)\n`);
+    process.exitCode = 1;
+    return;
+  }
+
+  let currentCommit = getCurrentCommit(repoPath);
+  if (options?.commit) {
+    try {
+      currentCommit = resolveCommit(repoPath, options.commit);
+    } catch {
+      console.log(`  Unknown commit: ${options.commit}\n`);
+      process.exitCode = 1;
+      return;
+    }
+  }
+  // A past commit gets its own index, so the working tree's stays as it is
+  const { storagePath, kuzuPath } = options?.commit
+    ? getCommitStoragePaths(repoPath, currentCommit)
+    : getStoragePaths(repoPath);
   const existingMeta = await loadMeta(storagePath);
 
-  if (existingMeta && !options?.force && existingMeta.lastCommit === currentCommit) {
+  // A scoped or build-constrained index doesn't stand in for a full one, and vice versa
+  const indexOptions: IndexOptions = {
+    ...(options?.root ? { root: options.root } : {}),
+    ...(options?.packages?.length ? { packages: options.packages } : {}),
+    ...(options?.include?.length ? { include: options.include } : {}),
+    ...(options?.exclude?.length ? { exclude: options.exclude } : {}),
+    ...(options?.skipGenerated ? { skipGenerated: true } : {}),
+    ...(options?.goos ? { goos: options.goos } : {}),
+    ...(options?.goarch ? { goarch: options.goarch } : {}),
+    ...(options?.tags ? { tags: options.tags.split(',') } : {}),
+    ...(options?.allVariants ? { allVariants: true } : {}),
+  };
+
+  if (existingMeta && !options?.force && !options?.exportJson && !options?.exportDot
+    && existingMeta.lastCommit === currentCommit && sameIndexOptions(existingMeta.indexOptions, indexOptions)) {
     console.log('  Already up to date\n');
     return;
   }
@@ -188,7 +269,69 @@ export const analyzeCommand = async (
     const phaseLabel = PHASE_LABELS[progress.phase] || progress.phase;
     const scaled = Math.round(progress.percent * 0.6);
     updateBar(scaled, phaseLabel);
+  }, {
+    ...(options?.commit ? { commit: currentCommit } : {}),
+    ...(options?.workers !== undefined ? { workers: parseInt(options.workers, 10) } : {}),
+    scan: {
+      ...(options?.include ? { include: options.include } : {}),
+      ...(options?.exclude ? { exclude: options.exclude } : {}),
+      ...(options?.skipGenerated ? { skipGenerated: true } : {}),
+      build: {
+        ...(options?.goos ? { goos: options.goos } : {}),
+        ...(options?.goarch ? { goarch: options.goarch } : {}),
+        ...(options?.tags ? { tags: options.tags.split(',') } : {}),
+        ...(options?.allVariants ? { allVariants: true } : {}),
+      },
+    },
+    ...(options?.root || options?.packages ? {
+      scope: {
+        ...(options.root ? { root: options.root } : {}),
+        ...(options.packages ? { packages: options.packages } : {}),
+      },
+    } : {}),
   });
+  const commitSource = options?.commit ? createCommitSource(repoPath, currentCommit) : null;
+
+  if (options?.exportJson) {
+    updateBar(60, 'Writing JSON export...');
+    const out = createWriteStream(path.resolve(options.exportJson), 'utf-8');
+    await writeGraphJSON(pipelineResult.graph, out, {
+      repoPath,
+      commit: currentCommit,
+      externalCalls: pipelineResult.externalCalls,
+      ...(options.idScheme ? { idScheme: options.idScheme as SymbolIdScheme } : {}),
+    });
+    await new Promise<void>((resolve, reject) => {
+      out.on('error', reject);
+      out.end(() => resolve());
+    });
+  }
+
+  if (options?.exportDot) {
+    updateBar(60, 'Writing DOT export...');
+    const graph = pipelineResult.graph;
+    let root: string | undefined;
+    if (options.dotRoot) {
+      root = graph.getNode(options.dotRoot)
+        ? options.dotRoot
+        : searchSymbolsByName(graph, options.dotRoot, { limit: 1 }).find(r => r.matchType === 'exact')?.id;
+      if (!root) {
+        bar.stop();
+        console.log(`  Unknown symbol: ${options.dotRoot}\n`);
+        process.exitCode = 1;
+        return;
+      }
+    }
+    const out = createWriteStream(path.resolve(options.exportDot), 'utf-8');
+    await writeCallGraphDOT(graph, out, {
+      ...(root ? { root } : {}),
+      ...(options.dotDepth !== undefined ? { depth: parseInt(options.dotDepth, 10) } : {}),
+    });
+    await new Promise<void>((resolve, reject) => {
+      out.on('error', reject);
+      out.end(() => resolve());
+    });
+  }
 
   // ── Phase 2: KuzuDB (60–85%) ──────────────────────────────────────
   updateBar(60, 'Loading into KuzuDB...');
@@ -206,7 +349,7 @@ export const analyzeCommand = async (
     kuzuMsgCount++;
     const progress = Math.min(84, 60 + Math.round((kuzuMsgCount / (kuzuMsgCount + 10)) * 24));
     updateBar(progress, msg);
-  });
+  }, commitSource?.readFile);
   const kuzuTime = ((Date.now() - t0Kuzu) / 1000).toFixed(1);
   const kuzuWarnings = kuzuResult.warnings;
 
@@ -280,6 +423,7 @@ export const analyzeCommand = async (
     repoPath,
     lastCommit: currentCommit,
     indexedAt: new Date().toISOString(),
+    ...(Object.keys(indexOptions).length > 0 ? { indexOptions } : {}),
     stats: {
       files: pipelineResult.totalFileCount,
       nodes: stats.nodes,
@@ -289,10 +433,14 @@ export const analyzeCommand = async (
     },
   };
   await saveMeta(storagePath, meta);
-  await registerRepo(repoPath, meta);
-  await addToGitignore(repoPath);
+  // The registry, .gitignore, hooks and context files describe the working
+  // tree's index; a commit analysis leaves them (and the repo) untouched
+  if (!options?.commit) {
+    await registerRepo(repoPath, meta);
+    await addToGitignore(repoPath);
+  }
 
-  const hookResult = await registerClaudeHook();
+  const hookResult = options?.commit ? { registered: false, message: '' } : await registerClaudeHook();
 
   const projectName = path.basename(repoPath);
   let aggregatedClusterCount = 0;
@@ -305,7 +453,7 @@ export const analyzeCommand = async (
     aggregatedClusterCount = Array.from(groups.values()).filter(count => count >= 5).length;
   }
 
-  const aiContext = await generateAIContextFiles(repoPath, storagePath, projectName, {
+  const aiContext = options?.commit ? { files: [] as string[] } : await generateAIContextFiles(repoPath, storagePath, projectName, {
     files: pipelineResult.totalFileCount,
     nodes: stats.nodes,
     edges: stats.edges,
@@ -336,7 +484,10 @@ export const analyzeCommand = async (
   console.log(`\n  Repository indexed successfully (${totalTime}s)${embeddingsCached ? ` [${cachedEmbeddings.length} embeddings cached]` : ''}\n`);
   console.log(`  ${stats.nodes.toLocaleString()} nodes | ${stats.edges.toLocaleString()} edges | ${pipelineResult.communityResult?.stats.totalCommunities || 0} clusters | ${pipelineResult.processResult?.stats.totalProcesses || 0} flows`);
   console.log(`  KuzuDB ${kuzuTime}s | FTS ${ftsTime}s | Embeddings ${embeddingSkipped ? embeddingSkipReason : embeddingTime + 's'}`);
-  console.log(`  ${repoPath}`);
+  console.log(`  ${repoPath}${options?.commit ? ` @ ${currentCommit.substring(0, 12)} (index: ${storagePath})` : ''}`);
+  if (options?.exportJson) {
+    console.log(`  JSON: ${path.resolve(options.exportJson)}`);
+  }
 
   if (aiContext.files.length > 0) {
     console.log(`  Context: ${aiContext.files.join(', ')}`);
@@ -346,6 +497,16 @@ export const analyzeCommand = async (
     console.log(`  Hooks: ${hookResult.message}`);
   }
 
+  const parseErrors = pipelineResult.parseErrors ?? [];
+  const failedFiles = parseErrors.filter(e => e.stage !== 'syntax' && e.stage !== 'annotate');
+  const annotateErrors = parseErrors.filter(e => e.stage === 'annotate').length;
+  if (parseErrors.length > 0) {
+    const syntaxErrors = parseErrors.length - failedFiles.length - annotateErrors;
+    console.log(`  Parse: ${failedFiles.length} files skipped, ${syntaxErrors} with syntax errors${annotateErrors ? `, ${annotateErrors} annotator failures` : ''}`);
+    for (const e of failedFiles.slice(0, 5)) console.log(`    ${e.filePath} (${e.stage}): ${e.message}`);
+    if (failedFiles.length > 5) console.log(`    ... and ${failedFiles.length - 5} more`);
+  }
+
   // Show a quiet summary if some edge types needed fallback insertion
   if (kuzuWarnings.length > 0) {
     const totalFallback = kuzuWarnings.reduce((sum, w) => {
@@ -0,0 +1,102 @@
+/**
+ * Diff Command
+ *
+ * Indexes two commits straight from the git object store and reports the
+ * symbols added, removed, and modified between them.
+ *
+ * Usage: gitnexus diff <base> [head] [--json] [--breaking]
+ *
+ * With --breaking, only exported API changes are listed, each classified as
+ * breaking or not, and the exit code is 1 if any are breaking.
+ */
+
+import { diffGraphs, GraphDiff, DiffSymbol } from '../core/graph/graph-diff.js';
+import { detectBreakingChanges } from '../core/graph/breaking-changes.js';
+import { getGitRoot, resolveCommit } from '../storage/git.js';
+import { loadOrBuild } from '../storage/graph-cache.js';
+
+export interface DiffOptions {
+  /** Print the full diff as JSON instead of a summary */
+  json?: boolean;
+  /** Report classified API changes and fail on breaking ones */
+  breaking?: boolean;
+}
+
+const describe = (symbol: DiffSymbol): string =>
+  `${symbol.kind} ${symbol.key}${symbol.isExported ? '' : ' (unexported)'}`;
+
+/** "3 exported signatures changed, 1 exported symbol removed" */
+export const summarizeDiff = (diff: GraphDiff): string => {
+  const plural = (n: number, word: string) => `${n} ${word}${n === 1 ? '' : 's'}`;
+  const exportedSignatures = diff.modified.filter(m => m.isExported && m.signatureChanged).length;
+  const exportedRemoved = diff.removed.filter(s => s.isExported).length;
+  const exportedAdded = diff.added.filter(s => s.isExported).length;
+  return [
+    `${plural(exportedSignatures, 'exported signature')} changed`,
+    `${plural(exportedRemoved, 'exported symbol')} removed`,
+    `${plural(exportedAdded, 'exported symbol')} added`,
+    `${plural(diff.modified.length, 'symbol')} modified in total`,
+  ].join(', ');
+};
+
+export const diffCommand = async (base: string, head: string = 'HEAD', options?: DiffOptions) => {
+  const repoPath = getGitRoot(process.cwd());
+  if (!repoPath) {
+    console.log('  Not inside a git repository\n');
+    process.exitCode = 1;
+    return;
+  }
+
+  const resolved: string[] = [];
+  for (const ref of [base, head]) {
+    try {
+      resolved.push(resolveCommit(repoPath, ref));
+    } catch {
+      console.log(`  Unknown commit: ${ref}\n`);
+      process.exitCode = 1;
+      return;
+    }
+  }
+  const [baseCommit, headCommit] = resolved;
+
+  // Cached per commit, so repeated diffs against the same base are cheap
+  const { result: baseResult } = await loadOrBuild(repoPath, baseCommit);
+  const { result: headResult } = await loadOrBuild(repoPath, headCommit);
+
+  if (options?.breaking) {
+    const changes = detectBreakingChanges(baseResult.graph, headResult.graph);
+    const breakingCount = changes.filter(c => c.breaking).length;
+    if (breakingCount > 0) process.exitCode = 1;
+    if (options.json) {
+      console.log(JSON.stringify({ base: baseCommit, head: headCommit, changes }, null, 2));
+      return;
+    }
+    console.log(`\n  ${baseCommit.slice(0, 12)}..${headCommit.slice(0, 12)}\n`);
+    for (const change of changes) {
+      console.log(`  ${change.breaking ? '!' : ' '} ${change.key} [${change.change}]`);
+      console.log(`      ${change.detail}`);
+    }
+    console.log(`\n  ${breakingCount} breaking, ${changes.length - breakingCount} non-breaking API changes\n`);
+    return;
+  }
+
+  const diff = diffGraphs(baseResult.graph, headResult.graph);
+
+  if (options?.json) {
+    console.log(JSON.stringify({ base: baseCommit, head: headCommit, ...diff }, null, 2));
+    return;
+  }
+
+  console.log(`\n  ${baseCommit.slice(0, 12)}..${headCommit.slice(0, 12)}\n`);
+  for (const symbol of diff.removed) console.log(`  - ${describe(symbol)}`);
+  for (const symbol of diff.added) console.log(`  + ${describe(symbol)}`);
+  for (const change of diff.modified) {
+    const what = change.signatureChanged ? 'signature' : 'body';
+    console.log(`  ~ ${describe(change.head)} [${what}]`);
+    if (change.signatureChanged) {
+      console.log(`      ${change.base.signature}`);
+      console.log(`   -> ${change.head.signature}`);
+    }
+  }
+  console.log(`\n  ${summarizeDiff(diff)}\n`);
+};
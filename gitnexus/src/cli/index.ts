@@ -32,6 +32,7 @@ import { augmentCommand } from './augment.js';
 import { wikiCommand } from './wiki.js';
 import { queryCommand, contextCommand, impactCommand, cypherCommand } from './tool.js';
 import { evalServerCommand } from './eval-server.js';
+import { diffCommand } from './diff.js';
 import { createRequire } from 'node:module';
 const _require = createRequire(import.meta.url);
 const pkg = _require('../../package.json');
@@ -52,8 +53,31 @@ program
   .description('Index a repository (full analysis)')
   .option('-f, --force', 'Force full re-index even if up to date')
   .option('--embeddings', 'Enable embedding generation for semantic search (off by default)')
+  .option('--export-json <file>', 'Also write the full symbol graph (nodes + edges) as JSON')
+  .option('--id-scheme <scheme>', 'Node ids in the JSON export: path (default) or qualified (importpath.Type.Method)')
+  .option('--export-dot <file>', 'Also write the call graph as Graphviz DOT, clustered by package')
+  .option('--dot-root <symbol>', 'Limit the DOT export to calls reachable from this symbol')
+  .option('--dot-depth <n>', 'Max call depth from --dot-root')
+  .option('--commit <ref>', 'Analyze a commit from git history without checking it out')
+  .option('--workers <n>', 'Parse worker threads (0 parses on the main thread)')
+  .option('--include <glob...>', 'Only index paths matching these globs')
+  .option('--exclude <glob...>', 'Skip paths matching these globs (wins over --include)')
+  .option('--skip-generated', 'Skip files with a "Code generated ... DO NOT EDIT." header')
+  .option('--goos <os>', 'Target GOOS for Go build constraints (default: host)')
+  .option('--goarch <arch>', 'Target GOARCH for Go build constraints (default: host)')
+  .option('--tags <list>', 'Comma-separated Go build tags')
+  .option('--all-variants', 'Index every Go build variant and tag symbols with their constraints')
+  .option('--root <dir>', 'Only analyze this subtree (e.g. internal/auth)')
+  .option('--packages <pattern...>', 'Only analyze Go packages matching these go list patterns (e.g. ./internal/auth/...)')
   .action(analyzeCommand);
 
+program
+  .command('diff <base> [head]')
+  .description('Compare the symbols of two commits (head defaults to HEAD)')
+  .option('--json', 'Print the full diff as JSON')
+  .option('--breaking', 'Classify exported API changes; exit 1 if any are breaking')
+  .action(diffCommand);
+
 program
   .command('serve')
   .description('Start local HTTP server for web UI connection')
@@ -29,5 +29,6 @@ export const statusCommand = async () => {
   console.log(`Indexed: ${new Date(repo.meta.indexedAt).toLocaleString()}`);
   console.log(`Indexed commit: ${repo.meta.lastCommit?.slice(0, 7)}`);
   console.log(`Current commit: ${currentCommit?.slice(0, 7)}`);
+  if (repo.meta.indexOptions) console.log(`Index options: ${JSON.stringify(repo.meta.indexOptions)} (partial index)`);
   console.log(`Status: ${isUpToDate ? '✅ up-to-date' : '⚠️ stale (re-run gitnexus analyze)'}`);
 };
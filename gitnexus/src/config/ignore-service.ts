@@ -237,3 +237,111 @@ export const shouldIgnorePath = (filePath: string): boolean => {
   return false;
 }
 
+
+// ============================================================================
+// USER FILTERS
+// ============================================================================
+
+/**
+ * Glob pattern as a RegExp over repo-relative paths (`/`-separated).
+ *   `*` and `?` stay within one segment, `**` spans segments, `[...]` and
+ *   `{a,b}` work as in the shell.
+ *   A pattern without `/` matches at any depth (`*_gen.go`); one with `/` is
+ *   anchored at the repo root (`vendor/**`, `/internal/mock`).
+ *   A pattern that matches a directory matches everything under it, so
+ *   `vendor` and `vendor/**` are equivalent.
+ */
+export const globToRegExp = (pattern: string): RegExp => {
+  let glob = pattern.trim().replace(/\\/g, '/').replace(/^\.\//, '');
+  const anywhere = !glob.replace(/\/+$/, '').includes('/');
+  glob = glob.replace(/^\/+/, '').replace(/\/+$/, '');
+
+  let source = '';
+  for (let i = 0; i < glob.length; i++) {
+    const c = glob[i];
+    if (c === '*') {
+      if (glob[i + 1] === '*') {
+        const atSegmentStart = i === 0 || glob[i - 1] === '/';
+        const atSegmentEnd = i + 2 === glob.length || glob[i + 2] === '/';
+        if (atSegmentStart && glob[i + 2] === '/') {
+          source += '(?:.*/)?';
+          i += 2;
+        } else if (atSegmentStart && atSegmentEnd) {
+          source += '.*';
+          i += 1;
+        } else {
+          source += '[^/]*';
+          i += 1;
+        }
+      } else {
+        source += '[^/]*';
+      }
+    } else if (c === '?') {
+      source += '[^/]';
+    } else if (c === '[') {
+      const close = glob.indexOf(']', i + 2);
+      if (close < 0) {
+        source += '\\[';
+      } else {
+        const body = glob.substring(i + 1, close).replace(/^!/, '^').replace(/\\/g, '\\\\');
+        source += `[${body}]`;
+        i = close;
+      }
+    } else if (c === '{') {
+      const close = glob.indexOf('}', i);
+      if (close < 0) {
+        source += '\\{';
+      } else {
+        const alternatives = glob.substring(i + 1, close).split(',')
+          .map(alt => alt.replace(/[.+^$()|\\]/g, '\\$&').replace(/\*/g, '[^/]*').replace(/\?/g, '[^/]'));
+        source += `(?:${alternatives.join('|')})`;
+        i = close;
+      }
+    } else {
+      source += c.replace(/[.+^$()|\\\]}]/g, '\\$&');
+    }
+  }
+  return new RegExp(`^${anywhere ? '(?:.*/)?' : ''}${source}(?:/.*)?$`);
+};
+
+export interface PathFilterOptions {
+  /** Keep only paths matching at least one pattern (all paths when empty) */
+  include?: string[];
+  /** Drop paths matching any pattern */
+  exclude?: string[];
+}
+
+/**
+ * Predicate for user include/exclude globs (see globToRegExp). Exclude wins:
+ * a path matching both an include and an exclude pattern is dropped. This
+ * runs after the built-in ignore rules, so include can't bring back e.g.
+ * node_modules.
+ */
+export const createPathFilter = (options: PathFilterOptions): ((filePath: string) => boolean) => {
+  const include = (options.include ?? []).filter(p => p.trim()).map(globToRegExp);
+  const exclude = (options.exclude ?? []).filter(p => p.trim()).map(globToRegExp);
+  return (filePath: string) => {
+    const normalized = filePath.replace(/\\/g, '/');
+    if (include.length > 0 && !include.some(re => re.test(normalized))) return false;
+    return !exclude.some(re => re.test(normalized));
+  };
+};
+
+/** Go's marker for generated files (https://go.dev/s/generatedcode) */
+const GENERATED_HEADER = /^\/\/ Code generated .* DO NOT EDIT\.$/m;
+
+/** Bytes of a file's head checked for the generated-code marker */
+export const GENERATED_HEADER_SCAN_BYTES = 4096;
+
+/**
+ * True if the file carries the standard `// Code generated ... DO NOT EDIT.`
+ * line. Go requires it before the package clause, so for Go only that part
+ * counts; other languages' generators (protoc, sqlc, ...) put it in the
+ * opening lines, which are all that's checked.
+ */
+export const isGeneratedSource = (content: string): boolean => {
+  let head = content.length > GENERATED_HEADER_SCAN_BYTES ? content.substring(0, GENERATED_HEADER_SCAN_BYTES) : content;
+  const packageClause = /^package\s/m.exec(head);
+  if (packageClause) head = head.substring(0, packageClause.index);
+  return GENERATED_HEADER.test(head.replace(/\r\n/g, '\n'));
+};
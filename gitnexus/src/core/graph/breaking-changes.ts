@@ -0,0 +1,216 @@
+/**
+ * Breaking Changes
+ *
+ * Release-gating view of a graph diff: changes to the exported Go API,
+ * each classified as breaking (code that compiled against base may not
+ * compile against head) or not.
+ *
+ * Public API: exported package-level names, plus exported methods and fields
+ * of exported types. Rules, roughly following Go's compatibility guidelines:
+ *   breaking      removing a symbol; any change to a func's parameters,
+ *                 results, or type parameters (arity changes are reported
+ *                 as parameter-added / parameter-removed, return-only
+ *                 changes as result-changed); changing an
+ *                 exported field's type or removing it; adding or removing
+ *                 interface methods (implementers or callers break); moving
+ *                 a method from a value to a pointer receiver (T loses it);
+ *                 changing a constant's value or type, a var's type, or a
+ *                 named type's underlying type; changing a symbol's kind
+ *   non-breaking  adding a symbol; adding a field to a struct that already
+ *                 has unexported fields; pointer -> value receiver; tag or
+ *                 body-only changes
+ * Adding any field to a non-empty struct whose fields are all exported is
+ * breaking: other packages may build it with an unkeyed literal (`T{a, b}`).
+ *
+ * Methods promoted through embedding are not expanded; a method set that
+ * narrows because an embedded field was removed shows up as the field
+ * removal.
+ */
+
+import { KnowledgeGraph, GraphNode, StructField } from './types.js';
+import { diffGraphs, indexSymbolsByKey, DiffSymbol } from './graph-diff.js';
+import { parseSignature } from './signatures.js';
+
+export type ApiChangeKind =
+  | 'removed'
+  | 'added'
+  | 'kind-changed'
+  | 'parameter-added'
+  | 'parameter-removed'
+  | 'result-changed'
+  | 'signature-changed'
+  | 'type-params-changed'
+  | 'receiver-changed'
+  | 'field-added'
+  | 'field-removed'
+  | 'field-type-changed'
+  | 'field-tag-changed'
+  | 'interface-method-added'
+  | 'interface-method-removed'
+  | 'value-changed'
+  | 'type-changed';
+
+export interface ApiChange {
+  /** Cross-commit symbol key (see graph-diff) */
+  key: string;
+  name: string;
+  filePath: string;
+  change: ApiChangeKind;
+  breaking: boolean;
+  /** Human-readable specifics (`Foo(int) -> Foo(int, string)`) */
+  detail: string;
+}
+
+const isExportedName = (name: string | undefined): boolean =>
+  !!name && name.charAt(0) !== name.charAt(0).toLowerCase();
+
+/** Part of the package's public API */
+const isPublic = (node: GraphNode): boolean => {
+  const { name, receiverType, language } = node.properties;
+  if (language !== 'go') return node.properties.isExported ?? false;
+  return isExportedName(name) && (node.label !== 'Method' || isExportedName(receiverType));
+};
+
+const formatTypeParams = (node: GraphNode): string =>
+  (node.properties.typeParams ?? []).map(t => `${t.name} ${t.constraint}`).join(', ');
+
+const compareFuncs = (base: GraphNode, head: GraphNode, push: (change: ApiChangeKind, breaking: boolean, detail: string) => void) => {
+  const before = base.properties.signature ?? '';
+  const after = head.properties.signature ?? '';
+  if (formatTypeParams(base) !== formatTypeParams(head)) {
+    push('type-params-changed', true, `[${formatTypeParams(base)}] -> [${formatTypeParams(head)}]`);
+  }
+  if (base.label === 'Method' && base.properties.receiverPointer !== head.properties.receiverPointer) {
+    const toPointer = !!head.properties.receiverPointer;
+    push('receiver-changed', toPointer,
+      `${toPointer ? '' : '*'}${base.properties.receiverType} -> ${toPointer ? '*' : ''}${head.properties.receiverType}`);
+  }
+  if (before === after) return;
+  const a = parseSignature(before);
+  const b = parseSignature(after);
+  if (a && b && b.params.length > a.params.length) push('parameter-added', true, `${before} -> ${after}`);
+  else if (a && b && b.params.length < a.params.length) push('parameter-removed', true, `${before} -> ${after}`);
+  else if (a && b && a.params.join(', ') === b.params.join(', ')) push('result-changed', true, `${before} -> ${after}`);
+  else push('signature-changed', true, `${before} -> ${after}`);
+};
+
+const fieldKey = (f: StructField): string => (f.embedded ? `(embedded) ${f.typeString}` : f.name);
+
+const compareStructs = (base: GraphNode, head: GraphNode, push: (change: ApiChangeKind, breaking: boolean, detail: string) => void) => {
+  const beforeFields = base.properties.fields ?? [];
+  const afterFields = head.properties.fields ?? [];
+  const before = new Map(beforeFields.map(f => [fieldKey(f), f]));
+  const after = new Map(afterFields.map(f => [fieldKey(f), f]));
+  // Unkeyed literals are only possible from outside when every field is
+  // exported, and only break when there was a field to list (`T{}` still compiles)
+  const unkeyedLiteralsPossible = beforeFields.length > 0 && beforeFields.every(f => f.exported);
+
+  for (const [key, field] of before) {
+    const now = after.get(key);
+    if (!now) {
+      if (field.exported) push('field-removed', true, `${key} ${field.typeString}`);
+      continue;
+    }
+    if (now.typeString !== field.typeString && field.exported) {
+      push('field-type-changed', true, `${key}: ${field.typeString} -> ${now.typeString}`);
+    }
+    if ((now.tag ?? '') !== (field.tag ?? '') && field.exported) {
+      push('field-tag-changed', false, `${key}: \`${field.tag ?? ''}\` -> \`${now.tag ?? ''}\``);
+    }
+  }
+  for (const [key, field] of after) {
+    if (before.has(key)) continue;
+    push('field-added', unkeyedLiteralsPossible,
+      `${key} ${field.typeString}${unkeyedLiteralsPossible ? ' (breaks unkeyed literals)' : ''}`);
+  }
+  if (formatTypeParams(base) !== formatTypeParams(head)) {
+    push('type-params-changed', true, `[${formatTypeParams(base)}] -> [${formatTypeParams(head)}]`);
+  }
+};
+
+const compareInterfaces = (base: GraphNode, head: GraphNode, push: (change: ApiChangeKind, breaking: boolean, detail: string) => void) => {
+  // Flattened method sets when available, so moving a method into an embedded interface isn't a change
+  const methodsOf = (n: GraphNode) => new Set(n.properties.methodSet ?? n.properties.methodSignatures ?? []);
+  const before = methodsOf(base);
+  const after = methodsOf(head);
+  for (const m of after) if (!before.has(m)) push('interface-method-added', true, m);
+  for (const m of before) if (!after.has(m)) push('interface-method-removed', true, m);
+  if (formatTypeParams(base) !== formatTypeParams(head)) {
+    push('type-params-changed', true, `[${formatTypeParams(base)}] -> [${formatTypeParams(head)}]`);
+  }
+};
+
+/**
+ * Exported API changes between two graphs, sorted by key then change.
+ * Body-only edits are not listed.
+ */
+export const detectBreakingChanges = (base: KnowledgeGraph, head: KnowledgeGraph): ApiChange[] => {
+  const diff = diffGraphs(base, head);
+  const baseNodes = indexSymbolsByKey(base);
+  const headNodes = indexSymbolsByKey(head);
+  const changes: ApiChange[] = [];
+
+  const record = (symbol: DiffSymbol, change: ApiChangeKind, breaking: boolean, detail: string) => {
+    changes.push({ key: symbol.key, name: symbol.name, filePath: symbol.filePath, change, breaking, detail });
+  };
+
+  for (const symbol of diff.removed) {
+    const node = baseNodes.get(symbol.key);
+    if (node && isPublic(node)) record(symbol, 'removed', true, `${symbol.kind} ${symbol.name}`);
+  }
+  for (const symbol of diff.added) {
+    const node = headNodes.get(symbol.key);
+    if (node && isPublic(node)) record(symbol, 'added', false, `${symbol.kind} ${symbol.name}`);
+  }
+
+  for (const modified of diff.modified) {
+    const baseNode = baseNodes.get(modified.key);
+    const headNode = headNodes.get(modified.key);
+    if (!baseNode || !headNode || !modified.signatureChanged) continue;
+    if (!isPublic(baseNode) && !isPublic(headNode)) continue;
+    const push = (change: ApiChangeKind, breaking: boolean, detail: string) =>
+      record(modified.head, change, breaking, detail);
+
+    if (!isPublic(headNode)) {
+      push('removed', true, `${baseNode.properties.name} is no longer exported`);
+      continue;
+    }
+    if (!isPublic(baseNode)) {
+      push('added', false, `${headNode.properties.name} is now exported`);
+      continue;
+    }
+    if (baseNode.label !== headNode.label) {
+      push('kind-changed', true, `${modified.base.kind} (${baseNode.label}) -> ${modified.head.kind} (${headNode.label})`);
+      continue;
+    }
+
+    switch (baseNode.label) {
+      case 'Function':
+      case 'Method':
+        compareFuncs(baseNode, headNode, push);
+        break;
+      case 'Struct':
+        compareStructs(baseNode, headNode, push);
+        break;
+      case 'Interface':
+        compareInterfaces(baseNode, headNode, push);
+        break;
+      case 'Const': {
+        const p = baseNode.properties;
+        const q = headNode.properties;
+        if ((p.declaredType ?? '') !== (q.declaredType ?? '')) {
+          push('type-changed', true, `${p.declaredType ?? '(untyped)'} -> ${q.declaredType ?? '(untyped)'}`);
+        }
+        const before = p.constValue ?? p.value;
+        const after = q.constValue ?? q.value;
+        if (before !== after) push('value-changed', true, `${before ?? '?'} -> ${after ?? '?'}`);
+        break;
+      }
+      default:
+        push('type-changed', true, `${modified.base.signature} -> ${modified.head.signature}`);
+    }
+  }
+
+  return changes.sort((a, b) =>
+    (a.key < b.key ? -1 : a.key > b.key ? 1 : 0) || (a.change < b.change ? -1 : a.change > b.change ? 1 : 0));
+};
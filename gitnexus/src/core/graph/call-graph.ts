@@ -0,0 +1,326 @@
+/**
+ * Call Graph
+ *
+ * CALLS relationships only exist between nodes in the graph, so calls into
+ * the standard library, third-party packages, repo packages outside the
+ * analysis scope, or anything the resolver couldn't pin down have no
+ * target node. Call processing records those as
+ * ExternalCall entries instead of dropping them; this module merges both
+ * into one caller -> callee edge list. Caller, test and recursion queries
+ * over the CALLS graph live here too.
+ */
+
+import { KnowledgeGraph, GoTestKind } from './types.js';
+import { findStronglyConnectedComponents, findCycleThrough } from './scc.js';
+import { isGoTestFile } from '../../lib/utils.js';
+
+/**
+ * A call whose target is not a node in the graph.
+ * - 'external-package': qualified by an import that isn't part of the repo
+ * - 'out-of-scope': into a repo package the analysis scope left out
+ *   (see analysis-scope)
+ * - 'unresolved': no matching definition was found (Go only; unmatched
+ *   calls in other languages aren't recorded)
+ */
+export interface ExternalCall {
+  /** Node id of the calling function (or File for top-level calls) */
+  sourceId: string;
+  filePath: string;
+  /** Callee as written at the call site, qualifier included (`fmt.Sprintf`) */
+  calleeName: string;
+  /** Import path for 'external-package' and 'out-of-scope' calls */
+  packagePath?: string;
+  reason: 'external-package' | 'out-of-scope' | 'unresolved';
+  /** Call-site rows (0-based, like startLine) */
+  callLines: number[];
+}
+
+export interface CallEdge {
+  callerId: string;
+  callerName: string;
+  /** Target node id — null for external calls */
+  calleeId: string | null;
+  calleeName: string;
+  /** True when the callee is outside the graph (other package or unresolved) */
+  external: boolean;
+  confidence: number;
+  reason: string;
+  /** Call-site rows in the caller's file (0-based) */
+  callLines: number[];
+}
+
+/** Dedup key for external calls: one entry per caller x callee text */
+export const externalCallKey = (call: ExternalCall): string =>
+  `${call.sourceId}->${call.calleeName}`;
+
+/**
+ * All caller -> callee pairs: resolved CALLS relationships plus the given
+ * external calls. Sorted by caller then callee for stable output.
+ */
+export const getCallEdges = (
+  graph: KnowledgeGraph,
+  externalCalls: Iterable<ExternalCall> = [],
+): CallEdge[] => {
+  const nameOf = (id: string): string => graph.getNode(id)?.properties.name ?? id;
+  const edges: CallEdge[] = [];
+
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'CALLS') return;
+    edges.push({
+      callerId: rel.sourceId,
+      callerName: nameOf(rel.sourceId),
+      calleeId: rel.targetId,
+      calleeName: nameOf(rel.targetId),
+      external: false,
+      confidence: rel.confidence,
+      reason: rel.reason,
+      callLines: rel.callLines ?? [],
+    });
+  });
+
+  for (const call of externalCalls) {
+    edges.push({
+      callerId: call.sourceId,
+      callerName: nameOf(call.sourceId),
+      calleeId: null,
+      calleeName: call.calleeName,
+      external: true,
+      confidence: call.reason === 'unresolved' ? 0 : 1.0,
+      reason: call.reason,
+      callLines: call.callLines,
+    });
+  }
+
+  return edges.sort((a, b) =>
+    a.callerId.localeCompare(b.callerId) || a.calleeName.localeCompare(b.calleeName));
+};
+
+// ============================================================================
+// CALLERS
+// ============================================================================
+
+export interface CallSite {
+  /** The symbol called from this site */
+  calleeId: string;
+  filePath: string;
+  /** 0-based row, like startLine */
+  line: number;
+}
+
+export interface CallerResult {
+  id: string;
+  name: string;
+  label: string;
+  filePath: string;
+  /** 1 = direct caller, 2 = caller of a direct caller, ... */
+  depth: number;
+  /** Where this caller calls into the previous level */
+  callSites: CallSite[];
+}
+
+/**
+ * Callers of a symbol, breadth-first over reversed CALLS edges.
+ *
+ * @param depth - 1 for direct callers, N for callers up to N hops away,
+ *   0 or negative for the full transitive closure
+ *
+ * Each caller is reported once, at the shortest distance. Recursion and
+ * mutual recursion terminate because visited symbols are never re-expanded;
+ * the symbol itself is only listed if it is reached through a cycle.
+ */
+export const findCallers = (
+  graph: KnowledgeGraph,
+  symbolId: string,
+  depth: number = 1,
+): CallerResult[] => {
+  const maxDepth = depth > 0 ? depth : Infinity;
+
+  const callersOf = new Map<string, { sourceId: string; lines: number[] }[]>();
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'CALLS') return;
+    let list = callersOf.get(rel.targetId);
+    if (!list) {
+      list = [];
+      callersOf.set(rel.targetId, list);
+    }
+    list.push({ sourceId: rel.sourceId, lines: rel.callLines ?? [] });
+  });
+
+  const results = new Map<string, CallerResult>();
+  const expanded = new Set<string>([symbolId]);
+  let frontier = [symbolId];
+
+  for (let level = 1; level <= maxDepth && frontier.length > 0; level++) {
+    const next: string[] = [];
+    for (const calleeId of frontier) {
+      for (const { sourceId, lines } of (callersOf.get(calleeId) ?? [])) {
+        let result = results.get(sourceId);
+        if (!result) {
+          const node = graph.getNode(sourceId);
+          result = {
+            id: sourceId,
+            name: node?.properties.name ?? sourceId,
+            label: node?.label ?? 'CodeElement',
+            filePath: node?.properties.filePath ?? '',
+            depth: level,
+            callSites: [],
+          };
+          results.set(sourceId, result);
+        }
+        // Only record sites that connect to the level directly below
+        if (result.depth === level) {
+          for (const line of lines) {
+            result.callSites.push({ calleeId, filePath: result.filePath, line });
+          }
+        }
+        if (!expanded.has(sourceId)) {
+          expanded.add(sourceId);
+          next.push(sourceId);
+        }
+      }
+    }
+    frontier = next;
+  }
+
+  return [...results.values()].sort((a, b) =>
+    a.depth - b.depth || a.filePath.localeCompare(b.filePath) || a.name.localeCompare(b.name));
+};
+
+// ============================================================================
+// TESTS
+// ============================================================================
+
+export interface TestReference {
+  id: string;
+  name: string;
+  filePath: string;
+  testKind: GoTestKind;
+  /** Test-file helpers between the test and the symbol, nearest the test first; empty for direct calls */
+  via: string[];
+}
+
+/**
+ * Go tests whose bodies call a symbol, directly or through helper functions
+ * declared in _test.go files. Calls through other production code don't
+ * count, so this is a "tests that exercise this function on purpose" map, not
+ * line coverage. Table-driven tests that dispatch through function values in
+ * a table only show up when the table's functions are called by name.
+ */
+export const findTestsFor = (graph: KnowledgeGraph, symbolId: string): TestReference[] => {
+  const callersOf = new Map<string, string[]>();
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'CALLS' || rel.sourceId === rel.targetId) return;
+    let list = callersOf.get(rel.targetId);
+    if (!list) {
+      list = [];
+      callersOf.set(rel.targetId, list);
+    }
+    list.push(rel.sourceId);
+  });
+
+  const results = new Map<string, TestReference>();
+  const visited = new Set<string>([symbolId]);
+  // Each entry: a symbol plus the helper chain from it back down to the target
+  let frontier: { id: string; via: string[] }[] = [{ id: symbolId, via: [] }];
+
+  while (frontier.length > 0) {
+    const next: { id: string; via: string[] }[] = [];
+    for (const { id, via } of frontier) {
+      for (const callerId of (callersOf.get(id) ?? [])) {
+        if (visited.has(callerId)) continue;
+        visited.add(callerId);
+        const caller = graph.getNode(callerId);
+        if (!caller || !isGoTestFile(caller.properties.filePath)) continue;
+        if (caller.properties.isTest && caller.properties.testKind) {
+          results.set(callerId, {
+            id: callerId,
+            name: caller.properties.name,
+            filePath: caller.properties.filePath,
+            testKind: caller.properties.testKind,
+            via,
+          });
+        } else {
+          next.push({ id: callerId, via: [callerId, ...via] });
+        }
+      }
+    }
+    frontier = next;
+  }
+
+  return [...results.values()].sort((a, b) =>
+    a.via.length - b.via.length || a.filePath.localeCompare(b.filePath) || a.name.localeCompare(b.name));
+};
+
+// ============================================================================
+// CYCLES
+// ============================================================================
+
+export interface CallCycleMember {
+  id: string;
+  name: string;
+  filePath: string;
+}
+
+export interface CallCycle {
+  /** 'self' for a function calling itself, 'mutual' for two or more */
+  kind: 'self' | 'mutual';
+  /** Symbols in the strongly connected component, sorted by id */
+  members: CallCycleMember[];
+  /**
+   * Concrete call cycles, each a list of node ids with the first repeated at
+   * the end. Together they pass through every member; the first is the
+   * shortest cycle through members[0].
+   */
+  paths: string[][];
+}
+
+/**
+ * Recursion and mutual recursion: strongly connected components of the
+ * CALLS graph with more than one symbol, plus symbols that call themselves.
+ * Uses the iterative SCC search, so deep call chains don't overflow the
+ * stack. Sorted by first member id.
+ */
+export const findCallCycles = (graph: KnowledgeGraph): CallCycle[] => {
+  const calls = new Map<string, Set<string>>();
+  const selfCalls = new Set<string>();
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'CALLS') return;
+    if (rel.sourceId === rel.targetId) selfCalls.add(rel.sourceId);
+    let targets = calls.get(rel.sourceId);
+    if (!targets) {
+      targets = new Set();
+      calls.set(rel.sourceId, targets);
+    }
+    targets.add(rel.targetId);
+  });
+
+  const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+  const successors = (id: string): Iterable<string> => calls.get(id) ?? [];
+  const member = (id: string): CallCycleMember => {
+    const node = graph.getNode(id);
+    return { id, name: node?.properties.name ?? id, filePath: node?.properties.filePath ?? '' };
+  };
+
+  const cycles: CallCycle[] = [];
+  for (const component of findStronglyConnectedComponents([...calls.keys()].sort(cmp), successors)) {
+    if (component.length === 1) {
+      const id = component[0];
+      if (selfCalls.has(id)) cycles.push({ kind: 'self', members: [member(id)], paths: [[id, id]] });
+      continue;
+    }
+    const ids = [...component].sort(cmp);
+    const inComponent = new Set(ids);
+    const covered = new Set<string>();
+    const paths: string[][] = [];
+    for (const id of ids) {
+      if (covered.has(id)) continue;
+      const path = findCycleThrough(id, inComponent, successors);
+      if (!path) continue;
+      paths.push(path);
+      for (const step of path) covered.add(step);
+    }
+    cycles.push({ kind: 'mutual', members: ids.map(member), paths });
+  }
+
+  return cycles.sort((a, b) => cmp(a.members[0].id, b.members[0].id));
+};
@@ -0,0 +1,113 @@
+/**
+ * Churn
+ *
+ * Change frequency per symbol: how many commits touched the lines a symbol
+ * occupies today. High-churn functions are refactoring and review hotspots.
+ *
+ * Approximation: git history has no notion of symbols, only line hunks. The
+ * walk starts from each symbol's current line range and goes back through
+ * first-parent history one commit at a time. A commit counts when one of its
+ * hunks overlaps the range (or deletes lines inside it). The range is then
+ * translated into the parent's line numbers by shifting it past the hunks
+ * above it and clamping its ends to the edges of hunks it overlaps. This
+ * follows code pushed up or down by edits elsewhere in the file, and follows
+ * file renames, but not code moved between files or reordered within one.
+ * Once a range shrinks to nothing (the lines were added by that commit)
+ * tracking stops for that symbol.
+ */
+
+import { KnowledgeGraph } from './types.js';
+import { getSymbolLineRange } from './symbol-authors.js';
+import { getCommitHistoryDiffs, CommitHistoryOptions, DiffHunk } from '../../storage/git.js';
+
+export type ChurnOptions = CommitHistoryOptions;
+
+interface TrackedRange {
+  symbolId: string;
+  /** 1-based inclusive, in the coordinates of the commit being examined */
+  start: number;
+  end: number;
+}
+
+const hunkTouches = (h: DiffHunk, r: TrackedRange): boolean => {
+  // Pure deletion: lines removed between newStart and newStart + 1
+  if (h.newCount === 0) return h.newStart >= r.start && h.newStart < r.end;
+  return h.newStart <= r.end && h.newStart + h.newCount - 1 >= r.start;
+};
+
+/**
+ * Map a line from post-image to pre-image numbering. Lines inside a hunk
+ * clamp to the hunk's old start (`side` 'start') or old end (`side` 'end').
+ */
+const mapToParent = (line: number, hunks: DiffHunk[], side: 'start' | 'end'): number => {
+  let offset = 0;
+  for (const h of hunks) {
+    const newEnd = h.newStart + h.newCount - 1;
+    if (h.newCount > 0 && line >= h.newStart && line <= newEnd) {
+      if (side === 'start') return h.oldCount === 0 ? h.oldStart + 1 : h.oldStart;
+      return h.oldCount === 0 ? h.oldStart : h.oldStart + h.oldCount - 1;
+    }
+    const before = h.newCount === 0 ? h.newStart < line : newEnd < line;
+    if (!before) break;
+    offset += h.oldCount - h.newCount;
+  }
+  return line + offset;
+};
+
+/**
+ * Commits touching each symbol, keyed by node id. Symbols never touched in
+ * the walked history are absent.
+ */
+export const computeChurn = (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  options: ChurnOptions = {},
+): Map<string, number> => {
+  const tracked = new Map<string, TrackedRange[]>();
+  graph.forEachNode(node => {
+    if (node.label === 'File' || node.label === 'Folder' || !node.properties.filePath) return;
+    const range = getSymbolLineRange(node);
+    if (!range) return;
+    let list = tracked.get(node.properties.filePath);
+    if (!list) {
+      list = [];
+      tracked.set(node.properties.filePath, list);
+    }
+    list.push({ symbolId: node.id, start: range[0] + 1, end: range[1] + 1 });
+  });
+
+  const churn = new Map<string, number>();
+  const history = getCommitHistoryDiffs(repoPath, options);
+
+  for (const commit of history) {
+    if (tracked.size === 0) break;
+    // Collect re-keys first so a swap of two paths doesn't clobber itself
+    const moves: [string, string | null, TrackedRange[]][] = [];
+    for (const file of commit.files) {
+      if (!file.path) continue;
+      const ranges = tracked.get(file.path);
+      if (!ranges) continue;
+
+      const hunks = [...file.hunks].sort((a, b) => a.newStart - b.newStart);
+      const survivors: TrackedRange[] = [];
+      for (const r of ranges) {
+        if (hunks.some(h => hunkTouches(h, r))) {
+          churn.set(r.symbolId, (churn.get(r.symbolId) ?? 0) + 1);
+        }
+        if (!file.oldPath) continue;
+        const start = mapToParent(r.start, hunks, 'start');
+        const end = mapToParent(r.end, hunks, 'end');
+        if (start <= end) survivors.push({ ...r, start, end });
+      }
+      moves.push([file.path, file.oldPath, survivors]);
+    }
+    for (const [path] of moves) tracked.delete(path);
+    for (const [, oldPath, survivors] of moves) {
+      if (oldPath && survivors.length > 0) {
+        tracked.set(oldPath, [...(tracked.get(oldPath) ?? []), ...survivors]);
+      }
+    }
+  }
+
+  return churn;
+};
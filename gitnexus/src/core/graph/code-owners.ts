@@ -0,0 +1,215 @@
+/**
+ * Code Owners
+ *
+ * Owning teams and users of the graph's symbols, from the repo's CODEOWNERS
+ * file. Where symbol-authors says who touched a symbol, this says who is
+ * responsible for it: for routing reviews, or drawing team boundaries over
+ * a dependency graph.
+ *
+ * A symbol is owned by its file. The file is read as GitHub does: the first
+ * of `.github/CODEOWNERS`, `CODEOWNERS`, `docs/CODEOWNERS`, at the commit
+ * the graph was built from. Each line is a pattern and its owners; the LAST
+ * matching line wins, so a later, narrower rule overrides an earlier one,
+ * and a matching line without owners leaves the path unowned. Patterns
+ * follow gitignore, with GitHub's restrictions:
+ *   `*.go`         no `/`: file name at any depth
+ *   `/cmd`         leading or inner `/`: anchored at the repo root
+ *   `apps/`        trailing `/`: that directory (at any depth) and all below
+ *   `docs`         a plain directory name also matches everything below it
+ *   `docs/*`       a wildcard last segment matches files directly in docs/
+ *                  only, not docs/guides/intro.md
+ *   `**` spans directories: `build/**`, or a leading `**` segment
+ * Negation (`!`) and character ranges (`[ ]`) aren't supported by GitHub;
+ * lines using them are reported invalid and skipped, as are lines with an
+ * owner that isn't `@user`, `@org/team` or an email address.
+ */
+
+import { KnowledgeGraph } from './types.js';
+import { readFilesAtCommit } from '../../storage/git.js';
+
+/** Where GitHub looks for the file, in order of precedence */
+export const CODEOWNERS_LOCATIONS = ['.github/CODEOWNERS', 'CODEOWNERS', 'docs/CODEOWNERS'];
+
+export interface CodeOwnersRule {
+  pattern: string;
+  /** `@user`, `@org/team` or email; empty for an explicitly unowned path */
+  owners: string[];
+  /** 0-based row in the CODEOWNERS file */
+  line: number;
+  regex: RegExp;
+}
+
+export interface CodeOwnersInvalidLine {
+  line: number;
+  text: string;
+  reason: string;
+}
+
+export interface CodeOwnersFile {
+  rules: CodeOwnersRule[];
+  invalid: CodeOwnersInvalidLine[];
+}
+
+export interface SymbolOwners {
+  /** Empty when no rule matches, or the matching rule lists no owners */
+  owners: string[];
+  /** The rule that decided, or null when none matched */
+  rule: { pattern: string; line: number } | null;
+}
+
+export interface CodeOwnersOptions {
+  /** Commit the graph was built from (default HEAD) */
+  ref?: string;
+  /** CODEOWNERS content to use instead of reading it from the repo */
+  content?: string;
+}
+
+const OWNER = /^(@[A-Za-z0-9][-A-Za-z0-9_.]*(\/[A-Za-z0-9][-A-Za-z0-9_.]*)?|[^@\s]+@[^@\s]+\.[^@\s]+)$/;
+
+const escapeRegex = (text: string): string => text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+
+/**
+ * CODEOWNERS pattern as a RegExp over repo-relative paths (see header).
+ * Directories are tested with a trailing `/` (`docs/`).
+ */
+export const codeOwnersPatternToRegExp = (pattern: string): RegExp => {
+  const directoryOnly = pattern.endsWith('/');
+  let glob = pattern.replace(/\/+$/, '');
+  const anchored = glob.includes('/');
+  glob = glob.replace(/^\/+/, '');
+
+  const segments = glob.split('/');
+  const last = segments[segments.length - 1];
+  // `docs/*` stops at files directly in docs/; `**` and plain names cover what is below
+  const descendants = last === '**' || !/[*?]/.test(last);
+
+  let source = '';
+  for (let i = 0; i < glob.length; i++) {
+    const c = glob[i];
+    if (c === '\\' && i + 1 < glob.length) {
+      source += escapeRegex(glob[++i]);
+    } else if (c === '*') {
+      const atSegmentStart = i === 0 || glob[i - 1] === '/';
+      if (glob[i + 1] === '*' && atSegmentStart && glob[i + 2] === '/') {
+        source += '(?:.*/)?';
+        i += 2;
+      } else if (glob[i + 1] === '*' && atSegmentStart && i + 2 === glob.length) {
+        source += '.*';
+        i += 1;
+      } else {
+        while (glob[i + 1] === '*') i++;
+        source += '[^/]*';
+      }
+    } else if (c === '?') {
+      source += '[^/]';
+    } else {
+      source += escapeRegex(c);
+    }
+  }
+
+  const prefix = anchored ? '' : '(?:.*/)?';
+  const suffix = directoryOnly ? '/.*' : descendants ? '(?:/.*)?' : '';
+  return new RegExp(`^${prefix}${source}${suffix}$`);
+};
+
+/** Split a line on unescaped whitespace, dropping an unescaped `#` comment */
+const splitLine = (text: string): string[] => {
+  const tokens: string[] = [];
+  let token = '';
+  for (let i = 0; i < text.length; i++) {
+    const c = text[i];
+    if (c === '\\' && i + 1 < text.length) {
+      token += c + text[++i];
+    } else if (c === '#' && token === '') {
+      break;
+    } else if (c === ' ' || c === '\t') {
+      if (token) tokens.push(token);
+      token = '';
+    } else {
+      token += c;
+    }
+  }
+  if (token) tokens.push(token);
+  return tokens;
+};
+
+/** Parse CODEOWNERS content into rules in file order */
+export const parseCodeOwners = (content: string): CodeOwnersFile => {
+  const rules: CodeOwnersRule[] = [];
+  const invalid: CodeOwnersInvalidLine[] = [];
+  content.split(/\r?\n/).forEach((text, line) => {
+    const [pattern, ...owners] = splitLine(text);
+    if (!pattern) return;
+    const fail = (reason: string) => invalid.push({ line, text, reason });
+    if (pattern.startsWith('!')) return fail('negation is not supported');
+    if (/(^|[^\\])\[/.test(pattern)) return fail('character ranges are not supported');
+    const bad = owners.find(owner => !OWNER.test(owner));
+    if (bad) return fail(`invalid owner ${bad}`);
+    rules.push({ pattern, owners, line, regex: codeOwnersPatternToRegExp(pattern) });
+  });
+  return { rules, invalid };
+};
+
+/** The deciding rule for a path (the last that matches), or null */
+export const matchCodeOwners = (file: CodeOwnersFile, filePath: string, isDirectory = false): CodeOwnersRule | null => {
+  const target = filePath.replace(/\\/g, '/').replace(/^\.?\/+/, '').replace(/\/+$/, '') + (isDirectory ? '/' : '');
+  for (let i = file.rules.length - 1; i >= 0; i--) {
+    if (file.rules[i].regex.test(target)) return file.rules[i];
+  }
+  return null;
+};
+
+/**
+ * Create a lookup of owners per symbol. CODEOWNERS is read once; `path` is
+ * where it was found, null when the repo has none (then nothing is owned).
+ */
+export const createCodeOwnersLookup = (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  options: CodeOwnersOptions = {},
+) => {
+  let content = options.content ?? null;
+  let path: string | null = null;
+  if (content === null) {
+    let found = new Map<string, string>();
+    try {
+      found = readFilesAtCommit(repoPath, options.ref || 'HEAD', CODEOWNERS_LOCATIONS);
+    } catch {
+      // Not a git repo or unknown ref: no owners
+    }
+    path = CODEOWNERS_LOCATIONS.find(location => found.has(location)) ?? null;
+    content = path ? found.get(path)! : '';
+  }
+  const file = parseCodeOwners(content);
+  const cache = new Map<string, SymbolOwners>();
+
+  const ownersOfPath = (filePath: string, isDirectory: boolean): SymbolOwners => {
+    const key = isDirectory ? `${filePath}/` : filePath;
+    let owners = cache.get(key);
+    if (!owners) {
+      const rule = matchCodeOwners(file, filePath, isDirectory);
+      owners = rule ? { owners: rule.owners, rule: { pattern: rule.pattern, line: rule.line } } : { owners: [], rule: null };
+      cache.set(key, owners);
+    }
+    return owners;
+  };
+
+  /**
+   * Owners of a symbol's file (of the folder itself for Folder nodes). No
+   * owners when the symbol is unknown or has no file.
+   */
+  const getOwners = (symbolId: string): SymbolOwners => {
+    const node = graph.getNode(symbolId);
+    const filePath = node?.properties.filePath;
+    if (!node || !filePath) return { owners: [], rule: null };
+    return ownersOfPath(filePath, node.label === 'Folder');
+  };
+
+  return {
+    getOwners,
+    /** Owners of a repo-relative path */
+    getPathOwners: (filePath: string) => ownersOfPath(filePath, false),
+    path,
+    invalid: file.invalid,
+  };
+};
@@ -0,0 +1,51 @@
+/**
+ * Complexity
+ *
+ * Functions and methods whose cyclomatic complexity (computed at parse time,
+ * see computeGoComplexity) is over a threshold — the same report as
+ * `gocyclo -over N`. Pair with computeChurn to find code that is both
+ * complicated and frequently changed.
+ */
+
+import { KnowledgeGraph, NodeLabel } from './types.js';
+
+export interface ComplexSymbol {
+  id: string;
+  name: string;
+  label: NodeLabel;
+  filePath: string;
+  startLine?: number;
+  /** `Recv` for methods */
+  receiverType?: string;
+  complexity: number;
+}
+
+/** Conventional gocyclo/golangci-lint limit */
+export const DEFAULT_COMPLEXITY_THRESHOLD = 15;
+
+/**
+ * Functions and methods with complexity strictly greater than `threshold`,
+ * most complex first (ties by file, then line).
+ */
+export const findComplexSymbols = (
+  graph: KnowledgeGraph,
+  threshold: number = DEFAULT_COMPLEXITY_THRESHOLD,
+): ComplexSymbol[] => {
+  const results: ComplexSymbol[] = [];
+  graph.forEachNode(node => {
+    if (node.label !== 'Function' && node.label !== 'Method') return;
+    const { complexity } = node.properties;
+    if (complexity === undefined || complexity <= threshold) return;
+    results.push({
+      id: node.id,
+      name: node.properties.name,
+      label: node.label,
+      filePath: node.properties.filePath,
+      ...(node.properties.startLine !== undefined ? { startLine: node.properties.startLine } : {}),
+      ...(node.properties.receiverType ? { receiverType: node.properties.receiverType } : {}),
+      complexity,
+    });
+  });
+  return results.sort((a, b) =>
+    b.complexity - a.complexity || a.filePath.localeCompare(b.filePath) || (a.startLine ?? 0) - (b.startLine ?? 0));
+};
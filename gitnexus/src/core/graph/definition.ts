@@ -0,0 +1,701 @@
+/**
+ * Go To Definition
+ *
+ * Resolves the identifier under a cursor to its declaration, as an editor's
+ * "go to definition" does. The file is parsed fresh, so the position refers
+ * to its current content; declarations in other files come from the graph.
+ * Positions are 0-based rows and byte columns, like startLine/startColumn.
+ *
+ * Names resolve the way Go scopes them, innermost first:
+ *   1. locals: parameters, receivers, named results, type parameters, and
+ *      anything declared earlier in an enclosing block or if/for/switch
+ *      header. A local shadows the package-level name it hides.
+ *   2. the file's imports (`fmt` in `fmt.Sprintf`)
+ *   3. package-level symbols of the file's package (its directory), then of
+ *      dot-imported packages
+ * In `x.Sel`, `x` is resolved first. For an imported package, Sel is looked
+ * up in that package. For a value, Sel is a method or field of x's type,
+ * promoted ones included, when the type can be told from the code: a
+ * declared type, `T{}`, `&T{}`, `new(T)`, a call's result type, a field.
+ *
+ * Predeclared names and anything from a package outside the repo (standard
+ * library, dependencies) fail with DefinitionNotFoundError rather than
+ * matching a same-named repo symbol.
+ */
+
+import { KnowledgeGraph, GraphNode, FileImport, StructField } from './types.js';
+import { getGoModules, createGoModuleResolver } from './modules.js';
+import { parseSignature } from './signatures.js';
+import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
+import { GO_PREDECLARED, extractGoImports, baseTypeName, initializerType } from '../ingestion/go-metadata.js';
+import { generateId, goPackageDir, isGoTestFile } from '../../lib/utils.js';
+
+/** Reads a repo-relative file; null when it can't be read */
+export type DefinitionFileReader = (relativePath: string) => Promise<string | null>;
+
+/**
+ * - symbol: a graph node (func, method, type, package-level const/var)
+ * - member: a struct field or interface method; nodeId is the owning type
+ * - local: a parameter, local variable, constant, type or label
+ * - import: the import spec binding a package name
+ */
+export type DefinitionKind = 'symbol' | 'member' | 'local' | 'import';
+
+export interface Definition {
+  kind: DefinitionKind;
+  name: string;
+  filePath: string;
+  /** 0-based row and byte column of the declared name (column 0 when only the row is known) */
+  line: number;
+  column: number;
+  nodeId?: string;
+}
+
+/**
+ * - no-identifier: the position isn't on an identifier
+ * - predeclared: a builtin (`len`, `error`, `nil`)
+ * - external: declared in a package outside the repo
+ * - unresolved: couldn't be told, e.g. a method on a value of unknown type
+ */
+export type DefinitionNotFoundReason = 'no-identifier' | 'predeclared' | 'external' | 'unresolved';
+
+export class DefinitionNotFoundError extends Error {
+  constructor(public readonly reason: DefinitionNotFoundReason, public readonly identifier?: string) {
+    super(identifier ? `No definition for ${identifier} (${reason})` : `No definition found (${reason})`);
+    this.name = 'DefinitionNotFoundError';
+  }
+}
+
+const IDENTIFIER_TYPES = new Set(['identifier', 'field_identifier', 'type_identifier', 'package_identifier', 'label_name']);
+const PACKAGE_LEVEL_LABELS = new Set(['Function', 'Struct', 'Interface', 'TypeAlias', 'Const', 'Static']);
+const TYPE_LABELS = new Set(['Struct', 'Interface', 'TypeAlias']);
+const FUNCTION_SCOPES = new Set(['function_declaration', 'method_declaration', 'func_literal']);
+const BLOCK_SCOPES = new Set(['block', 'statement_list', 'expression_case', 'default_case', 'type_case', 'communication_case']);
+const HEADER_SCOPES = new Set(['if_statement', 'expression_switch_statement', 'type_switch_statement']);
+const PREDECLARED_VALUES = new Set(['true', 'false', 'nil', 'iota']);
+const NAMED_TYPE = /^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$/;
+const MAX_INFERENCE_DEPTH = 8;
+
+/** Named type resolved to its package directory */
+interface TypeRef {
+  dir: string;
+  name: string;
+}
+
+type TypeResolution = TypeRef | 'external' | null;
+
+/** A name declared inside a function, and the construct declaring it */
+interface LocalDeclaration {
+  nameNode: any;
+  decl: any;
+  /** Position among the construct's names (`a, b := f()`) */
+  index: number;
+}
+
+interface MemberMatch {
+  definition: Definition;
+  field?: StructField;
+  owner: GraphNode;
+}
+
+// ============================================================================
+// LOCAL SCOPES
+// ============================================================================
+
+const fieldIs = (parent: any, field: string, node: any): boolean => {
+  const child = parent?.childForFieldName?.(field);
+  return !!child && child.startIndex === node.startIndex && child.endIndex === node.endIndex;
+};
+
+/** Direct identifier children: the names of a spec or parameter declaration */
+const declaredNames = (decl: any): any[] =>
+  (decl.namedChildren ?? []).filter((c: any) => c.type === 'identifier');
+
+/** Names a statement declares for the statements after it */
+const statementDeclarations = (stmt: any): LocalDeclaration[] => {
+  const found: LocalDeclaration[] = [];
+  const addList = (list: any, decl: any) => {
+    (list?.namedChildren ?? []).forEach((id: any, index: number) => {
+      if (id.type === 'identifier') found.push({ nameNode: id, decl, index });
+    });
+  };
+  const addSpecs = (node: any) => {
+    for (const child of node.namedChildren ?? []) {
+      if (child.type === 'var_spec' || child.type === 'const_spec') {
+        declaredNames(child).forEach((id: any, index: number) => found.push({ nameNode: id, decl: child, index }));
+      } else if (child.type === 'type_spec' || child.type === 'type_alias') {
+        const name = child.childForFieldName?.('name');
+        if (name) found.push({ nameNode: name, decl: child, index: 0 });
+      } else if (child.type.endsWith('_spec_list')) {
+        addSpecs(child);
+      }
+    }
+  };
+  switch (stmt.type) {
+    case 'short_var_declaration':
+      addList(stmt.childForFieldName?.('left'), stmt);
+      break;
+    case 'var_declaration':
+    case 'const_declaration':
+    case 'type_declaration':
+      addSpecs(stmt);
+      break;
+    case 'receive_statement':
+      if (stmt.text.includes(':=')) addList(stmt.childForFieldName?.('left'), stmt);
+      break;
+  }
+  return found;
+};
+
+/** Parameter-like names of a function or generic type: receiver, params, results, type params */
+const signatureDeclarations = (scope: any): LocalDeclaration[] => {
+  const found: LocalDeclaration[] = [];
+  for (const field of ['receiver', 'type_parameters', 'parameters', 'result']) {
+    const list = scope.childForFieldName?.(field);
+    for (const param of list?.namedChildren ?? []) {
+      if (!param.type.endsWith('parameter_declaration')) continue;
+      for (const id of param.namedChildren ?? []) {
+        if (id.type === 'identifier' && !fieldIs(param, 'type', id)) found.push({ nameNode: id, decl: param, index: 0 });
+      }
+    }
+  }
+  // `func (s *Set[T]) Add(v T)`: the receiver's type arguments declare T
+  if (scope.type === 'method_declaration') {
+    const visit = (node: any) => {
+      if (node.type === 'type_arguments') {
+        for (const arg of node.namedChildren ?? []) {
+          if (arg.type === 'type_identifier') found.push({ nameNode: arg, decl: node, index: 0 });
+        }
+        return;
+      }
+      for (const child of node.namedChildren ?? []) visit(child);
+    };
+    const receiver = scope.childForFieldName?.('receiver');
+    if (receiver) visit(receiver);
+  }
+  return found;
+};
+
+/** Names an if/for/switch header declares, visible at `ident` inside the statement's `child` */
+const headerDeclarations = (scope: any, child: any, ident: any): LocalDeclaration[] => {
+  const found: LocalDeclaration[] = [];
+  const before = (node: any) => node && node.endIndex <= ident.startIndex;
+  if (scope.type === 'for_statement') {
+    for (const clause of scope.namedChildren ?? []) {
+      if (clause.type === 'for_clause') {
+        const init = clause.childForFieldName?.('initializer');
+        if (before(init)) found.push(...statementDeclarations(init));
+      } else if (clause.type === 'range_clause' && clause !== child && clause.startIndex !== child.startIndex) {
+        const left = clause.childForFieldName?.('left');
+        if (left && clause.text.includes(':=')) {
+          (left.namedChildren ?? []).forEach((id: any, index: number) => {
+            if (id.type === 'identifier') found.push({ nameNode: id, decl: clause, index });
+          });
+        }
+      }
+    }
+    return found;
+  }
+  const init = scope.childForFieldName?.('initializer');
+  if (before(init)) found.push(...statementDeclarations(init));
+  if (scope.type === 'type_switch_statement' && (child.type === 'type_case' || child.type === 'default_case')) {
+    const alias = scope.childForFieldName?.('alias');
+    for (const id of alias?.namedChildren ?? (alias?.type === 'identifier' ? [alias] : [])) {
+      if (id.type === 'identifier') found.push({ nameNode: id, decl: scope, index: 0 });
+    }
+  }
+  return found;
+};
+
+/** The local declaration `ident` refers to, or null when it isn't a local */
+const findLocal = (ident: any, name: string = ident.text): LocalDeclaration | null => {
+  for (let child = ident, scope = ident.parent; scope && scope.type !== 'source_file'; child = scope, scope = scope.parent) {
+    let candidates: LocalDeclaration[] = [];
+    if (BLOCK_SCOPES.has(scope.type)) {
+      for (const stmt of scope.namedChildren ?? []) {
+        if (stmt.endIndex > ident.startIndex) break;
+        candidates.push(...statementDeclarations(stmt));
+      }
+    } else if (FUNCTION_SCOPES.has(scope.type) || scope.type === 'type_spec' || scope.type === 'type_alias') {
+      candidates = signatureDeclarations(scope);
+    } else if (scope.type === 'for_statement' || HEADER_SCOPES.has(scope.type)) {
+      candidates = headerDeclarations(scope, child, ident);
+    }
+    // `a, err := f(); b, err := g()` redeclares err; the first declaration is the one
+    const match = candidates.find(c => c.nameNode.text === name && c.nameNode.startIndex !== ident.startIndex);
+    if (match) return match;
+  }
+  return null;
+};
+
+// ============================================================================
+// RESOLVER
+// ============================================================================
+
+/**
+ * Create a resolver over a graph. Go symbols are indexed by package on first
+ * use; call clear() after the graph changes.
+ */
+export const createDefinitionResolver = (graph: KnowledgeGraph, readFile: DefinitionFileReader) => {
+  let symbolsByDir: Map<string, GraphNode[]> | null = null;
+  let goModules = createGoModuleResolver([]);
+
+  const symbolsIn = (dir: string): GraphNode[] => {
+    if (!symbolsByDir) {
+      symbolsByDir = new Map();
+      goModules = createGoModuleResolver(getGoModules(graph));
+      graph.forEachNode(node => {
+        const { filePath } = node.properties;
+        if (!filePath?.endsWith('.go') || (!PACKAGE_LEVEL_LABELS.has(node.label) && node.label !== 'Method')) return;
+        const key = goPackageDir(filePath);
+        let nodes = symbolsByDir!.get(key);
+        if (!nodes) {
+          nodes = [];
+          symbolsByDir!.set(key, nodes);
+        }
+        nodes.push(node);
+      });
+      const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+      for (const nodes of symbolsByDir.values()) {
+        nodes.sort((a, b) => cmp(a.properties.filePath, b.properties.filePath) || (a.properties.startLine ?? 0) - (b.properties.startLine ?? 0));
+      }
+    }
+    return symbolsByDir.get(dir) ?? [];
+  };
+
+  const symbolDefinition = (node: GraphNode): Definition => ({
+    kind: 'symbol',
+    name: node.properties.name,
+    filePath: node.properties.filePath,
+    line: node.properties.startLine ?? 0,
+    column: node.properties.startColumn ?? 0,
+    nodeId: node.id,
+  });
+
+  /** Package-level symbol (not a method) of the package in `dir` */
+  const packageSymbol = (dir: string, name: string, fromFile: string): GraphNode | null =>
+    symbolsIn(dir).find(n =>
+      n.properties.name === name && PACKAGE_LEVEL_LABELS.has(n.label)
+      && (!isGoTestFile(n.properties.filePath) || isGoTestFile(fromFile))) ?? null;
+
+  /**
+   * Repo directory of an imported package, or null when it is outside the
+   * repo. Exact with go.mod files, by path shape otherwise.
+   */
+  const packageDir = (importPath: string, fromFile: string): string | null => {
+    symbolsIn('');
+    if (goModules.modules.length > 0) return goModules.resolveImportDir(importPath, fromFile);
+    // Without go.mod, `strings` must not match a repo directory named strings:
+    // standard library paths have no dot in their first element
+    if (!importPath.split('/')[0].includes('.')) return null;
+    const dirs = [...symbolsByDir!.keys()].filter(d => d !== '' && (importPath === d || importPath.endsWith('/' + d)));
+    return dirs.sort((a, b) => b.length - a.length)[0] ?? null;
+  };
+
+  const importsOf = (filePath: string): FileImport[] =>
+    graph.getNode(generateId('File', filePath))?.properties.imports ?? [];
+
+  const resolveTypeText = (typeText: string, fromFile: string, imports: FileImport[]): TypeResolution => {
+    const text = baseTypeName(typeText);
+    if (!NAMED_TYPE.test(text)) return null;
+    const dot = text.indexOf('.');
+    if (dot < 0) return GO_PREDECLARED.has(text) ? 'external' : { dir: goPackageDir(fromFile), name: text };
+    const pkg = text.substring(0, dot);
+    const imp = imports.find(i => i.localName === pkg && i.kind !== 'dot' && i.kind !== 'blank');
+    if (!imp) return null;
+    const dir = packageDir(imp.path, fromFile);
+    return dir === null ? 'external' : { dir, name: text.substring(dot + 1) };
+  };
+
+  const typeNode = (ref: TypeRef): GraphNode | null =>
+    symbolsIn(ref.dir).find(n => TYPE_LABELS.has(n.label) && n.properties.name === ref.name) ?? null;
+
+  /**
+   * Method or field `member` of a type, following embedded fields and
+   * interfaces (promotion). Defined types (`type B A`) get A's fields but
+   * not its methods; aliases get both.
+   */
+  const findMember = (ref: TypeRef, member: string, fieldsOnly = false, seen = new Set<string>()): MemberMatch | 'external' | null => {
+    const key = `${ref.dir}\0${ref.name}`;
+    if (seen.has(key)) return null;
+    seen.add(key);
+
+    const owner = typeNode(ref);
+    if (!fieldsOnly) {
+      const method = symbolsIn(ref.dir).find(n =>
+        n.label === 'Method' && n.properties.name === member && n.properties.receiverType === ref.name);
+      if (method) return { definition: symbolDefinition(method), owner: owner ?? method };
+    }
+    if (!owner) return null;
+    const { fields, methodSignatures, embeddedTypes, filePath } = owner.properties;
+    const memberDefinition = (line: number | undefined): Definition => ({
+      kind: 'member',
+      name: member,
+      filePath,
+      line: line ?? owner.properties.startLine ?? 0,
+      column: 0,
+      nodeId: owner.id,
+    });
+
+    const field = fields?.find(f => f.name === member);
+    if (field) return { definition: memberDefinition(field.line), field, owner };
+    if (!fieldsOnly && methodSignatures?.some(sig => sig.startsWith(member + '(') || sig.startsWith(member + '['))) {
+      return { definition: memberDefinition(undefined), owner };
+    }
+
+    const parents: string[] = [
+      ...(fields ?? []).filter(f => f.embedded).map(f => f.typeString),
+      ...(embeddedTypes ?? []),
+    ];
+    let external = false;
+    for (const parent of parents) {
+      const resolved = resolveTypeText(parent, filePath, importsOf(filePath));
+      if (resolved === 'external') external = true;
+      if (!resolved || resolved === 'external') continue;
+      const found = findMember(resolved, member, fieldsOnly, seen);
+      if (found === 'external') external = true;
+      else if (found) return found;
+    }
+    if (owner.label === 'TypeAlias' && owner.properties.underlyingType) {
+      const resolved = resolveTypeText(owner.properties.underlyingType, filePath, importsOf(filePath));
+      if (resolved === 'external') return 'external';
+      if (resolved) return findMember(resolved, member, fieldsOnly || !owner.properties.isAlias, seen);
+    }
+    return external ? 'external' : null;
+  };
+
+  /** Resolve one parsed file; positions are 0-based */
+  const definitionInTree = (rootNode: any, filePath: string, line: number, column: number): Definition => {
+    const imports = extractGoImports(rootNode);
+    const dotImports = imports.filter(i => i.kind === 'dot');
+    const fileDir = goPackageDir(filePath);
+
+    const located = (kind: DefinitionKind, nameNode: any, nodeId?: string): Definition => ({
+      kind,
+      name: nameNode.text,
+      filePath,
+      line: nameNode.startPosition.row,
+      column: nameNode.startPosition.column,
+      ...(nodeId ? { nodeId } : {}),
+    });
+
+    /** The graph node declared at `nameNode` in this file */
+    const declaredHere = (nameNode: any, labels: Set<string>): Definition => {
+      const row = nameNode.startPosition.row;
+      const node = symbolsIn(fileDir).find(n =>
+        n.properties.filePath === filePath && n.properties.name === nameNode.text
+        && labels.has(n.label) && (n.properties.startLine === undefined || n.properties.startLine === row));
+      return located('symbol', nameNode, node?.id);
+    };
+
+    const importDefinition = (name: string): Definition | null => {
+      const imp = imports.find(i => i.localName === name && i.kind !== 'dot' && i.kind !== 'blank');
+      return imp ? { kind: 'import', name, filePath, line: imp.line, column: 0 } : null;
+    };
+
+    const localDefinition = (local: LocalDeclaration): Definition =>
+      located('local', local.nameNode);
+
+    const enclosingTypeSpec = (node: any): any => {
+      for (let current = node.parent; current; current = current.parent) {
+        if (current.type === 'type_spec' || current.type === 'type_alias') return current;
+      }
+      return null;
+    };
+
+    /** The identifier is itself a declaration: the definition is where it stands */
+    const declarationAt = (ident: any): Definition | null => {
+      const parent = ident.parent;
+      if (!parent) return null;
+      switch (parent.type) {
+        case 'function_declaration':
+          return fieldIs(parent, 'name', ident) ? declaredHere(ident, new Set(['Function'])) : null;
+        case 'method_declaration':
+          return fieldIs(parent, 'name', ident) ? declaredHere(ident, new Set(['Method'])) : null;
+        case 'type_spec':
+        case 'type_alias':
+          return fieldIs(parent, 'name', ident) ? declaredHere(ident, TYPE_LABELS) : null;
+        case 'var_spec':
+        case 'const_spec': {
+          if (ident.type !== 'identifier' || fieldIs(parent, 'type', ident)) return null;
+          let decl = parent.parent;
+          while (decl && decl.type.endsWith('_spec_list')) decl = decl.parent;
+          if (decl?.parent?.type === 'source_file') {
+            return declaredHere(ident, new Set([parent.type === 'var_spec' ? 'Static' : 'Const']));
+          }
+          return located('local', ident);
+        }
+        case 'parameter_declaration':
+        case 'variadic_parameter_declaration':
+        case 'type_parameter_declaration':
+          return ident.type === 'identifier' && !fieldIs(parent, 'type', ident) ? located('local', ident) : null;
+        case 'field_declaration':
+        case 'method_spec':
+        case 'method_elem': {
+          if (ident.type !== 'field_identifier') return null;
+          const spec = enclosingTypeSpec(parent);
+          const owner = spec?.childForFieldName?.('name');
+          const node = owner ? declaredHere(owner, TYPE_LABELS) : null;
+          return { ...located('member', ident), ...(node?.nodeId ? { nodeId: node.nodeId } : {}) };
+        }
+        case 'import_spec':
+          return fieldIs(parent, 'name', ident) ? { kind: 'import', name: ident.text, filePath, line: parent.startPosition.row, column: 0 } : null;
+        case 'labeled_statement':
+          return located('local', ident);
+        case 'expression_list': {
+          const stmt = parent.parent;
+          const declaring = (stmt?.type === 'short_var_declaration' || stmt?.type === 'range_clause' || stmt?.type === 'receive_statement')
+            && fieldIs(stmt, 'left', parent) && stmt.text.includes(':=');
+          if (!declaring) return null;
+          return localDefinition(findLocal(ident) ?? { nameNode: ident, decl: stmt, index: 0 });
+        }
+      }
+      return null;
+    };
+
+    const resolvePackageMember = (pkgName: string, name: string): Definition => {
+      const imp = imports.find(i => i.localName === pkgName && i.kind !== 'dot' && i.kind !== 'blank')!;
+      const dir = packageDir(imp.path, filePath);
+      if (dir === null) throw new DefinitionNotFoundError('external', `${pkgName}.${name}`);
+      const node = packageSymbol(dir, name, filePath);
+      if (!node) throw new DefinitionNotFoundError('unresolved', `${pkgName}.${name}`);
+      return symbolDefinition(node);
+    };
+
+    /** Is `ident` (a bare identifier) an import's package name here? */
+    const isPackageName = (ident: any): boolean =>
+      ident.type === 'identifier' && !findLocal(ident) && !!importDefinition(ident.text);
+
+    // ---- type inference for selector operands ----
+
+    const typeOfNode = (node: GraphNode, index: number): TypeResolution => {
+      const { filePath: nodeFile, signature, declaredType } = node.properties;
+      if (TYPE_LABELS.has(node.label)) return { dir: goPackageDir(nodeFile), name: node.properties.name };
+      if (node.label === 'Static' || node.label === 'Const') {
+        return declaredType ? resolveTypeText(declaredType, nodeFile, importsOf(nodeFile)) : null;
+      }
+      const result = signature ? parseSignature(signature)?.results[index] : undefined;
+      return result ? resolveTypeText(result, nodeFile, importsOf(nodeFile)) : null;
+    };
+
+    const typeOfLocal = (local: LocalDeclaration, depth: number): TypeResolution => {
+      const { decl, index } = local;
+      if (decl.type === 'parameter_declaration' || decl.type === 'var_spec' || decl.type === 'const_spec') {
+        const typeNode = decl.childForFieldName?.('type');
+        if (typeNode) return resolveTypeText(typeNode.text, filePath, imports);
+        const values = decl.childForFieldName?.('value')?.namedChildren ?? [];
+        return values[index] ? typeOfExpression(values[index], 0, depth + 1) : null;
+      }
+      if (decl.type === 'short_var_declaration') {
+        const right = decl.childForFieldName?.('right')?.namedChildren ?? [];
+        // `a, err := f()`: one call, several results
+        if (right.length === 1 && index > 0) return typeOfExpression(right[0], index, depth + 1);
+        return right[index] ? typeOfExpression(right[index], 0, depth + 1) : null;
+      }
+      return null;
+    };
+
+    /** Declaration a (non-selector or selector) callee resolves to, or null */
+    const tryResolve = (expr: any): Definition | null => {
+      try {
+        if (expr.type === 'identifier') return resolveName(expr);
+        if (expr.type === 'selector_expression') {
+          const field = expr.childForFieldName?.('field');
+          return field ? resolveSelector(expr.childForFieldName?.('operand'), field.text) : null;
+        }
+      } catch {
+        // fall through
+      }
+      return null;
+    };
+
+    /** Type of a value expression; `index` picks among a call's results */
+    const typeOfExpression = (expr: any, index: number, depth: number): TypeResolution => {
+      if (!expr || depth > MAX_INFERENCE_DEPTH) return null;
+      switch (expr.type) {
+        case 'parenthesized_expression':
+          return typeOfExpression(expr.namedChildren?.[0], index, depth + 1);
+        case 'composite_literal':
+        case 'unary_expression': {
+          const t = initializerType(expr);
+          return t ? resolveTypeText(t, filePath, imports) : null;
+        }
+        case 'type_assertion_expression': {
+          const t = expr.childForFieldName?.('type');
+          return t ? resolveTypeText(t.text, filePath, imports) : null;
+        }
+        case 'identifier': {
+          const local = findLocal(expr);
+          if (local) return typeOfLocal(local, depth);
+          const node = packageSymbol(fileDir, expr.text, filePath);
+          return node && node.label !== 'Function' ? typeOfNode(node, 0) : null;
+        }
+        case 'call_expression': {
+          const alloc = initializerType(expr);
+          if (alloc) return resolveTypeText(alloc, filePath, imports);
+          let fn = expr.childForFieldName?.('function');
+          if (fn?.type === 'index_expression') fn = fn.childForFieldName?.('operand');
+          const callee = fn ? tryResolve(fn) : null;
+          const node = callee?.nodeId ? graph.getNode(callee.nodeId) : undefined;
+          if (!node) return null;
+          // `T(x)` converts; `s.Method()` on a member returns its result
+          return callee!.kind === 'member' ? null : typeOfNode(node, index);
+        }
+        case 'selector_expression': {
+          const operand = expr.childForFieldName?.('operand');
+          const field = expr.childForFieldName?.('field')?.text;
+          if (!operand || !field) return null;
+          if (isPackageName(operand)) {
+            const def = tryResolve(expr);
+            const node = def?.nodeId ? graph.getNode(def.nodeId) : undefined;
+            return node && node.label !== 'Function' ? typeOfNode(node, 0) : null;
+          }
+          const owner = typeOfExpression(operand, 0, depth + 1);
+          if (!owner || owner === 'external') return owner;
+          const member = findMember(owner, field, true);
+          if (!member || member === 'external') return member;
+          const ownerFile = member.owner.properties.filePath;
+          return member.field ? resolveTypeText(member.field.typeString, ownerFile, importsOf(ownerFile)) : null;
+        }
+      }
+      return null;
+    };
+
+    // ---- names ----
+
+    const resolveSelector = (operand: any, name: string): Definition => {
+      if (!operand) throw new DefinitionNotFoundError('unresolved', name);
+      if (isPackageName(operand)) return resolvePackageMember(operand.text, name);
+
+      let owner = typeOfExpression(operand, 0, 0);
+      // Method expression on a type: `T.Method`, `(*T).Method`
+      if (!owner && operand.type === 'identifier' && !findLocal(operand)) {
+        const node = packageSymbol(fileDir, operand.text, filePath);
+        if (node && TYPE_LABELS.has(node.label)) owner = { dir: fileDir, name: node.properties.name };
+      }
+      if (owner === 'external') throw new DefinitionNotFoundError('external', name);
+      if (!owner) throw new DefinitionNotFoundError('unresolved', name);
+      const member = findMember(owner, name);
+      if (member === 'external') throw new DefinitionNotFoundError('external', name);
+      if (!member) throw new DefinitionNotFoundError('unresolved', name);
+      return member.definition;
+    };
+
+    const resolveName = (ident: any): Definition => {
+      const name = ident.text;
+      const local = findLocal(ident);
+      if (local) return localDefinition(local);
+      const imported = ident.type === 'identifier' ? importDefinition(name) : null;
+      if (imported) return imported;
+      const node = packageSymbol(fileDir, name, filePath);
+      if (node) return symbolDefinition(node);
+      for (const imp of dotImports) {
+        const dir = packageDir(imp.path, filePath);
+        const dotNode = dir !== null ? packageSymbol(dir, name, filePath) : null;
+        if (dotNode) return symbolDefinition(dotNode);
+      }
+      if (GO_PREDECLARED.has(name) || PREDECLARED_VALUES.has(name)) throw new DefinitionNotFoundError('predeclared', name);
+      throw new DefinitionNotFoundError('unresolved', name);
+    };
+
+    /** `T{Field: v}`: the key is a field of T */
+    const compositeKeyField = (ident: any): Definition | null => {
+      // Grammar versions differ: the key is the ident itself or a literal_element around it
+      const key = ident.parent?.type === 'literal_element' ? ident.parent : ident;
+      const element = key.parent;
+      if (element?.type !== 'keyed_element' || element.namedChildren?.[0]?.startIndex !== key.startIndex) return null;
+      const literal = element.parent?.type === 'literal_value' ? element.parent.parent : null;
+      if (literal?.type !== 'composite_literal') return null;
+      const owner = typeOfExpression(literal, 0, 0);
+      if (!owner || owner === 'external') return null;
+      const member = findMember(owner, ident.text, true);
+      return member && member !== 'external' ? member.definition : null;
+    };
+
+    const labelDefinition = (ident: any): Definition => {
+      let fn = ident.parent;
+      while (fn && !FUNCTION_SCOPES.has(fn.type)) fn = fn.parent;
+      let found: any = null;
+      const visit = (node: any) => {
+        if (found) return;
+        if (node !== fn && node.type === 'func_literal') return;
+        if (node.type === 'labeled_statement') {
+          const label = node.childForFieldName?.('label') ?? node.namedChildren?.[0];
+          if (label?.text === ident.text) {
+            found = label;
+            return;
+          }
+        }
+        for (const child of node.namedChildren ?? []) visit(child);
+      };
+      if (fn) visit(fn);
+      if (!found) throw new DefinitionNotFoundError('unresolved', ident.text);
+      return located('local', found);
+    };
+
+    const ident = identifierAt(rootNode, line, column);
+    if (!ident || ident.parent?.type === 'package_clause') throw new DefinitionNotFoundError('no-identifier');
+
+    const declared = declarationAt(ident);
+    if (declared) return declared;
+
+    const parent = ident.parent;
+    if (ident.type === 'label_name') return labelDefinition(ident);
+    if (ident.type === 'package_identifier') {
+      const imported = importDefinition(ident.text);
+      if (!imported) throw new DefinitionNotFoundError('unresolved', ident.text);
+      return imported;
+    }
+    if (parent?.type === 'qualified_type' && fieldIs(parent, 'name', ident)) {
+      const pkg = parent.childForFieldName?.('package')?.text;
+      if (pkg && importDefinition(pkg)) return resolvePackageMember(pkg, ident.text);
+      throw new DefinitionNotFoundError('unresolved', ident.text);
+    }
+    if (parent?.type === 'selector_expression' && fieldIs(parent, 'field', ident)) {
+      return resolveSelector(parent.childForFieldName?.('operand'), ident.text);
+    }
+    const keyField = compositeKeyField(ident);
+    if (keyField) return keyField;
+    if (ident.type === 'field_identifier') throw new DefinitionNotFoundError('unresolved', ident.text);
+    return resolveName(ident);
+  };
+
+  /**
+   * Definition of the identifier at a position in a Go file. Throws
+   * DefinitionNotFoundError when there is none to go to, and a plain Error
+   * when the file can't be read or isn't Go.
+   */
+  const findDefinition = async (filePath: string, line: number, column: number): Promise<Definition> => {
+    if (!filePath.endsWith('.go')) throw new Error(`Go to definition supports Go files only: ${filePath}`);
+    const content = await readFile(filePath);
+    if (content === null) throw new Error(`Cannot read ${filePath}`);
+    const parser = await loadParser();
+    await loadLanguage(SupportedLanguages.Go, filePath);
+    const tree = parser.parse(content, undefined, { bufferSize: 1024 * 256 });
+    return definitionInTree(tree.rootNode, filePath, line, column);
+  };
+
+  return {
+    findDefinition,
+    definitionInTree,
+    /** Drop the symbol index, e.g. after the graph was updated */
+    clear: () => {
+      symbolsByDir = null;
+    },
+  };
+};
+
+/** Innermost identifier at a position; one ending right at it counts (cursor after the name) */
+const identifierAt = (rootNode: any, line: number, column: number): any => {
+  const cmpPoint = (p: { row: number; column: number }) => (p.row - line) || (p.column - column);
+  let node = rootNode;
+  for (;;) {
+    const children = node.namedChildren ?? [];
+    const inside = children.find((c: any) => cmpPoint(c.startPosition) <= 0 && cmpPoint(c.endPosition) > 0)
+      ?? children.find((c: any) => IDENTIFIER_TYPES.has(c.type) && cmpPoint(c.endPosition) === 0);
+    if (!inside) break;
+    node = inside;
+  }
+  return IDENTIFIER_TYPES.has(node.type) ? node : null;
+};
@@ -0,0 +1,79 @@
+/**
+ * Duplicate Bodies
+ *
+ * Functions and methods with identical implementations, for finding
+ * copy-pasted code. Symbols are grouped by bodyHash, which covers the whole
+ * definition except comments, formatting and the symbol's own name (see
+ * getBodyHash): a copy that was renamed, re-indented or re-commented still
+ * groups with its original, one with any real edit doesn't. Methods on
+ * different receivers group too, when the receivers are written the same.
+ *
+ * Short definitions are left out by default: one-line getters and empty
+ * stubs are identical everywhere and would drown the real copies.
+ */
+
+import { KnowledgeGraph, NodeLabel } from './types.js';
+
+export interface DuplicateSymbol {
+  id: string;
+  name: string;
+  label: NodeLabel;
+  filePath: string;
+  startLine: number;
+  /** Rows the definition spans */
+  lines: number;
+}
+
+export interface DuplicateBodyGroup {
+  bodyHash: string;
+  /** Two or more, by file path then line */
+  symbols: DuplicateSymbol[];
+}
+
+export interface DuplicateBodyOptions {
+  /** Skip definitions spanning fewer rows (default 3) */
+  minLines?: number;
+  /** Labels to compare (default Function, Method, Constructor) */
+  labels?: NodeLabel[];
+}
+
+const DEFAULT_LABELS: NodeLabel[] = ['Function', 'Method', 'Constructor'];
+
+const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+
+/**
+ * Groups of symbols sharing a body hash, largest first, then by the first
+ * symbol's file and line, so the output is the same for the same graph.
+ */
+export const findDuplicateBodies = (
+  graph: KnowledgeGraph,
+  options: DuplicateBodyOptions = {},
+): DuplicateBodyGroup[] => {
+  const minLines = options.minLines ?? 3;
+  const labels = new Set<string>(options.labels ?? DEFAULT_LABELS);
+
+  const byHash = new Map<string, DuplicateSymbol[]>();
+  graph.forEachNode(node => {
+    const { bodyHash, startLine } = node.properties;
+    if (!labels.has(node.label) || !bodyHash || startLine === undefined) return;
+    const lines = (node.properties.definitionEndLine ?? node.properties.endLine ?? startLine) - startLine + 1;
+    if (lines < minLines) return;
+    const symbol = { id: node.id, name: node.properties.name, label: node.label, filePath: node.properties.filePath, startLine, lines };
+    const group = byHash.get(bodyHash);
+    if (group) group.push(symbol);
+    else byHash.set(bodyHash, [symbol]);
+  });
+
+  const groups: DuplicateBodyGroup[] = [];
+  for (const [bodyHash, symbols] of byHash) {
+    if (symbols.length < 2) continue;
+    symbols.sort((a, b) => cmp(a.filePath, b.filePath) || a.startLine - b.startLine || cmp(a.id, b.id));
+    groups.push({ bodyHash, symbols });
+  }
+  groups.sort((a, b) =>
+    b.symbols.length - a.symbols.length ||
+    cmp(a.symbols[0].filePath, b.symbols[0].filePath) ||
+    a.symbols[0].startLine - b.symbols[0].startLine ||
+    cmp(a.bodyHash, b.bodyHash));
+  return groups;
+};
@@ -0,0 +1,184 @@
+/**
+ * Graph Diff
+ *
+ * Symbol-level comparison of two indexed graphs (usually two commits) for
+ * review automation: what was added, removed, or modified, and for each
+ * modification whether the public shape changed or only the body.
+ *
+ * Identity: node ids contain the file path, so a function moved between
+ * files would look removed + added. Symbols are matched on a key instead:
+ *   Go:     package directory + receiver type + name (`pkg/auth:Store.Get`)
+ *   others: file path + label + name
+ * Line numbers never take part, so code pushed around by unrelated edits is
+ * not reported. Keys that still collide within one graph (several `init`
+ * functions in a package) are disambiguated by file, then by order.
+ *
+ * "Signature" is the part callers depend on: parameter/result types and type
+ * parameters for funcs and methods (plus pointer vs value receiver), fields
+ * for structs, method lists for interfaces, the aliased/underlying type for
+ * named types, type and value for constants. Everything else that changes
+ * the definition shows up as a body change (see bodyHash); comment-only and
+ * formatting-only edits don't.
+ */
+
+import { KnowledgeGraph, GraphNode, NodeLabel } from './types.js';
+import { getSymbolKind, GraphJSONKind } from './json-export.js';
+import { goPackageDir } from '../../lib/utils.js';
+
+export interface DiffSymbol {
+  /** Cross-commit identity (see header) */
+  key: string;
+  id: string;
+  name: string;
+  label: NodeLabel;
+  kind: GraphJSONKind;
+  filePath: string;
+  receiverType?: string;
+  isExported: boolean;
+  signature: string;
+}
+
+export interface ModifiedSymbol {
+  key: string;
+  base: DiffSymbol;
+  head: DiffSymbol;
+  /** Public shape changed (see header) */
+  signatureChanged: boolean;
+  /** Definition text changed; true alongside signatureChanged in most cases */
+  bodyChanged: boolean;
+  /** Exported in base or head */
+  isExported: boolean;
+}
+
+export interface GraphDiff {
+  added: DiffSymbol[];
+  removed: DiffSymbol[];
+  modified: ModifiedSymbol[];
+}
+
+/** Labels that aren't symbols, or whose ids are run-specific */
+const NON_SYMBOL_LABELS = new Set<string>(['Project', 'Package', 'Folder', 'File', 'Community', 'Process', 'Import']);
+
+const baseKey = (node: GraphNode): string => {
+  const { name, filePath, language, receiverType } = node.properties;
+  if (language === 'go') {
+    return `${goPackageDir(filePath) || '.'}:${receiverType ? `${receiverType}.` : ''}${name}`;
+  }
+  return `${filePath}:${node.label}:${name}`;
+};
+
+/** Public shape of a symbol as a comparable string */
+export const getSymbolSignature = (node: GraphNode): string => {
+  const p = node.properties;
+  const typeParams = p.typeParams?.length
+    ? `[${p.typeParams.map(t => `${t.name} ${t.constraint}`).join(', ')}]`
+    : '';
+  switch (node.label) {
+    case 'Function':
+      return `${typeParams}${p.signature ?? p.description ?? ''}`;
+    case 'Method':
+      return `${p.receiverPointer ? '*' : ''}${p.receiverType ?? ''}.${typeParams}${p.signature ?? p.description ?? ''}`;
+    case 'Struct':
+      return `${typeParams}struct{${(p.fields ?? [])
+        .map(f => `${f.embedded ? '' : f.name + ' '}${f.typeString}${f.tag ? ` \`${f.tag}\`` : ''}`)
+        .join('; ')}}`;
+    case 'Interface':
+      return `${typeParams}interface{${[...(p.embeddedTypes ?? []), ...(p.methodSignatures ?? [])].join('; ')}}`;
+    case 'TypeAlias':
+      return p.underlyingType !== undefined
+        ? `${typeParams}${p.isAlias ? '= ' : ''}${p.underlyingType}`
+        : p.description ?? '';
+    case 'Const':
+      return `${p.declaredType ?? ''} = ${p.constValue ?? p.value ?? ''}`;
+    case 'Static':
+      return p.declaredType ?? p.description ?? '';
+    default:
+      return p.description ?? '';
+  }
+};
+
+const cmp = (a: string, b: string): number => (a < b ? -1 : a > b ? 1 : 0);
+
+/** Symbols keyed by cross-commit identity */
+export const indexSymbolsByKey = (graph: KnowledgeGraph): Map<string, GraphNode> => {
+  const groups = new Map<string, GraphNode[]>();
+  graph.forEachNode(node => {
+    if (NON_SYMBOL_LABELS.has(node.label) || !node.properties.filePath) return;
+    const key = baseKey(node);
+    const group = groups.get(key) ?? [];
+    group.push(node);
+    groups.set(key, group);
+  });
+
+  const byKey = new Map<string, GraphNode>();
+  for (const [key, nodes] of groups) {
+    if (nodes.length === 1) {
+      byKey.set(key, nodes[0]);
+      continue;
+    }
+    nodes.sort((a, b) =>
+      cmp(a.properties.filePath, b.properties.filePath) ||
+      (a.properties.startLine ?? 0) - (b.properties.startLine ?? 0) ||
+      cmp(a.id, b.id));
+    const perFile = new Map<string, number>();
+    for (const node of nodes) {
+      const n = perFile.get(node.properties.filePath) ?? 0;
+      perFile.set(node.properties.filePath, n + 1);
+      byKey.set(`${key}@${node.properties.filePath}${n > 0 ? `#${n}` : ''}`, node);
+    }
+  }
+  return byKey;
+};
+
+const toDiffSymbol = (key: string, node: GraphNode): DiffSymbol => ({
+  key,
+  id: node.id,
+  name: node.properties.name,
+  label: node.label,
+  kind: getSymbolKind(node.label),
+  filePath: node.properties.filePath,
+  ...(node.properties.receiverType ? { receiverType: node.properties.receiverType } : {}),
+  isExported: node.properties.isExported ?? false,
+  signature: getSymbolSignature(node),
+});
+
+/**
+ * Added, removed, and modified symbols between two graphs, each list sorted
+ * by key.
+ */
+export const diffGraphs = (base: KnowledgeGraph, head: KnowledgeGraph): GraphDiff =>
+  diffSymbols(indexSymbolsByKey(base), indexSymbolsByKey(head));
+
+/** diffGraphs over symbol maps from indexSymbolsByKey, e.g. narrowed to some files */
+export const diffSymbols = (baseSymbols: Map<string, GraphNode>, headSymbols: Map<string, GraphNode>): GraphDiff => {
+  const diff: GraphDiff = { added: [], removed: [], modified: [] };
+
+  for (const [key, node] of baseSymbols) {
+    if (!headSymbols.has(key)) diff.removed.push(toDiffSymbol(key, node));
+  }
+  for (const [key, headNode] of headSymbols) {
+    const baseNode = baseSymbols.get(key);
+    if (!baseNode) {
+      diff.added.push(toDiffSymbol(key, headNode));
+      continue;
+    }
+    const before = toDiffSymbol(key, baseNode);
+    const after = toDiffSymbol(key, headNode);
+    const signatureChanged = before.signature !== after.signature || before.label !== after.label;
+    const bodyChanged = baseNode.properties.bodyHash !== headNode.properties.bodyHash;
+    if (!signatureChanged && !bodyChanged) continue;
+    diff.modified.push({
+      key,
+      base: before,
+      head: after,
+      signatureChanged,
+      bodyChanged,
+      isExported: before.isExported || after.isExported,
+    });
+  }
+
+  diff.added.sort((a, b) => cmp(a.key, b.key));
+  diff.removed.sort((a, b) => cmp(a.key, b.key));
+  diff.modified.sort((a, b) => cmp(a.key, b.key));
+  return diff;
+};
@@ -16,6 +16,9 @@ export const createKnowledgeGraph = (): KnowledgeGraph => {
     }
   };
 
+  const removeRelationship = (relationshipId: string): boolean =>
+    relationshipMap.delete(relationshipId);
+
   /**
    * Remove a single node and all relationships involving it
    */
@@ -33,6 +36,24 @@ export const createKnowledgeGraph = (): KnowledgeGraph => {
     return true;
   };
 
+  /**
+   * Remove many nodes in one relationship sweep — removeNode scans every
+   * relationship per call, which is quadratic for file-sized batches
+   */
+  const removeNodes = (nodeIds: Iterable<string>): number => {
+    const ids = new Set<string>();
+    for (const id of nodeIds) {
+      if (nodeMap.delete(id)) ids.add(id);
+    }
+    if (ids.size === 0) return 0;
+    for (const [relId, rel] of relationshipMap) {
+      if (ids.has(rel.sourceId) || ids.has(rel.targetId)) {
+        relationshipMap.delete(relId);
+      }
+    }
+    return ids.size;
+  };
+
   /**
    * Remove all nodes (and their relationships) belonging to a file
    */
@@ -61,6 +82,7 @@ export const createKnowledgeGraph = (): KnowledgeGraph => {
     forEachNode(fn: (node: GraphNode) => void) { nodeMap.forEach(fn); },
     forEachRelationship(fn: (rel: GraphRelationship) => void) { relationshipMap.forEach(fn); },
     getNode: (id: string) => nodeMap.get(id),
+    getRelationship: (id: string) => relationshipMap.get(id),
 
     // O(1) count getters - avoid creating arrays just for length
     get nodeCount() {
@@ -73,7 +95,9 @@ export const createKnowledgeGraph = (): KnowledgeGraph => {
 
     addNode,
     addRelationship,
+    removeRelationship,
     removeNode,
+    removeNodes,
     removeNodesByFile,
 
   };
@@ -0,0 +1,160 @@
+/**
+ * Ignored Errors
+ *
+ * Error-handling audit: Go calls that return an error which the caller
+ * throws away, either by using the call as a statement (`f.Close()`,
+ * `defer f.Close()`) or by assigning the error to `_` (`v, _ := parse(s)`).
+ * Discarded calls are recorded per function at parse time (see
+ * extractGoDiscardedCalls); this decides which of them drop an error.
+ *
+ * Calls resolved into the repo (CALLS edges) are judged by the callee's
+ * recorded signature, so they are exact. Calls leaving the repo have no
+ * signature to go by, and are judged by convention (basis `heuristic`):
+ *   - `v, _ := lib.F()` with the last value dropped: the last result of a
+ *     multi-value call is, by Go convention, the error. Well-known comma-ok
+ *     calls (`os.LookupEnv`, `sync.Map.Load`) are left out.
+ *   - well-known standard library functions and methods that return an
+ *     error (`os.Remove`, `json.Unmarshal`, `Close`, `Flush`, `Write`).
+ *     As in errcheck, writes to bytes.Buffer, strings.Builder and hashes
+ *     never fail and are left out, and fmt.Print* is never reported.
+ */
+
+import { KnowledgeGraph, GraphNode, GraphRelationship, DiscardedCall, DiscardKind } from './types.js';
+import { parseSignature } from './signatures.js';
+import { isGoTestFile } from '../../lib/utils.js';
+
+export interface IgnoredError {
+  /** Func/method containing the call */
+  sourceId: string;
+  sourceName: string;
+  filePath: string;
+  /** 0-based row and byte column of the call */
+  line: number;
+  column: number;
+  /** Callee as written (`f.Close`, `os.Remove`, `parse`) */
+  call: string;
+  /** Callee node, when the call resolved into the repo */
+  calleeId?: string;
+  discard: DiscardKind;
+  /** `signature`: the callee's signature returns an error; `heuristic`: judged by convention */
+  basis: 'signature' | 'heuristic';
+}
+
+export interface IgnoredErrorOptions {
+  /** Report `defer f()` and `go f()` (default true) */
+  includeDeferred?: boolean;
+  /** Report calls leaving the repo, judged by convention (default true) */
+  includeHeuristic?: boolean;
+  /** Leave out _test.go files */
+  excludeTests?: boolean;
+}
+
+/** Standard library functions whose (last) result is an error, by import path */
+const KNOWN_ERROR_FUNCS = new Set([
+  'os.Chdir', 'os.Chmod', 'os.Chown', 'os.Chtimes', 'os.Lchown', 'os.Link', 'os.Mkdir', 'os.MkdirAll',
+  'os.Remove', 'os.RemoveAll', 'os.Rename', 'os.Setenv', 'os.Symlink', 'os.Truncate', 'os.Unsetenv',
+  'os.WriteFile', 'io.Copy', 'io.CopyN', 'io.ReadFull', 'io.WriteString',
+  'encoding/json.Unmarshal', 'encoding/xml.Unmarshal', 'encoding/binary.Read', 'encoding/binary.Write',
+  'net/http.ListenAndServe', 'net/http.ListenAndServeTLS', 'net/http.Serve',
+]);
+
+/** Method names that return an error on the standard library types that have them */
+const KNOWN_ERROR_METHODS = new Set([
+  'Close', 'Flush', 'Sync', 'Shutdown', 'Encode', 'Decode', 'Write', 'WriteString', 'WriteTo', 'ReadFrom',
+  'Commit', 'Rollback', 'Ping', 'SetDeadline', 'SetReadDeadline', 'SetWriteDeadline',
+]);
+
+/** Receivers whose writes are documented never to fail */
+const INFALLIBLE_WRITERS = /^(bytes\.Buffer|strings\.Builder|hash\.Hash(32|64)?)$/;
+
+/** Multi-value calls whose last result is an ok bool, not an error */
+const COMMA_OK_CALLS = new Set(['os.LookupEnv', 'syscall.Getenv', 'Load', 'LoadOrStore', 'LoadAndDelete', 'Lookup']);
+
+const isErrorType = (type: string): boolean => type.replace(/\s+/g, '') === 'error';
+
+const callText = (call: DiscardedCall): string => (call.qualifier ? `${call.qualifier}.${call.callee}` : call.callee);
+
+/** Does this discarded call drop an error, going by the callee's signature? */
+const dropsErrorBySignature = (call: DiscardedCall, signature: string): boolean => {
+  const results = parseSignature(signature)?.results ?? [];
+  const errorAt = results.flatMap((t, i) => (isErrorType(t) ? [i] : []));
+  if (errorAt.length === 0) return false;
+  if (call.discard !== 'blank') return true;
+  return (call.blankResults ?? []).some(i => errorAt.includes(i));
+};
+
+/** Does a call leaving the repo drop an error, by convention? */
+const dropsErrorByConvention = (call: DiscardedCall, repoTypes: Set<string>): boolean => {
+  const pkgKey = call.qualifierPackage ? `${call.qualifierPackage}.${call.callee}` : null;
+  if (call.qualifierPackage === 'fmt') return false;
+
+  if (call.discard === 'blank' && (call.resultCount ?? 0) > 1) {
+    if (COMMA_OK_CALLS.has(pkgKey ?? '') || (!call.qualifierPackage && COMMA_OK_CALLS.has(call.callee))) return false;
+    return call.blankResults!.includes(call.resultCount! - 1);
+  }
+  if (pkgKey) return KNOWN_ERROR_FUNCS.has(pkgKey);
+  if (!call.qualifier || !KNOWN_ERROR_METHODS.has(call.callee)) return false;
+  if (call.qualifierType) {
+    if (INFALLIBLE_WRITERS.test(call.qualifierType)) return false;
+    // A repo type's method that didn't resolve is promoted or unknown, not the library's
+    if (repoTypes.has(call.qualifierType.substring(call.qualifierType.lastIndexOf('.') + 1))) return false;
+  }
+  return true;
+};
+
+/** Discarded error results across the repo, sorted by file, line, column */
+export const findIgnoredErrors = (graph: KnowledgeGraph, options: IgnoredErrorOptions = {}): IgnoredError[] => {
+  const includeDeferred = options.includeDeferred ?? true;
+  const includeHeuristic = options.includeHeuristic ?? true;
+
+  const callers: GraphNode[] = [];
+  const repoTypes = new Set<string>();
+  graph.forEachNode(node => {
+    if (node.label === 'Struct' || node.label === 'Interface' || node.label === 'TypeAlias') repoTypes.add(node.properties.name);
+    if ((node.label !== 'Function' && node.label !== 'Method') || !node.properties.discardedCalls) return;
+    if (options.excludeTests && isGoTestFile(node.properties.filePath)) return;
+    callers.push(node);
+  });
+  const outgoing = new Map<string, GraphRelationship[]>();
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'CALLS' || !rel.callLines) return;
+    const list = outgoing.get(rel.sourceId);
+    if (list) list.push(rel);
+    else outgoing.set(rel.sourceId, [rel]);
+  });
+
+  const results: IgnoredError[] = [];
+  for (const node of callers) {
+    const edges = outgoing.get(node.id) ?? [];
+    for (const call of node.properties.discardedCalls!) {
+      if (!includeDeferred && (call.discard === 'defer' || call.discard === 'go')) continue;
+      const edge = edges.find(e => e.callLines!.includes(call.line) && graph.getNode(e.targetId)?.properties.name === call.callee);
+      const callee = edge ? graph.getNode(edge.targetId) : undefined;
+
+      let basis: IgnoredError['basis'] | null = null;
+      if (callee) {
+        if (callee.properties.signature && dropsErrorBySignature(call, callee.properties.signature)) basis = 'signature';
+      } else if (includeHeuristic && dropsErrorByConvention(call, repoTypes)) {
+        basis = 'heuristic';
+      }
+      if (!basis) continue;
+
+      results.push({
+        sourceId: node.id,
+        sourceName: node.label === 'Method' && node.properties.receiverType
+          ? `${node.properties.receiverType}.${node.properties.name}`
+          : node.properties.name,
+        filePath: node.properties.filePath,
+        line: call.line,
+        column: call.column,
+        call: callText(call),
+        ...(callee ? { calleeId: callee.id } : {}),
+        discard: call.discard,
+        basis,
+      });
+    }
+  }
+
+  const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+  return results.sort((a, b) => cmp(a.filePath, b.filePath) || a.line - b.line || a.column - b.column);
+};
@@ -0,0 +1,119 @@
+/**
+ * Introduced In
+ *
+ * Archaeology view of the graph: the commit where a symbol first appeared,
+ * found with a `git log -S`-style pickaxe search for its declaration in its
+ * own file. Blame (see symbol-authors) answers "who touched this last";
+ * this answers "when, by whom and why was it added".
+ *
+ * It is a heuristic. File renames are followed, but a symbol that was
+ * renamed or moved in from another file reports the commit of the rename or
+ * move. For Go, the search looks for the declaration itself (`func Name(`,
+ * `func (r *Recv) Name(`, `type Name`, `const Name`, `var Name`); when that
+ * never matched (declarations inside a `type ( ... )` or `const ( ... )`
+ * group) or for other languages it falls back to the bare name, whose first
+ * mention in the file is usually the declaration.
+ */
+
+import { KnowledgeGraph, GraphNode } from './types.js';
+import { findIntroducingCommit, CommitInfo } from '../../storage/git.js';
+
+export interface IntroducedIn extends CommitInfo {
+  /**
+   * What the commit was found by: the declaration, the bare name (less
+   * reliable), or, for File nodes, the commit that added the file
+   */
+  matchedBy: 'declaration' | 'name' | 'file';
+}
+
+export interface IntroducedInOptions {
+  /** Commit the graph was built from (default HEAD) */
+  ref?: string;
+}
+
+const escapeRegex = (value: string): string => value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+
+const SPACE = '[[:space:]]';
+const NOT_IDENT = '[^A-Za-z0-9_]';
+
+/** ERE matching the Go declaration of a symbol, or null for other labels */
+const goDeclarationPattern = (node: GraphNode): string | null => {
+  const name = escapeRegex(node.properties.name);
+  switch (node.label) {
+    case 'Function':
+      return `func${SPACE}+${name}${SPACE}*[[(]`;
+    case 'Method': {
+      const receiver = node.properties.receiverType ? escapeRegex(node.properties.receiverType) : '';
+      return `func${SPACE}*\\([^)]*${receiver}[^)]*\\)${SPACE}*${name}${SPACE}*[[(]`;
+    }
+    case 'Struct':
+    case 'Interface':
+    case 'TypeAlias':
+      return `type${SPACE}+${name}(${NOT_IDENT}|$)`;
+    case 'Const':
+      return `const${SPACE}+${name}(${NOT_IDENT}|$)`;
+    case 'Static':
+      return `var${SPACE}+${name}(${NOT_IDENT}|$)`;
+    default:
+      return null;
+  }
+};
+
+const wholeWordPattern = (name: string): string => `(^|${NOT_IDENT})${escapeRegex(name)}(${NOT_IDENT}|$)`;
+
+/**
+ * Create a lookup of introducing commits per symbol. Searches are cached,
+ * so each symbol's history is walked at most once.
+ */
+export const createIntroducedInLookup = (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  options: IntroducedInOptions = {},
+) => {
+  const ref = options.ref || 'HEAD';
+  const searchCache = new Map<string, CommitInfo | null>();
+
+  const search = (filePath: string, pattern: string | null): CommitInfo | null => {
+    const key = `${filePath}\0${pattern ?? ''}`;
+    if (searchCache.has(key)) return searchCache.get(key)!;
+    let commit: CommitInfo | null;
+    try {
+      commit = findIntroducingCommit(repoPath, filePath, pattern, ref);
+    } catch {
+      // Untracked at ref, unknown ref, or outside the repo
+      commit = null;
+    }
+    searchCache.set(key, commit);
+    return commit;
+  };
+
+  /**
+   * The commit that introduced a symbol (or file), or null when the symbol
+   * is unknown or its history can't be searched.
+   */
+  const getIntroducedIn = (symbolId: string): IntroducedIn | null => {
+    const node = graph.getNode(symbolId);
+    const filePath = node?.properties.filePath;
+    if (!node || !filePath) return null;
+
+    if (node.label === 'File') {
+      const added = search(filePath, null);
+      return added ? { ...added, matchedBy: 'file' } : null;
+    }
+    if (!node.properties.name) return null;
+
+    const declaration = filePath.endsWith('.go') ? goDeclarationPattern(node) : null;
+    if (declaration) {
+      const found = search(filePath, declaration);
+      if (found) return { ...found, matchedBy: 'declaration' };
+    }
+    const found = search(filePath, wholeWordPattern(node.properties.name));
+    return found ? { ...found, matchedBy: 'name' } : null;
+  };
+
+  return {
+    getIntroducedIn,
+    /** Drop cached searches, e.g. after the graph was updated to a new commit */
+    clear: () => searchCache.clear(),
+  };
+};
@@ -0,0 +1,347 @@
+/**
+ * JSON Export
+ *
+ * Serializes the whole knowledge graph (nodes + edges) for other tools.
+ * Output is deterministic: nodes are sorted by id and edges by
+ * source/type/target, so exports of the same tree diff cleanly.
+ *
+ * Schema (version GRAPH_JSON_SCHEMA_VERSION):
+ *   { schemaVersion, generator, idScheme, repoPath?, commit?,
+ *     nodes: [{ id, kind, label, name, filePath, startLine, endLine, properties }],
+ *     edges: [{ id, source, target, type, confidence, reason, step?, callLines?, usageSites? }],
+ *     externalCalls?: ExternalCall[] }
+ *
+ * Node ids are `Label:filePath:name` (`Recv.Name` for Go methods) and
+ * depend only on the file, so an unchanged file keeps its ids across runs;
+ * with `idScheme: 'qualified'` Go symbols use `importPath.Name` instead (see
+ * symbol-ids for both schemes and how collisions are resolved). Community
+ * and process ids come from clustering and may change between runs. Struct
+ * fields are emitted as `field` nodes (`Field:filePath:Struct.name`, or
+ * `importPath.Struct.name`) linked by HAS_FIELD edges.
+ *
+ * Go type strings come with their structured form (see type-exprs): field
+ * nodes carry `typeExpr` next to `typeString`, Go funcs and methods
+ * `signatureTypes` ({ params, results, variadic }) next to `signature`.
+ */
+
+import { KnowledgeGraph, GraphNode, NodeLabel, TypeUsageSite, FileImport } from './types.js';
+import { ExternalCall } from './call-graph.js';
+import { generateId } from '../../lib/utils.js';
+import { createSymbolIdMapper, SymbolIdScheme } from './symbol-ids.js';
+import { parseTypeExpr, parseSignatureTypeExpr, getFileImports } from './type-exprs.js';
+
+/** Bump when a field is removed or changes meaning; additions keep the version */
+export const GRAPH_JSON_SCHEMA_VERSION = 1;
+
+export type GraphJSONKind =
+  | 'folder' | 'file' | 'package' | 'module'
+  | 'func' | 'method' | 'type' | 'field' | 'const' | 'var'
+  | 'import' | 'community' | 'process' | 'other';
+
+export interface GraphJSONNode {
+  id: string;
+  kind: GraphJSONKind;
+  /** Graph label the kind was derived from (`Struct`, `Interface`, ...) */
+  label: string;
+  name: string;
+  filePath: string;
+  /** 0-based rows */
+  startLine: number | null;
+  endLine: number | null;
+  /** Remaining node properties (docComment, signature, receiverType, meta, ...) */
+  properties: Record<string, unknown>;
+}
+
+export interface GraphJSONEdge {
+  id: string;
+  source: string;
+  target: string;
+  type: string;
+  confidence: number;
+  reason: string;
+  step?: number;
+  callLines?: number[];
+  usageSites?: TypeUsageSite[];
+}
+
+export interface GraphJSON {
+  schemaVersion: number;
+  generator: 'gitnexus';
+  idScheme: SymbolIdScheme;
+  repoPath?: string;
+  commit?: string;
+  nodes: GraphJSONNode[];
+  edges: GraphJSONEdge[];
+  externalCalls?: ExternalCall[];
+}
+
+export interface GraphJSONOptions {
+  repoPath?: string;
+  commit?: string;
+  /** Calls with no target node (stdlib, third-party, unresolved) */
+  externalCalls?: ExternalCall[];
+  /** Node id scheme (default 'path', the graph's own ids) */
+  idScheme?: SymbolIdScheme;
+}
+
+const KIND_BY_LABEL: Partial<Record<NodeLabel, GraphJSONKind>> = {
+  Folder: 'folder',
+  File: 'file',
+  Package: 'package',
+  Module: 'module',
+  Namespace: 'package',
+  Function: 'func',
+  Method: 'method',
+  Constructor: 'method',
+  Class: 'type',
+  Struct: 'type',
+  Interface: 'type',
+  Type: 'type',
+  TypeAlias: 'type',
+  Typedef: 'type',
+  Enum: 'type',
+  Union: 'type',
+  Trait: 'type',
+  Record: 'type',
+  Delegate: 'type',
+  Property: 'field',
+  Const: 'const',
+  Static: 'var',
+  Variable: 'var',
+  Import: 'import',
+  Community: 'community',
+  Process: 'process',
+};
+
+/** Export kind for a graph label (`Struct` -> 'type', `Static` -> 'var') */
+export const getSymbolKind = (label: string): GraphJSONKind =>
+  KIND_BY_LABEL[label as NodeLabel] ?? 'other';
+
+/** Properties promoted to top-level node keys or expanded into field nodes */
+const PROMOTED_PROPERTIES = new Set(['name', 'filePath', 'startLine', 'endLine', 'fields']);
+
+const compareIds = (a: string, b: string): number => (a < b ? -1 : a > b ? 1 : 0);
+
+const stripUndefined = (props: Record<string, unknown>): Record<string, unknown> => {
+  const out: Record<string, unknown> = {};
+  for (const key of Object.keys(props).sort()) {
+    if (PROMOTED_PROPERTIES.has(key) || props[key] === undefined) continue;
+    out[key] = props[key];
+  }
+  return out;
+};
+
+const isGoFunc = (node: GraphNode): boolean =>
+  (node.label === 'Function' || node.label === 'Method') && node.properties.language === 'go' && !!node.properties.signature;
+
+/**
+ * A node or edge reduced to its sort keys; the record itself is built when
+ * it's written, so a streamed export never holds every record at once.
+ */
+interface PendingNode {
+  id: string;
+  build: () => GraphJSONNode;
+}
+
+interface PendingEdge {
+  id: string;
+  source: string;
+  target: string;
+  type: string;
+  build: () => GraphJSONEdge;
+}
+
+const toJSONNode = (node: GraphNode, id: string, imports: FileImport[] = []): GraphJSONNode => {
+  const signatureTypes = isGoFunc(node) ? parseSignatureTypeExpr(node.properties.signature!, { imports }) : null;
+  return {
+    id,
+    kind: getSymbolKind(node.label),
+    label: node.label,
+    name: node.properties.name,
+    filePath: node.properties.filePath ?? '',
+    startLine: node.properties.startLine ?? null,
+    endLine: node.properties.endLine ?? null,
+    properties: stripUndefined({
+      ...node.properties,
+      ...(signatureTypes
+        ? { signatureTypes: { params: signatureTypes.params, results: signatureTypes.results, variadic: signatureTypes.variadic } }
+        : {}),
+    } as Record<string, unknown>),
+  };
+};
+
+/** Field nodes + HAS_FIELD edges for a struct's fields */
+const expandFields = (node: GraphNode, nodeId: string, imports: FileImport[] = []): { nodes: PendingNode[]; edges: PendingEdge[] } => {
+  const nodes: PendingNode[] = [];
+  const edges: PendingEdge[] = [];
+  // A struct with a qualified id has no ':' in it; its fields follow it
+  const qualified = !nodeId.includes(':');
+  for (const field of (node.properties.fields ?? [])) {
+    const id = qualified
+      ? `${nodeId}.${field.name}`
+      : generateId('Field', `${node.properties.filePath}:${node.properties.name}.${field.name}`);
+    nodes.push({
+      id,
+      build: () => {
+        const line = field.line ?? node.properties.startLine ?? null;
+        return {
+          id,
+          kind: 'field',
+          label: 'Field',
+          name: field.name,
+          filePath: node.properties.filePath,
+          startLine: line,
+          endLine: line,
+          properties: stripUndefined({
+            typeString: field.typeString,
+            typeExpr: node.properties.language === 'go' ? parseTypeExpr(field.typeString, { imports }) : undefined,
+            tag: field.tag,
+            isExported: field.exported,
+            embedded: field.embedded,
+            docComment: field.doc,
+          }),
+        };
+      },
+    });
+    const edgeId = generateId('HAS_FIELD', `${nodeId}->${id}`);
+    edges.push({
+      id: edgeId,
+      source: nodeId,
+      target: id,
+      type: 'HAS_FIELD',
+      build: () => ({ id: edgeId, source: nodeId, target: id, type: 'HAS_FIELD', confidence: 1.0, reason: '' }),
+    });
+  }
+  return { nodes, edges };
+};
+
+/**
+ * Edge ids are `TYPE:<source id>...-><target id>`; carry the endpoints'
+ * ids over to the export's scheme without touching the rest.
+ */
+const remapEdgeId = (id: string, type: string, sourceId: string, source: string, targetId: string, target: string): string => {
+  let out = id;
+  const head = `${type}:${sourceId}`;
+  if (source !== sourceId && out.startsWith(head)) out = `${type}:${source}${out.substring(head.length)}`;
+  const tail = `->${targetId}`;
+  if (target !== targetId && out.endsWith(tail)) out = `${out.substring(0, out.length - tail.length)}->${target}`;
+  return out;
+};
+
+interface PendingGraph {
+  idScheme: SymbolIdScheme;
+  nodes: PendingNode[];
+  edges: PendingEdge[];
+  externalCalls?: ExternalCall[];
+}
+
+/** Sort keys for every node and edge, in export order */
+const collectGraph = (graph: KnowledgeGraph, options: GraphJSONOptions): PendingGraph => {
+  const nodes: PendingNode[] = [];
+  const edges: PendingEdge[] = [];
+  const idScheme = options.idScheme ?? 'path';
+  const mapId = createSymbolIdMapper(graph, idScheme);
+  const imports = getFileImports(graph);
+
+  graph.forEachNode(node => {
+    const id = mapId(node.id);
+    const fileImports = imports.get(node.properties.filePath);
+    nodes.push({ id, build: () => toJSONNode(node, id, fileImports) });
+    if (node.properties.fields?.length) {
+      const expanded = expandFields(node, id, fileImports);
+      nodes.push(...expanded.nodes);
+      edges.push(...expanded.edges);
+    }
+  });
+
+  graph.forEachRelationship(rel => {
+    const source = mapId(rel.sourceId);
+    const target = mapId(rel.targetId);
+    const id = remapEdgeId(rel.id, rel.type, rel.sourceId, source, rel.targetId, target);
+    edges.push({
+      id,
+      source,
+      target,
+      type: rel.type,
+      build: () => ({
+        id,
+        source,
+        target,
+        type: rel.type,
+        confidence: rel.confidence,
+        reason: rel.reason,
+        ...(rel.step !== undefined ? { step: rel.step } : {}),
+        ...(rel.callLines?.length ? { callLines: [...rel.callLines].sort((a, b) => a - b) } : {}),
+        ...(rel.usageSites?.length
+          ? { usageSites: [...rel.usageSites].sort((a, b) => a.line - b.line || a.column - b.column) }
+          : {}),
+      }),
+    });
+  });
+
+  nodes.sort((a, b) => compareIds(a.id, b.id));
+  edges.sort((a, b) =>
+    compareIds(a.source, b.source) || compareIds(a.type, b.type) ||
+    compareIds(a.target, b.target) || compareIds(a.id, b.id));
+
+  const externalCalls = options.externalCalls
+    ?.map(call => ({ ...call, sourceId: mapId(call.sourceId), callLines: [...call.callLines].sort((a, b) => a - b) }))
+    .sort((a, b) => compareIds(a.sourceId, b.sourceId) || compareIds(a.calleeName, b.calleeName));
+
+  return { idScheme, nodes, edges, ...(externalCalls ? { externalCalls } : {}) };
+};
+
+const headerOf = (pending: PendingGraph, options: GraphJSONOptions) => ({
+  schemaVersion: GRAPH_JSON_SCHEMA_VERSION,
+  generator: 'gitnexus' as const,
+  idScheme: pending.idScheme,
+  ...(options.repoPath ? { repoPath: options.repoPath } : {}),
+  ...(options.commit ? { commit: options.commit } : {}),
+});
+
+/**
+ * Build the export payload in memory. Prefer writeGraphJSON for large graphs.
+ */
+export const serializeGraph = (graph: KnowledgeGraph, options: GraphJSONOptions = {}): GraphJSON => {
+  const pending = collectGraph(graph, options);
+  return {
+    ...headerOf(pending, options),
+    nodes: pending.nodes.map(node => node.build()),
+    edges: pending.edges.map(edge => edge.build()),
+    ...(pending.externalCalls ? { externalCalls: pending.externalCalls } : {}),
+  };
+};
+
+const writeChunk = (out: NodeJS.WritableStream, chunk: string): Promise<void> =>
+  new Promise(resolve => {
+    if (out.write(chunk)) resolve();
+    else out.once('drain', () => resolve());
+  });
+
+/**
+ * Stream the export to `out`, one node/edge per line, honoring backpressure.
+ * Only the sort keys are held up front; each record is built as it's
+ * written. The stream is not ended.
+ */
+export const writeGraphJSON = async (
+  graph: KnowledgeGraph,
+  out: NodeJS.WritableStream,
+  options: GraphJSONOptions = {},
+): Promise<void> => {
+  const pending = collectGraph(graph, options);
+
+  const writeList = async <T>(key: string, items: T[], toJSON: (item: T) => unknown, last: boolean) => {
+    await writeChunk(out, `  ${JSON.stringify(key)}: [`);
+    for (let i = 0; i < items.length; i++) {
+      await writeChunk(out, `${i === 0 ? '\n' : ',\n'}    ${JSON.stringify(toJSON(items[i]))}`);
+    }
+    await writeChunk(out, `${items.length > 0 ? '\n  ' : ''}]${last ? '\n' : ',\n'}`);
+  };
+
+  const headerJson = JSON.stringify(headerOf(pending, options), null, 2);
+  await writeChunk(out, headerJson.slice(0, -2) + ',\n');
+  await writeList('nodes', pending.nodes, node => node.build(), false);
+  await writeList('edges', pending.edges, edge => edge.build(), !pending.externalCalls);
+  if (pending.externalCalls) await writeList('externalCalls', pending.externalCalls, call => call, true);
+  await writeChunk(out, '}\n');
+};
@@ -0,0 +1,506 @@
+/**
+ * Local Issues
+ *
+ * Findings inside Go function bodies, from a scope-aware walk of the AST:
+ *   unused   a local variable (`:=`, `var`, range and type-switch
+ *            variables) that is never read. Plain assignments and `x++`
+ *            write without reading, as the compiler sees it; parameters
+ *            and named results are never reported.
+ *   shadow   a local declaration hiding a name from an enclosing scope: an
+ *            outer local, parameter or loop variable, a package-level
+ *            symbol, or an import. The classic case is `err :=` in a nested
+ *            block, leaving the outer err unset. As with go vet's shadow
+ *            check, a hidden local counts only if it is mentioned again
+ *            after the shadowing declaration (`strict` reports all).
+ *            `v := v` in a loop body is always reported, with `loopCopy`
+ *            (redundant since Go 1.22's per-iteration loop variables).
+ *            `switch x := x.(type)` is the idiom, not a finding.
+ * `a, err := f()` only declares the names not yet declared in the same
+ * scope; the others are assignments, as in Go.
+ *
+ * Like go to definition, the walk parses the file fresh; package-level
+ * names come from the graph. Positions are 0-based rows and byte columns of
+ * the declaring identifier, like startLine/startColumn.
+ */
+
+import { KnowledgeGraph, GraphNode } from './types.js';
+import { DefinitionFileReader } from './definition.js';
+import { generateId, goPackageDir, isGoTestFile } from '../../lib/utils.js';
+import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
+
+export type LocalIssueKind = 'unused' | 'shadow';
+
+/**
+ * What a shadowing declaration hides:
+ * - local: a variable, constant or type declared in an enclosing block
+ * - param: a receiver, parameter or named result of an enclosing function
+ * - loop-variable: a for/range variable of an enclosing loop
+ * - package: a package-level symbol of the file's package
+ * - import: a package name imported by the file
+ */
+export type ShadowedKind = 'local' | 'param' | 'loop-variable' | 'package' | 'import';
+
+export interface LocalIssue {
+  kind: LocalIssueKind;
+  name: string;
+  filePath: string;
+  line: number;
+  column: number;
+  /** Function or method whose body holds the declaration */
+  symbolId: string;
+  /** shadow: the hidden declaration (row of the import spec for imports) */
+  shadowed?: { kind: ShadowedKind; line: number; column: number; nodeId?: string };
+  /** shadow: `v := v` copying a loop variable */
+  loopCopy?: boolean;
+}
+
+type BindingKind = 'var' | 'const' | 'type' | 'param' | 'loop-variable';
+
+export interface LocalIssueOptions {
+  /** Report every shadowed local, not only those mentioned after the shadowing declaration */
+  strict?: boolean;
+}
+
+interface Binding {
+  name: string;
+  kind: BindingKind;
+  nameNode: any;
+  used: boolean;
+  /** Start of the last read or write */
+  lastMention: number;
+}
+
+type Scope = Map<string, Binding>;
+
+/** A package-level name visible in the file */
+interface OuterName {
+  kind: 'package' | 'import';
+  line: number;
+  column: number;
+  nodeId?: string;
+}
+
+const PACKAGE_LEVEL_LABELS = new Set(['Function', 'Struct', 'Interface', 'TypeAlias', 'Const', 'Static']);
+const CASE_CLAUSES = new Set(['expression_case', 'default_case', 'type_case', 'communication_case']);
+
+const field = (node: any, name: string): any => node?.childForFieldName?.(name) ?? null;
+
+const shadowedKind = (kind: BindingKind): ShadowedKind =>
+  kind === 'param' || kind === 'loop-variable' ? kind : 'local';
+
+/** Identifiers of an expression list, or the node itself when it is one */
+const listIdentifiers = (list: any): any[] =>
+  !list ? [] : list.type === 'identifier' ? [list] : (list.namedChildren ?? []).filter((c: any) => c.type === 'identifier');
+
+/** Source text between two children: does the statement declare (`:=`) rather than assign? */
+const declaresBetween = (stmt: any, left: any, right: any): boolean =>
+  !!left && !!right && stmt.text.substring(left.endIndex - stmt.startIndex, right.startIndex - stmt.startIndex).includes(':=');
+
+/** Names of a parameter list: receiver, params, results, type params */
+const parameterNames = (list: any): any[] => {
+  const names: any[] = [];
+  for (const param of list?.namedChildren ?? []) {
+    if (!param.type.endsWith('parameter_declaration')) continue;
+    const type = field(param, 'type');
+    for (const id of param.namedChildren ?? []) {
+      if (id.type === 'identifier' && !(type && id.startIndex === type.startIndex)) names.push(id);
+    }
+  }
+  return names;
+};
+
+/**
+ * Issues of one function body. `outer` holds the package-level names of
+ * the file (symbols of its package, imports).
+ */
+const analyzeFunction = (
+  funcNode: any,
+  filePath: string,
+  symbolId: string,
+  outer: Map<string, OuterName>,
+  strict: boolean,
+): LocalIssue[] => {
+  const issues: LocalIssue[] = [];
+  const scopes: Scope[] = [];
+  /** Shadowed locals, kept if the hidden binding is mentioned after `start` */
+  const shadows: { issue: LocalIssue; hidden: Binding; start: number }[] = [];
+
+  const position = (nameNode: any) => ({ line: nameNode.startPosition.row, column: nameNode.startPosition.column });
+
+  const lookup = (name: string): Binding | null => {
+    for (let i = scopes.length - 1; i >= 0; i--) {
+      const binding = scopes[i].get(name);
+      if (binding) return binding;
+    }
+    return null;
+  };
+
+  const push = () => scopes.push(new Map());
+  const pop = () => {
+    for (const binding of scopes.pop()!.values()) {
+      if (binding.used || (binding.kind !== 'var' && binding.kind !== 'loop-variable')) continue;
+      issues.push({ kind: 'unused', name: binding.name, filePath, ...position(binding.nameNode), symbolId });
+    }
+  };
+
+  /** Bind a name in the innermost scope, reporting what it shadows */
+  const declare = (nameNode: any, kind: BindingKind, options: { initializer?: any; exempt?: boolean } = {}) => {
+    const name = nameNode.text;
+    if (name === '_') return;
+    const current = scopes[scopes.length - 1];
+    const hidden = lookup(name);
+    if (!options.exempt && kind !== 'param') {
+      if (hidden && !current.has(name)) {
+        const loopCopy = hidden.kind === 'loop-variable' && options.initializer?.type === 'identifier'
+          && options.initializer.text === name;
+        const issue: LocalIssue = {
+          kind: 'shadow', name, filePath, ...position(nameNode), symbolId,
+          shadowed: { kind: shadowedKind(hidden.kind), ...position(hidden.nameNode) },
+          ...(loopCopy ? { loopCopy: true } : {}),
+        };
+        if (loopCopy || strict) issues.push(issue);
+        else shadows.push({ issue, hidden, start: nameNode.startIndex });
+      } else if (!hidden) {
+        const pkg = outer.get(name);
+        if (pkg) {
+          issues.push({
+            kind: 'shadow', name, filePath, ...position(nameNode), symbolId,
+            shadowed: { kind: pkg.kind, line: pkg.line, column: pkg.column, ...(pkg.nodeId ? { nodeId: pkg.nodeId } : {}) },
+          });
+        }
+      }
+    }
+    current.set(name, { name, kind, nameNode, used: false, lastMention: nameNode.startIndex });
+  };
+
+  const mention = (ident: any, read: boolean) => {
+    const binding = lookup(ident.text);
+    if (!binding) return;
+    if (read) binding.used = true;
+    binding.lastMention = Math.max(binding.lastMention, ident.startIndex);
+  };
+
+  /** An assignment target: a bare name is written, anything else (`x.f`, `x[i]`, `*p`) reads its operand */
+  const assignTarget = (target: any) => {
+    if (target.type === 'identifier') mention(target, false);
+    else visit(target);
+  };
+
+  /** `a, b := x, y`: right side first, then the names new to this scope */
+  const shortVar = (left: any, right: any, kind: BindingKind = 'var') => {
+    if (right) visit(right);
+    const current = scopes[scopes.length - 1];
+    const values = right?.type === 'expression_list' ? right.namedChildren ?? [] : right ? [right] : [];
+    listIdentifiers(left).forEach((id, index) => {
+      if (current.has(id.text)) {
+        mention(id, false);
+        return;
+      }
+      declare(id, kind, { initializer: values.length === listIdentifiers(left).length ? values[index] : undefined });
+    });
+  };
+
+  const specs = (decl: any, kind: BindingKind) => {
+    for (const spec of decl.namedChildren ?? []) {
+      if (spec.type.endsWith('_spec_list')) {
+        specs(spec, kind);
+      } else if (spec.type === 'type_spec' || spec.type === 'type_alias') {
+        const name = field(spec, 'name');
+        if (name) declare(name, 'type');
+        for (const child of spec.namedChildren ?? []) if (child !== name && child.startIndex !== name?.startIndex) visit(child);
+      } else if (spec.type === 'var_spec' || spec.type === 'const_spec') {
+        const names = (spec.namedChildren ?? []).filter((c: any) => c.type === 'identifier');
+        const type = field(spec, 'type');
+        const value = field(spec, 'value');
+        if (type) visit(type);
+        if (value) visit(value);
+        const values = value?.namedChildren ?? [];
+        names.forEach((id: any, index: number) =>
+          declare(id, kind, { initializer: values.length === names.length ? values[index] : undefined }));
+      }
+    }
+  };
+
+  /** Statements of a block, in the current scope */
+  const visitStatements = (block: any) => {
+    for (const stmt of block?.namedChildren ?? []) {
+      if (stmt.type === 'statement_list') visitStatements(stmt);
+      else visit(stmt);
+    }
+  };
+
+  const visitFunction = (fn: any) => {
+    push();
+    for (const list of ['receiver', 'type_parameters', 'parameters', 'result']) {
+      for (const id of parameterNames(field(fn, list))) declare(id, 'param');
+    }
+    // Parameters and the body's top-level declarations share one block
+    visitStatements(field(fn, 'body'));
+    pop();
+  };
+
+  const visit = (node: any): void => {
+    if (!node) return;
+    switch (node.type) {
+      case 'identifier':
+      case 'type_identifier':
+        mention(node, true);
+        return;
+      case 'field_identifier':
+      case 'package_identifier':
+      case 'label_name':
+        return;
+      case 'func_literal':
+        visitFunction(node);
+        return;
+      case 'function_type':
+        // Parameter names of a func type declare nothing
+        for (const list of ['parameters', 'result']) {
+          for (const param of field(node, list)?.namedChildren ?? []) visit(param.type.endsWith('parameter_declaration') ? field(param, 'type') : param);
+        }
+        return;
+      case 'selector_expression':
+        visit(field(node, 'operand'));
+        return;
+      case 'block':
+        push();
+        visitStatements(node);
+        pop();
+        return;
+      case 'short_var_declaration':
+        shortVar(field(node, 'left'), field(node, 'right'));
+        return;
+      case 'var_declaration':
+        specs(node, 'var');
+        return;
+      case 'const_declaration':
+        specs(node, 'const');
+        return;
+      case 'type_declaration':
+        specs(node, 'type');
+        return;
+      case 'assignment_statement': {
+        const left = field(node, 'left');
+        for (const target of left?.type === 'expression_list' ? left.namedChildren ?? [] : [left]) if (target) assignTarget(target);
+        visit(field(node, 'right'));
+        return;
+      }
+      case 'inc_statement':
+      case 'dec_statement':
+        for (const child of node.namedChildren ?? []) assignTarget(child);
+        return;
+      case 'if_statement':
+        push();
+        visit(field(node, 'initializer'));
+        visit(field(node, 'condition'));
+        visit(field(node, 'consequence'));
+        visit(field(node, 'alternative'));
+        pop();
+        return;
+      case 'for_statement':
+        push();
+        for (const child of node.namedChildren ?? []) {
+          if (child.type === 'for_clause') {
+            const init = field(child, 'initializer');
+            if (init?.type === 'short_var_declaration') shortVar(field(init, 'left'), field(init, 'right'), 'loop-variable');
+            else visit(init);
+            visit(field(child, 'condition'));
+            visit(field(child, 'update'));
+          } else if (child.type === 'range_clause') {
+            const left = field(child, 'left');
+            const right = field(child, 'right');
+            if (declaresBetween(child, left, right)) {
+              visit(right);
+              for (const id of listIdentifiers(left)) declare(id, 'loop-variable');
+            } else {
+              for (const target of left?.namedChildren ?? []) assignTarget(target);
+              visit(right);
+            }
+          } else {
+            visit(child);
+          }
+        }
+        pop();
+        return;
+      case 'expression_switch_statement':
+      case 'select_statement':
+        push();
+        for (const child of node.namedChildren ?? []) visit(child);
+        pop();
+        return;
+      case 'type_switch_statement': {
+        push();
+        visit(field(node, 'initializer'));
+        const value = field(node, 'value');
+        visit(value);
+        const alias = listIdentifiers(field(node, 'alias'))[0];
+        // Declared per case clause in Go; one binding, read if any clause reads it
+        if (alias) declare(alias, 'var', { exempt: !!value && value.text === alias.text });
+        for (const child of node.namedChildren ?? []) if (CASE_CLAUSES.has(child.type)) visit(child);
+        pop();
+        return;
+      }
+      case 'communication_case': {
+        push();
+        const comm = field(node, 'communication');
+        if (comm?.type === 'receive_statement' && declaresBetween(comm, field(comm, 'left'), field(comm, 'right'))) {
+          shortVar(field(comm, 'left'), field(comm, 'right'));
+        } else {
+          visit(comm);
+        }
+        for (const child of node.namedChildren ?? []) if (!comm || child.startIndex !== comm.startIndex) visitStatementOrNode(child);
+        pop();
+        return;
+      }
+      case 'expression_case':
+      case 'default_case':
+      case 'type_case':
+        push();
+        for (const child of node.namedChildren ?? []) visitStatementOrNode(child);
+        pop();
+        return;
+      case 'receive_statement':
+        if (declaresBetween(node, field(node, 'left'), field(node, 'right'))) shortVar(field(node, 'left'), field(node, 'right'));
+        else for (const child of node.namedChildren ?? []) visit(child);
+        return;
+      default:
+        for (const child of node.namedChildren ?? []) visit(child);
+    }
+  };
+
+  const visitStatementOrNode = (node: any) => {
+    if (node.type === 'statement_list') visitStatements(node);
+    else visit(node);
+  };
+
+  visitFunction(funcNode);
+  for (const { issue, hidden, start } of shadows) {
+    if (hidden.lastMention > start) issues.push(issue);
+  }
+  return issues;
+};
+
+const cmp = (a: string, b: string): number => (a < b ? -1 : a > b ? 1 : 0);
+
+const sortIssues = (issues: LocalIssue[]): LocalIssue[] =>
+  issues.sort((a, b) => a.line - b.line || a.column - b.column || cmp(a.kind, b.kind));
+
+/**
+ * Create an analyzer over a graph. Package-level names are indexed on first
+ * use; call clear() after the graph changes.
+ */
+export const createLocalIssueAnalyzer = (graph: KnowledgeGraph, readFile: DefinitionFileReader) => {
+  let symbolsByDir: Map<string, GraphNode[]> | null = null;
+
+  const symbolsIn = (dir: string): GraphNode[] => {
+    if (!symbolsByDir) {
+      symbolsByDir = new Map();
+      graph.forEachNode(node => {
+        const { filePath } = node.properties;
+        if (!filePath?.endsWith('.go') || !PACKAGE_LEVEL_LABELS.has(node.label)) return;
+        const key = goPackageDir(filePath);
+        let nodes = symbolsByDir!.get(key);
+        if (!nodes) {
+          nodes = [];
+          symbolsByDir!.set(key, nodes);
+        }
+        nodes.push(node);
+      });
+    }
+    return symbolsByDir.get(dir) ?? [];
+  };
+
+  /** Package-level symbols of the file's package, then imports (which win: they are file-scoped) */
+  const outerNames = (filePath: string): Map<string, OuterName> => {
+    const names = new Map<string, OuterName>();
+    for (const node of symbolsIn(goPackageDir(filePath))) {
+      if (isGoTestFile(node.properties.filePath) && !isGoTestFile(filePath)) continue;
+      if (names.has(node.properties.name)) continue;
+      names.set(node.properties.name, {
+        kind: 'package',
+        line: node.properties.startLine ?? 0,
+        column: node.properties.startColumn ?? 0,
+        nodeId: node.id,
+      });
+    }
+    const fileNode = graph.getNode(generateId('File', filePath));
+    for (const imp of fileNode?.properties.imports ?? []) {
+      if (imp.kind === 'dot' || imp.kind === 'blank') continue;
+      names.set(imp.localName, { kind: 'import', line: imp.line, column: 0 });
+    }
+    return names;
+  };
+
+  /** Function and method declarations of a parsed file, with their graph node */
+  const functionsInTree = (rootNode: any, filePath: string): { fn: any; node: GraphNode }[] => {
+    const found: { fn: any; node: GraphNode }[] = [];
+    const byPosition = new Map<string, GraphNode>();
+    graph.forEachNode(node => {
+      if ((node.label === 'Function' || node.label === 'Method') && node.properties.filePath === filePath) {
+        byPosition.set(`${node.properties.startLine}:${node.properties.startColumn ?? ''}`, node);
+        byPosition.set(`${node.properties.startLine}:`, node);
+      }
+    });
+    for (const decl of rootNode.namedChildren ?? []) {
+      if (decl.type !== 'function_declaration' && decl.type !== 'method_declaration') continue;
+      const name = field(decl, 'name');
+      if (!name || !field(decl, 'body')) continue;
+      const node = byPosition.get(`${name.startPosition.row}:${name.startPosition.column}`)
+        ?? byPosition.get(`${name.startPosition.row}:`);
+      if (node) found.push({ fn: decl, node });
+    }
+    return found;
+  };
+
+  /** Issues of every function in a parsed file, or of the one with `symbolId` */
+  const localIssuesInTree = (
+    rootNode: any,
+    filePath: string,
+    options: LocalIssueOptions & { symbolId?: string } = {},
+  ): LocalIssue[] => {
+    const outer = outerNames(filePath);
+    const issues: LocalIssue[] = [];
+    for (const { fn, node } of functionsInTree(rootNode, filePath)) {
+      if (options.symbolId && node.id !== options.symbolId) continue;
+      issues.push(...analyzeFunction(fn, filePath, node.id, outer, !!options.strict));
+    }
+    return sortIssues(issues);
+  };
+
+  const parseFile = async (filePath: string): Promise<any> => {
+    const content = await readFile(filePath);
+    if (content === null) throw new Error(`Cannot read ${filePath}`);
+    const parser = await loadParser();
+    await loadLanguage(SupportedLanguages.Go, filePath);
+    return parser.parse(content, undefined, { bufferSize: 1024 * 256 }).rootNode;
+  };
+
+  /**
+   * Issues in the body of a Go function or method, sorted by position.
+   * Empty for unknown symbols and anything else; throws when the file
+   * can't be read.
+   */
+  const getLocalIssues = async (symbolId: string, options: LocalIssueOptions = {}): Promise<LocalIssue[]> => {
+    const node = graph.getNode(symbolId);
+    if (!node || (node.label !== 'Function' && node.label !== 'Method')) return [];
+    if (!node.properties.filePath?.endsWith('.go')) return [];
+    return localIssuesInTree(await parseFile(node.properties.filePath), node.properties.filePath, { ...options, symbolId });
+  };
+
+  /** Issues of every function and method in a Go file */
+  const getFileLocalIssues = async (filePath: string, options: LocalIssueOptions = {}): Promise<LocalIssue[]> => {
+    if (!filePath.endsWith('.go')) return [];
+    return localIssuesInTree(await parseFile(filePath), filePath, options);
+  };
+
+  return {
+    getLocalIssues,
+    getFileLocalIssues,
+    localIssuesInTree,
+    /** Drop the package-level index, e.g. after the graph was updated */
+    clear: () => {
+      symbolsByDir = null;
+    },
+  };
+};
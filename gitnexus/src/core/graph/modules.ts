@@ -0,0 +1,108 @@
+/**
+ * Go Modules
+ *
+ * A repo may hold several Go modules, one per go.mod. Each module owns its
+ * directory tree minus any nested module's tree, and a package's import path
+ * is the module path plus the package directory relative to the go.mod
+ * (`github.com/acme/api` + `internal/auth`).
+ *
+ * Modules are recorded on their go.mod File nodes at analysis time (see
+ * go-module-processor), so everything here works from the graph alone.
+ *
+ * Import resolution, as the importing module's build would see it:
+ *   1. the importing module's `replace` directives that point at a local
+ *      directory inside the repo (`=> ../shared`); versioned replaces apply
+ *      to every version, since the index has one copy of each module
+ *   2. any module in the repo whose path is a prefix of the import path,
+ *      longest first — as with a go.work covering the repo. Directories
+ *      owned by a nested module are not part of the outer one.
+ * Anything else is outside the repo.
+ */
+
+import { KnowledgeGraph, GoModule } from './types.js';
+import { goPackageDir } from '../../lib/utils.js';
+
+const cmp = (a: string, b: string): number => (a < b ? -1 : a > b ? 1 : 0);
+
+/** Modules in the repo, sorted by directory */
+export const getGoModules = (graph: KnowledgeGraph): GoModule[] => {
+  const modules: GoModule[] = [];
+  graph.forEachNode(node => {
+    if (node.label === 'File' && node.properties.goMod) modules.push(node.properties.goMod);
+  });
+  return modules.sort((a, b) => cmp(a.dir, b.dir));
+};
+
+export interface GoModuleResolver {
+  readonly modules: GoModule[];
+  /** Module owning a repo-relative file path, or null (no enclosing go.mod) */
+  moduleForFile: (filePath: string) => GoModule | null;
+  /** Import path of the package in directory `dir` ('' = repo root), or null */
+  importPathForDir: (dir: string) => string | null;
+  /**
+   * Repo-relative package directory ('' = repo root) for an import written
+   * in `fromFile`, or null when the package is outside the repo.
+   */
+  resolveImportDir: (importPath: string, fromFile?: string) => string | null;
+}
+
+const joinDir = (dir: string, rest: string): string => (dir && rest ? `${dir}/${rest}` : dir || rest);
+
+/** `rest` of `importPath` below `prefix`, or null when it isn't under it */
+const pathBelow = (importPath: string, prefix: string): string | null => {
+  if (importPath === prefix) return '';
+  return importPath.startsWith(prefix + '/') ? importPath.substring(prefix.length + 1) : null;
+};
+
+export const createGoModuleResolver = (modules: GoModule[]): GoModuleResolver => {
+  // Deepest directory first, so nested modules win over the outer one
+  const byDepth = [...modules].sort((a, b) => b.dir.length - a.dir.length || cmp(a.dir, b.dir));
+  // Longest module path first
+  const byPath = [...modules].sort((a, b) => b.path.length - a.path.length || cmp(a.dir, b.dir));
+  const resolved = new Map<string, string | null>();
+
+  const moduleForDir = (dir: string): GoModule | null =>
+    byDepth.find(m => m.dir === '' || dir === m.dir || dir.startsWith(m.dir + '/')) ?? null;
+
+  const moduleForFile = (filePath: string): GoModule | null => moduleForDir(goPackageDir(filePath));
+
+  const importPathForDir = (dir: string): string | null => {
+    const module = moduleForDir(dir);
+    if (!module) return null;
+    const rel = module.dir === '' ? dir : dir.substring(module.dir.length + 1);
+    return rel ? `${module.path}/${rel}` : module.path;
+  };
+
+  const resolveImportDir = (importPath: string, fromFile?: string): string | null => {
+    const from = fromFile ? moduleForFile(fromFile) : null;
+    const key = `${from?.dir ?? '-'}\0${importPath}`;
+    const cached = resolved.get(key);
+    if (cached !== undefined) return cached;
+
+    let dir: string | null = null;
+    const replaces = (from?.replaces ?? [])
+      .filter(r => r.localDir !== undefined)
+      .sort((a, b) => b.oldPath.length - a.oldPath.length);
+    for (const replace of replaces) {
+      const rest = pathBelow(importPath, replace.oldPath);
+      if (rest !== null) {
+        dir = joinDir(replace.localDir!, rest);
+        break;
+      }
+    }
+    if (dir === null) {
+      for (const module of byPath) {
+        const rest = pathBelow(importPath, module.path);
+        if (rest === null) continue;
+        const candidate = joinDir(module.dir, rest);
+        // A nested module owns its tree; the outer module can't import into it
+        if (moduleForDir(candidate) === module) dir = candidate;
+        break;
+      }
+    }
+    resolved.set(key, dir);
+    return dir;
+  };
+
+  return { modules, moduleForFile, importPathForDir, resolveImportDir };
+};
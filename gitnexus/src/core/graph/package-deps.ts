@@ -0,0 +1,176 @@
+/**
+ * Package Dependencies
+ *
+ * Coarse Go architecture view: one node per package (directory) in the repo,
+ * one edge per package-imports-package pair, built from the per-file imports
+ * recorded at parse time and the file-level IMPORTS edges the import
+ * processor resolved against the repo's go.mod files.
+ *
+ * Imports that don't resolve into the repo are collapsed: the whole
+ * standard library into one `ext:std` node, everything else into one node
+ * per module root (`ext:github.com/spf13/cobra`). The root is a heuristic —
+ * three path segments on github.com/gitlab.com/bitbucket.org/golang.org, two
+ * elsewhere (`go.uber.org/zap`, `k8s.io/api`).
+ *
+ * Cycles between internal packages are reported since `go build` rejects
+ * them. Test files are left out by default: an external test package
+ * (`package foo_test`) may import packages that import foo, which Go allows.
+ */
+
+import { KnowledgeGraph } from './types.js';
+import { findStronglyConnectedComponents, findCycleThrough } from './scc.js';
+import { goPackageDir, isGoTestFile } from '../../lib/utils.js';
+
+export interface PackageDepNode {
+  /** Directory path (`.` for the repo root), or `ext:*` for collapsed externals */
+  id: string;
+  kind: 'internal' | 'std' | 'external';
+  /** Go files in the package (internal only) */
+  fileCount: number;
+}
+
+export interface PackageDepEdge {
+  from: string;
+  to: string;
+  /** Files in `from` that import `to` */
+  files: number;
+}
+
+export interface PackageCycle {
+  /** Packages in the strongly connected component, sorted */
+  packages: string[];
+  /** One concrete import cycle, first package repeated at the end */
+  path: string[];
+}
+
+export interface PackageDeps {
+  packages: PackageDepNode[];
+  edges: PackageDepEdge[];
+  /** from -> sorted list of imported package ids */
+  adjacency: Record<string, string[]>;
+  cycles: PackageCycle[];
+}
+
+export interface PackageDepsOptions {
+  /** 'collapse' (default) keeps ext:* nodes; 'exclude' drops them */
+  externals?: 'collapse' | 'exclude';
+  /** Count imports from _test.go files (default false) */
+  includeTests?: boolean;
+}
+
+const THREE_SEGMENT_HOSTS = new Set(['github.com', 'gitlab.com', 'bitbucket.org', 'golang.org']);
+
+const packageOf = (filePath: string): string => goPackageDir(filePath) || '.';
+
+/** Collapsed node id for an import path outside the repo */
+const externalId = (importPath: string): { id: string; kind: 'std' | 'external' } => {
+  const segments = importPath.split('/');
+  // Standard library paths have no dot in their first element
+  if (!segments[0].includes('.')) return { id: 'ext:std', kind: 'std' };
+  const depth = THREE_SEGMENT_HOSTS.has(segments[0]) ? 3 : 2;
+  return { id: `ext:${segments.slice(0, depth).join('/')}`, kind: 'external' };
+};
+
+const cmp = (a: string, b: string): number => (a < b ? -1 : a > b ? 1 : 0);
+
+export const getPackageDeps = (graph: KnowledgeGraph, options: PackageDepsOptions = {}): PackageDeps => {
+  const includeTests = options.includeTests ?? false;
+  const collapse = (options.externals ?? 'collapse') === 'collapse';
+  const isIndexedGoFile = (path: string) =>
+    path.endsWith('.go') && (includeTests || !isGoTestFile(path));
+
+  const packages = new Map<string, PackageDepNode>();
+  // from -> to -> importing files
+  const edgeFiles = new Map<string, Map<string, Set<string>>>();
+  const addEdge = (from: string, to: string, file: string) => {
+    if (from === to) return;
+    let targets = edgeFiles.get(from);
+    if (!targets) {
+      targets = new Map();
+      edgeFiles.set(from, targets);
+    }
+    let files = targets.get(to);
+    if (!files) {
+      files = new Set();
+      targets.set(to, files);
+    }
+    files.add(file);
+  };
+
+  // Resolved internal packages per importing file
+  const resolvedDirs = new Map<string, Set<string>>();
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'IMPORTS') return;
+    const source = graph.getNode(rel.sourceId);
+    const target = graph.getNode(rel.targetId);
+    if (source?.label !== 'File' || target?.label !== 'File') return;
+    if (!isIndexedGoFile(source.properties.filePath) || !target.properties.filePath.endsWith('.go')) return;
+    const dirs = resolvedDirs.get(source.properties.filePath) ?? new Set<string>();
+    dirs.add(packageOf(target.properties.filePath));
+    resolvedDirs.set(source.properties.filePath, dirs);
+  });
+
+  graph.forEachNode(node => {
+    if (node.label !== 'File' || !isIndexedGoFile(node.properties.filePath)) return;
+    const filePath = node.properties.filePath;
+    const from = packageOf(filePath);
+    const pkg = packages.get(from) ?? { id: from, kind: 'internal' as const, fileCount: 0 };
+    pkg.fileCount++;
+    packages.set(from, pkg);
+
+    const dirs = resolvedDirs.get(filePath) ?? new Set<string>();
+    const matched = new Set<string>();
+    for (const imp of (node.properties.imports ?? [])) {
+      const dir = [...dirs].find(d => imp.path === d || imp.path.endsWith('/' + d));
+      if (dir) {
+        matched.add(dir);
+        addEdge(from, dir, filePath);
+        continue;
+      }
+      if (!collapse) continue;
+      const ext = externalId(imp.path);
+      if (!packages.has(ext.id)) packages.set(ext.id, { id: ext.id, kind: ext.kind, fileCount: 0 });
+      addEdge(from, ext.id, filePath);
+    }
+    // Resolved imports whose path didn't line up with the directory name
+    for (const dir of dirs) {
+      if (!matched.has(dir)) addEdge(from, dir, filePath);
+    }
+  });
+
+  // Targets that only appear as import destinations (e.g. test-only packages)
+  for (const targets of edgeFiles.values()) {
+    for (const to of targets.keys()) {
+      if (!packages.has(to)) packages.set(to, { id: to, kind: 'internal', fileCount: 0 });
+    }
+  }
+
+  const edges: PackageDepEdge[] = [];
+  const adjacency: Record<string, string[]> = {};
+  for (const from of [...edgeFiles.keys()].sort(cmp)) {
+    const targets = [...edgeFiles.get(from)!.keys()].sort(cmp);
+    adjacency[from] = targets;
+    for (const to of targets) edges.push({ from, to, files: edgeFiles.get(from)!.get(to)!.size });
+  }
+
+  const internalSuccessors = (id: string): string[] =>
+    (adjacency[id] ?? []).filter(to => packages.get(to)?.kind === 'internal');
+  const internalIds = [...packages.values()].filter(p => p.kind === 'internal').map(p => p.id).sort(cmp);
+  const cycles: PackageCycle[] = findStronglyConnectedComponents(internalIds, internalSuccessors)
+    .filter(component => component.length > 1)
+    .map(component => {
+      const members = [...component].sort(cmp);
+      return {
+        packages: members,
+        path: findCycleThrough(members[0], new Set(members), internalSuccessors) ?? [...members, members[0]],
+      };
+    })
+    .sort((a, b) => cmp(a.packages[0], b.packages[0]));
+
+  return {
+    packages: [...packages.values()].sort((a, b) => cmp(a.id, b.id)),
+    edges,
+    adjacency,
+    cycles,
+  };
+};
@@ -0,0 +1,38 @@
+/**
+ * Symbol Positions
+ *
+ * Parsing records locations as flat node properties (startLine, startColumn,
+ * definitionEndLine, startByte, endByte) because KuzuDB and the worker
+ * messages want plain fields. This assembles them into one value for
+ * editor integrations and snippet extraction.
+ */
+
+import { GraphNode } from './types.js';
+
+export interface SymbolPosition {
+  /** 0-based row of the symbol's name */
+  startLine: number;
+  /** 0-based byte column of the name on startLine */
+  startColumn: number;
+  /** 0-based last row of the whole definition, body included */
+  endLine: number;
+  /** UTF-8 byte offsets of the whole definition, end exclusive */
+  startByte: number;
+  endByte: number;
+}
+
+/**
+ * Position of a parsed symbol, or null for nodes without one (folders,
+ * files, communities, and nodes from parsers that report lines only).
+ */
+export const getNodePosition = (node: GraphNode): SymbolPosition | null => {
+  const { startLine, endLine, definitionEndLine, startColumn, startByte, endByte } = node.properties;
+  if (startLine === undefined || startByte === undefined || endByte === undefined) return null;
+  return {
+    startLine,
+    startColumn: startColumn ?? 0,
+    endLine: Math.max(endLine ?? startLine, definitionEndLine ?? startLine),
+    startByte,
+    endByte,
+  };
+};
@@ -0,0 +1,103 @@
+/**
+ * Strongly Connected Components
+ *
+ * Iterative Tarjan over an adjacency function, so deep graphs (long call
+ * chains, big package trees) don't overflow the JS stack. Shared by the
+ * package dependency graph and call-graph cycle detection.
+ */
+
+/**
+ * Every SCC of the graph, each listed in discovery order. Components come
+ * out in reverse topological order (a component precedes the ones that
+ * reach it). Single nodes without a self-loop are their own component, so
+ * filter on size (and selfLoop) for cycles.
+ */
+export const findStronglyConnectedComponents = (
+  nodes: Iterable<string>,
+  successors: (node: string) => Iterable<string>,
+): string[][] => {
+  const index = new Map<string, number>();
+  const lowlink = new Map<string, number>();
+  const onStack = new Set<string>();
+  const stack: string[] = [];
+  const components: string[][] = [];
+  let counter = 0;
+
+  for (const root of nodes) {
+    if (index.has(root)) continue;
+
+    // Explicit DFS frames: node + iterator over its successors
+    const frames: { node: string; next: Iterator<string> }[] = [];
+    const visit = (node: string) => {
+      index.set(node, counter);
+      lowlink.set(node, counter);
+      counter++;
+      stack.push(node);
+      onStack.add(node);
+      frames.push({ node, next: successors(node)[Symbol.iterator]() });
+    };
+    visit(root);
+
+    while (frames.length > 0) {
+      const frame = frames[frames.length - 1];
+      const step = frame.next.next();
+      if (!step.done) {
+        const succ = step.value;
+        if (!index.has(succ)) {
+          visit(succ);
+        } else if (onStack.has(succ)) {
+          lowlink.set(frame.node, Math.min(lowlink.get(frame.node)!, index.get(succ)!));
+        }
+        continue;
+      }
+
+      frames.pop();
+      if (frames.length > 0) {
+        const parent = frames[frames.length - 1].node;
+        lowlink.set(parent, Math.min(lowlink.get(parent)!, lowlink.get(frame.node)!));
+      }
+      if (lowlink.get(frame.node) === index.get(frame.node)) {
+        const component: string[] = [];
+        let member: string;
+        do {
+          member = stack.pop()!;
+          onStack.delete(member);
+          component.push(member);
+        } while (member !== frame.node);
+        components.push(component.reverse());
+      }
+    }
+  }
+
+  return components;
+};
+
+/**
+ * Shortest cycle through `start` that stays inside `members` (BFS), as a
+ * node list ending back at `start`. Null when there is none.
+ */
+export const findCycleThrough = (
+  start: string,
+  members: Set<string>,
+  successors: (node: string) => Iterable<string>,
+): string[] | null => {
+  const parent = new Map<string, string>();
+  const queue: string[] = [start];
+  for (let head = 0; head < queue.length; head++) {
+    const node = queue[head];
+    for (const succ of successors(node)) {
+      if (!members.has(succ)) continue;
+      if (succ === start) {
+        // Walk back from the closing edge to start
+        const inner: string[] = [];
+        for (let cur = node; cur !== start; cur = parent.get(cur)!) inner.push(cur);
+        return [start, ...inner.reverse(), start];
+      }
+      if (!parent.has(succ) && succ !== start) {
+        parent.set(succ, node);
+        queue.push(succ);
+      }
+    }
+  }
+  return null;
+};
@@ -0,0 +1,47 @@
+/**
+ * Signatures
+ *
+ * Helpers for the Go signature strings recorded at parse time
+ * (`Name(int, ...Option) (T, error)`: parameter types only, no names or
+ * receiver; see formatGoSignature).
+ */
+
+/** Split on top-level commas, ignoring those nested in (), [], {} */
+export const splitTopLevel = (text: string): string[] => {
+  const parts: string[] = [];
+  let depth = 0;
+  let current = '';
+  for (const c of text) {
+    if (c === '(' || c === '[' || c === '{') depth++;
+    else if (c === ')' || c === ']' || c === '}') depth--;
+    if (c === ',' && depth === 0) {
+      parts.push(current.trim());
+      current = '';
+    } else {
+      current += c;
+    }
+  }
+  if (current.trim()) parts.push(current.trim());
+  return parts;
+};
+
+/** `Name(int, string) (T, error)` -> params ['int', 'string'], results ['T', 'error'] */
+export const parseSignature = (signature: string): { params: string[]; results: string[] } | null => {
+  const open = signature.indexOf('(');
+  if (open < 0) return null;
+  let depth = 0;
+  let close = -1;
+  for (let i = open; i < signature.length; i++) {
+    if (signature[i] === '(') depth++;
+    else if (signature[i] === ')' && --depth === 0) {
+      close = i;
+      break;
+    }
+  }
+  if (close < 0) return null;
+  const rest = signature.substring(close + 1).trim();
+  const results = rest.startsWith('(') && rest.endsWith(')')
+    ? splitTopLevel(rest.slice(1, -1))
+    : rest ? [rest] : [];
+  return { params: splitTopLevel(signature.substring(open + 1, close)), results };
+};
@@ -0,0 +1,104 @@
+/**
+ * Snippets
+ *
+ * Source text of a symbol (signature + body, or the full declaration) for
+ * previews. The file is re-read and sliced by the byte span recorded at parse
+ * time, so the graph doesn't have to keep file contents in memory.
+ *
+ * The span is only meaningful for the content that was parsed. File nodes
+ * carry a contentHash of that content; when the file now hashes differently
+ * the read fails with StaleSnippetError instead of returning a wrong slice.
+ * Offsets are UTF-8 bytes over the content exactly as read, so CRLF line
+ * endings are counted like any other byte and come back unchanged.
+ */
+
+import { KnowledgeGraph } from './types.js';
+import { getNodePosition } from './position.js';
+import { generateId, hashContent } from '../../lib/utils.js';
+
+/** Reads a repo-relative file; null when it can't be read */
+export type SnippetFileReader = (relativePath: string) => Promise<string | null>;
+
+export interface SnippetOptions {
+  /** Lines of source to include above the symbol (and its doc comment) */
+  contextLines?: number;
+  /** Extend the snippet up over the comment block directly above the symbol */
+  includeDocComment?: boolean;
+}
+
+/** The file changed since it was parsed; re-index before asking again */
+export class StaleSnippetError extends Error {
+  constructor(public readonly filePath: string) {
+    super(`${filePath} has changed since it was indexed`);
+    this.name = 'StaleSnippetError';
+  }
+}
+
+const NEWLINE = 0x0a;
+
+/** Start of the line before the one starting at `lineStart`, or -1 at the top */
+const previousLineStart = (buf: Buffer, lineStart: number): number => {
+  if (lineStart <= 0) return -1;
+  // Buffer#lastIndexOf treats a negative offset as counting from the end
+  return lineStart < 2 ? 0 : buf.lastIndexOf(NEWLINE, lineStart - 2) + 1;
+};
+
+const isCommentLine = (text: string): boolean =>
+  /^(\/\/|\/\*|\*|#|--|"""|''')/.test(text);
+
+/**
+ * Source text of a symbol. Throws StaleSnippetError when the file no longer
+ * matches what was indexed, and a plain Error when the symbol is unknown,
+ * has no recorded position, or its file can't be read.
+ */
+export const getSnippet = async (
+  graph: KnowledgeGraph,
+  symbolId: string,
+  readFile: SnippetFileReader,
+  options: SnippetOptions = {},
+): Promise<string> => {
+  const node = graph.getNode(symbolId);
+  if (!node) throw new Error(`Unknown symbol: ${symbolId}`);
+  const position = getNodePosition(node);
+  if (!position) throw new Error(`No source position recorded for ${symbolId}`);
+
+  const { filePath } = node.properties;
+  const content = await readFile(filePath);
+  if (content === null) throw new Error(`Cannot read ${filePath}`);
+
+  const recordedHash = graph.getNode(generateId('File', filePath))?.properties.contentHash;
+  if (recordedHash && recordedHash !== hashContent(content)) throw new StaleSnippetError(filePath);
+
+  const buf = Buffer.from(content, 'utf8');
+  if (position.endByte > buf.length || position.startByte > position.endByte) {
+    throw new StaleSnippetError(filePath);
+  }
+
+  const lineStartOf = (offset: number): number =>
+    offset === 0 ? 0 : buf.lastIndexOf(NEWLINE, offset - 1) + 1;
+
+  let start = position.startByte;
+  const declarationLineStart = lineStartOf(start);
+
+  if (options.includeDocComment && node.properties.docComment) {
+    let lineStart = declarationLineStart;
+    for (let prev = previousLineStart(buf, lineStart); prev >= 0; prev = previousLineStart(buf, lineStart)) {
+      const text = buf.subarray(prev, lineStart).toString('utf8').trim();
+      if (!isCommentLine(text)) break;
+      lineStart = prev;
+    }
+    if (lineStart < declarationLineStart) start = lineStart;
+  }
+
+  if (options.contextLines && options.contextLines > 0) {
+    let lineStart = lineStartOf(start);
+    for (let i = 0; i < options.contextLines; i++) {
+      const prev = previousLineStart(buf, lineStart);
+      if (prev < 0) break;
+      lineStart = prev;
+    }
+    start = lineStart;
+  }
+
+  return buf.subarray(start, position.endByte).toString('utf8');
+};
@@ -0,0 +1,109 @@
+/**
+ * Symbol Authors
+ *
+ * Ownership view of the graph: who last touched the lines of a symbol,
+ * according to `git blame`. Useful for picking reviewers and mapping code
+ * owners.
+ *
+ * Blame runs at the commit the graph was built from, so a symbol's recorded
+ * line range still points at its own lines even if the working tree (or
+ * HEAD) has moved on since. Within that commit, blame itself tracks lines
+ * that shifted or moved over history (`-M`), so authorship survives code
+ * being pushed around by unrelated edits.
+ */
+
+import { KnowledgeGraph, GraphNode } from './types.js';
+import { blameFile, BlameLine } from '../../storage/git.js';
+
+export interface SymbolAuthor {
+  author: string;
+  authorEmail: string;
+  /** Lines of the symbol last changed by this author */
+  lines: number;
+  /** Most recent commit of this author within the symbol */
+  lastCommit: string;
+  /** Author time of lastCommit, seconds since epoch */
+  lastAuthorTime: number;
+}
+
+export interface SymbolAuthorOptions {
+  /** Commit the graph was built from (default HEAD) */
+  ref?: string;
+}
+
+/** 0-based inclusive row range of a symbol, body included when known */
+export const getSymbolLineRange = (node: GraphNode): [number, number] | null => {
+  const { startLine, endLine, definitionEndLine } = node.properties;
+  if (startLine === undefined) return null;
+  return [startLine, Math.max(endLine ?? startLine, definitionEndLine ?? startLine)];
+};
+
+/**
+ * Create a lookup of authors per symbol. Blame output is cached per file,
+ * so querying many symbols of one file runs `git blame` once.
+ */
+export const createSymbolAuthorLookup = (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  options: SymbolAuthorOptions = {},
+) => {
+  const ref = options.ref || 'HEAD';
+  const blameCache = new Map<string, BlameLine[] | null>();
+
+  const blameFor = (filePath: string): BlameLine[] | null => {
+    if (blameCache.has(filePath)) return blameCache.get(filePath)!;
+    let lines: BlameLine[] | null;
+    try {
+      lines = blameFile(repoPath, filePath, ref);
+    } catch {
+      // Untracked at ref, binary, or outside the repo
+      lines = null;
+    }
+    blameCache.set(filePath, lines);
+    return lines;
+  };
+
+  /**
+   * Authors of a symbol's lines, most lines first. Empty when the symbol is
+   * unknown, has no line range, or its file can't be blamed.
+   */
+  const getSymbolAuthors = (symbolId: string): SymbolAuthor[] => {
+    const node = graph.getNode(symbolId);
+    if (!node?.properties.filePath) return [];
+    const range = node.label === 'File' ? null : getSymbolLineRange(node);
+    const blame = blameFor(node.properties.filePath);
+    if (!blame) return [];
+
+    const [start, end] = range ?? [0, blame.length - 1];
+    const byAuthor = new Map<string, SymbolAuthor>();
+    for (let row = start; row <= end && row < blame.length; row++) {
+      const line = blame[row];
+      const key = line.authorEmail || line.author;
+      const entry = byAuthor.get(key);
+      if (!entry) {
+        byAuthor.set(key, {
+          author: line.author,
+          authorEmail: line.authorEmail,
+          lines: 1,
+          lastCommit: line.commit,
+          lastAuthorTime: line.authorTime,
+        });
+        continue;
+      }
+      entry.lines++;
+      if (line.authorTime > entry.lastAuthorTime) {
+        entry.lastCommit = line.commit;
+        entry.lastAuthorTime = line.authorTime;
+      }
+    }
+
+    return [...byAuthor.values()].sort((a, b) =>
+      b.lines - a.lines || b.lastAuthorTime - a.lastAuthorTime || a.author.localeCompare(b.author));
+  };
+
+  return {
+    getSymbolAuthors,
+    /** Drop cached blame, e.g. after the graph was updated to a new commit */
+    clear: () => blameCache.clear(),
+  };
+};
@@ -0,0 +1,138 @@
+/**
+ * Symbol IDs
+ *
+ * Every node in the graph has a unique id, and the JSON export can write
+ * it in one of two schemes.
+ *
+ * `path` (graph node ids; the default): `Label:filePath:name`
+ *   Function:pkg/auth/store.go:NewStore
+ *   Method:pkg/auth/store.go:Store.Get        Go methods carry the receiver's base type
+ *   Struct:pkg/auth/store.go:Store
+ *   Function:pkg/auth/store.go:init#2         n-th declaration with the same id in one file
+ * The file path makes ids unique across build-tag variants (`conn_unix.go`,
+ * `conn_windows.go`), and the receiver across methods of one name. What is
+ * left is a name declared more than once in one file, which Go allows for
+ * `init` and `_`: those get `#2`, `#3` in source order. Ids depend only on
+ * the file's path and content, so an unchanged file keeps them across runs.
+ * pathSymbolId reproduces them. Ordinals are Go-only: other languages keep
+ * `Label:filePath:name`, which their enclosing-function lookups rebuild
+ * from the name alone.
+ *
+ * `qualified` (Go symbols in a module): `importPath.Name`
+ *   example.com/app/pkg/auth.NewStore
+ *   example.com/app/pkg/auth.Store.Get
+ *   example.com/app/pkg/auth.Store.mu         struct fields in the JSON export
+ * These survive moving a symbol between files of its package. Go has no
+ * overloading, and generic types and funcs are one symbol however they are
+ * instantiated (`List[T].Get` is `List.Get`), so a collision means the
+ * package declares a name more than once: `init` in several files, or one
+ * symbol in each of several build-tag-gated files. Then every symbol in the
+ * collision is suffixed with its file, and in-file repeats with `#n`
+ * (`example.com/app.init@main.go#2`), sorted by file and line so the result
+ * doesn't depend on parse order. Nodes without a qualified form (Go outside
+ * a module, other languages, files, folders, communities) keep their path
+ * ids. qualifiedSymbolId reproduces the id before collision handling.
+ */
+
+import { KnowledgeGraph, GraphNode, NodeLabel } from './types.js';
+import { generateId, symbolIdName } from '../../lib/utils.js';
+
+export type SymbolIdScheme = 'path' | 'qualified';
+
+/** Labels that aren't symbols, or repeat a package's identity */
+const SCHEME_EXEMPT_LABELS = new Set<string>(['Project', 'Package', 'Module', 'Folder', 'File', 'Community', 'Process', 'Import']);
+
+/**
+ * Graph node id of a symbol: `Label:filePath:name` (see header). `ordinal`
+ * is the declaration's position among those with the same label and name
+ * in the file, from 1.
+ */
+export const pathSymbolId = (
+  label: NodeLabel,
+  filePath: string,
+  name: string,
+  options: { receiverType?: string; ordinal?: number } = {},
+): string => generateId(label, `${filePath}:${symbolIdName(name, options.receiverType, options.ordinal)}`);
+
+/**
+ * Qualified id of a Go symbol in a module (`importPath.Recv.Name`), before
+ * collision handling; null for nodes that have none (see header).
+ */
+export const qualifiedSymbolId = (node: GraphNode): string | null => {
+  const { importPath, language, name, receiverType } = node.properties;
+  if (language !== 'go' || !importPath || !name) return null;
+  if (SCHEME_EXEMPT_LABELS.has(node.label)) return null;
+  return `${importPath}.${symbolIdName(name, node.label === 'Method' ? receiverType : undefined)}`;
+};
+
+/**
+ * Reserve an id for the declaration whose name starts at byte `start` of its
+ * file: `baseId`, or the next free `baseId#n` when another declaration of
+ * the file has it. Null when this declaration already holds an id, i.e. a
+ * second query pattern matched it. `claimed` is per file. Go files only
+ * (see header).
+ */
+export const claimSymbolId = (claimed: Map<string, number>, baseId: string, start: number): string | null => {
+  for (let n = 1; ; n++) {
+    const id = n === 1 ? baseId : `${baseId}#${n}`;
+    const holder = claimed.get(id);
+    if (holder === undefined) {
+      claimed.set(id, start);
+      return id;
+    }
+    if (holder === start) return null;
+  }
+};
+
+const cmp = (a: string, b: string): number => (a < b ? -1 : a > b ? 1 : 0);
+
+/**
+ * Give each member of a collision group its file, and in-file repeats an
+ * ordinal: `id@file`, `id@file#2`. Groups of one keep their id.
+ */
+const disambiguateIds = <T>(
+  groups: Map<string, T[]>,
+  fileOf: (item: T) => string,
+  compare: (a: T, b: T) => number,
+): Map<T, string> => {
+  const ids = new Map<T, string>();
+  for (const [id, items] of groups) {
+    if (items.length === 1) {
+      ids.set(items[0], id);
+      continue;
+    }
+    const perFile = new Map<string, number>();
+    for (const item of [...items].sort(compare)) {
+      const file = fileOf(item);
+      const n = (perFile.get(file) ?? 0) + 1;
+      perFile.set(file, n);
+      ids.set(item, `${id}@${file}${n > 1 ? `#${n}` : ''}`);
+    }
+  }
+  return ids;
+};
+
+/**
+ * Node id -> id in `scheme`, for every node in the graph. Unique for either
+ * scheme; graph node ids pass through unchanged for `path`.
+ */
+export const createSymbolIdMapper = (graph: KnowledgeGraph, scheme: SymbolIdScheme): (nodeId: string) => string => {
+  if (scheme === 'path') return (nodeId) => nodeId;
+
+  const groups = new Map<string, GraphNode[]>();
+  graph.forEachNode(node => {
+    const id = qualifiedSymbolId(node);
+    if (!id) return;
+    const group = groups.get(id);
+    if (group) group.push(node);
+    else groups.set(id, [node]);
+  });
+  const byNode = disambiguateIds(groups, node => node.properties.filePath, (a, b) =>
+    cmp(a.properties.filePath, b.properties.filePath) ||
+    (a.properties.startLine ?? 0) - (b.properties.startLine ?? 0) ||
+    cmp(a.id, b.id));
+  const mapped = new Map<string, string>();
+  for (const [node, id] of byNode) mapped.set(node.id, id);
+  // A qualified id never contains ':', so it can't equal a path id that was kept
+  return (nodeId) => mapped.get(nodeId) ?? nodeId;
+};
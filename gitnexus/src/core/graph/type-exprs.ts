@@ -0,0 +1,550 @@
+/**
+ * Type Expressions
+ *
+ * Structured form of the Go type strings recorded at parse time (field
+ * types, signature parameters and results, declared types), for questions
+ * plain string matching gets wrong: `chan<- Event` and `<-chan Event` differ
+ * by one token, `map[UserID][]Order` is a map keyed by a named type however
+ * it is spaced. The strings stay the graph's source of truth; this parses
+ * them on demand (parseTypeExpr) into:
+ *   named      `T`, `pkg.T`, `List[T]` (package qualifier, resolved import
+ *              path when the file's imports are known, builtin flag)
+ *   pointer    `*T`           slice `[]T`          array `[N]T`
+ *   map        `map[K]V`      chan (`both`, `send` = `chan<-`, `recv` = `<-chan`)
+ *   func       params/results, `variadic` when the last param is `...T`
+ *              (that param is then the slice `[]T`, as in go/types)
+ *   struct     fields with names, type and tag
+ *   interface  kept as written; union (`~int | ~string`, constraints only)
+ * Text that doesn't parse as a type is `unknown` with the text.
+ *
+ * findTypeExprSlots runs a predicate over every typed slot in the graph:
+ *   chan sends:   findTypeExprSlots(graph, e => e.kind === 'chan' && e.dir === 'send', { slots: ['param'] })
+ *   user-keyed maps: findTypeExprSlots(graph, e => e.kind === 'map' && e.key.kind === 'named' && !e.key.builtin, { slots: ['field'] })
+ */
+
+import { KnowledgeGraph, FileImport, GraphNode, NodeLabel } from './types.js';
+import { parseSignature } from './signatures.js';
+import { GO_PREDECLARED_TYPES } from '../ingestion/go-metadata.js';
+
+export type ChanDir = 'both' | 'send' | 'recv';
+
+export interface TypeExprField {
+  /** Declared names; empty for an embedded field */
+  names: string[];
+  type: TypeExpr;
+  /** Struct tag without quotes */
+  tag?: string;
+}
+
+export type TypeExpr =
+  | {
+      kind: 'named';
+      name: string;
+      /** Package qualifier as written (`json` in `json.Decoder`) */
+      package?: string;
+      /** Import path the qualifier binds, when the file's imports were given */
+      importPath?: string;
+      /** Predeclared (`int`, `error`, `any`) */
+      builtin?: boolean;
+      /** Instantiation arguments (`List[int]`) */
+      typeArgs?: TypeExpr[];
+    }
+  | { kind: 'pointer'; elem: TypeExpr }
+  | { kind: 'slice'; elem: TypeExpr }
+  | { kind: 'array'; /** Length as written: `4`, `N`, `...` */ length: string; elem: TypeExpr }
+  | { kind: 'map'; key: TypeExpr; value: TypeExpr }
+  | { kind: 'chan'; dir: ChanDir; elem: TypeExpr }
+  | { kind: 'func'; params: TypeExpr[]; results: TypeExpr[]; variadic: boolean }
+  | { kind: 'struct'; fields: TypeExprField[] }
+  | { kind: 'interface'; text: string }
+  | { kind: 'union'; terms: { tilde: boolean; type: TypeExpr }[] }
+  | { kind: 'unknown'; text: string };
+
+export interface TypeExprOptions {
+  /** Imports of the file the type is written in, to resolve `pkg.T` to an import path */
+  imports?: FileImport[];
+}
+
+// ============================================================================
+// PARSER
+// ============================================================================
+
+interface Token {
+  text: string;
+  start: number;
+  end: number;
+  /** ident (identifiers and numbers), string (tags), or punct */
+  type: 'ident' | 'string' | 'punct';
+}
+
+const KEYWORDS = new Set(['chan', 'func', 'map', 'struct', 'interface']);
+
+const tokenize = (text: string): Token[] | null => {
+  const tokens: Token[] = [];
+  let i = 0;
+  while (i < text.length) {
+    const c = text[i];
+    if (/\s/.test(c)) {
+      i++;
+      continue;
+    }
+    const start = i;
+    if (c === '`' || c === '"') {
+      i++;
+      while (i < text.length && text[i] !== c) i += text[i] === '\\' && c === '"' ? 2 : 1;
+      if (i >= text.length) return null;
+      i++;
+      tokens.push({ text: text.substring(start, i), start, end: i, type: 'string' });
+    } else if (text.startsWith('...', i) || text.startsWith('<-', i)) {
+      i += text[i] === '.' ? 3 : 2;
+      tokens.push({ text: text.substring(start, i), start, end: i, type: 'punct' });
+    } else if (/[\p{L}\p{N}_]/u.test(c)) {
+      while (i < text.length && /[\p{L}\p{N}_]/u.test(text[i])) i++;
+      tokens.push({ text: text.substring(start, i), start, end: i, type: 'ident' });
+    } else {
+      i++;
+      tokens.push({ text: c, start, end: i, type: 'punct' });
+    }
+  }
+  return tokens;
+};
+
+class ParseFail extends Error {}
+
+const unquoteTag = (tag: string): string =>
+  tag.startsWith('`') ? tag.slice(1, -1) : tag.slice(1, -1).replace(/\\(.)/g, '$1');
+
+/** Recursive-descent parser over one type string's tokens */
+const createParser = (text: string, tokens: Token[], options: TypeExprOptions) => {
+  let pos = 0;
+  const peek = (offset = 0): Token | undefined => tokens[pos + offset];
+  const is = (t: string, offset = 0): boolean => peek(offset)?.text === t;
+  const expect = (t: string): Token => {
+    const token = tokens[pos];
+    if (token?.text !== t) throw new ParseFail();
+    pos++;
+    return token;
+  };
+  const ident = (): string => {
+    const token = tokens[pos];
+    if (token?.type !== 'ident' || KEYWORDS.has(token.text)) throw new ParseFail();
+    pos++;
+    return token.text;
+  };
+
+  /** Can a type start at the current token? */
+  const startsType = (): boolean => {
+    const token = peek();
+    if (!token) return false;
+    if (token.type === 'ident') return true;
+    return ['*', '[', '(', '<-'].includes(token.text);
+  };
+
+  const named = (): TypeExpr => {
+    let name = ident();
+    let pkg: string | undefined;
+    if (is('.') && peek(1)?.type === 'ident') {
+      pos++;
+      pkg = name;
+      name = ident();
+    }
+    let typeArgs: TypeExpr[] | undefined;
+    if (is('[') && !is(']', 1)) {
+      const saved = pos;
+      try {
+        pos++;
+        typeArgs = [parseType()];
+        while (is(',')) {
+          pos++;
+          if (is(']')) break;
+          typeArgs.push(parseType());
+        }
+        expect(']');
+      } catch (err) {
+        if (!(err instanceof ParseFail)) throw err;
+        pos = saved;
+        typeArgs = undefined;
+      }
+    }
+    const importPath = pkg
+      ? options.imports?.find(imp => (imp.kind === 'default' || imp.kind === 'alias') && imp.localName === pkg)?.path
+      : undefined;
+    return {
+      kind: 'named',
+      name,
+      ...(pkg ? { package: pkg } : {}),
+      ...(importPath ? { importPath } : {}),
+      ...(!pkg && GO_PREDECLARED_TYPES.has(name) ? { builtin: true } : {}),
+      ...(typeArgs ? { typeArgs } : {}),
+    };
+  };
+
+  /** Text between the brackets at pos (`[` ... `]`), nesting included; pos ends after `]` */
+  const bracketed = (open: string, close: string): string => {
+    const first = expect(open);
+    let depth = 1;
+    while (pos < tokens.length) {
+      const token = tokens[pos++];
+      if (token.text === open) depth++;
+      else if (token.text === close && --depth === 0) return text.substring(first.end, token.start).trim();
+    }
+    throw new ParseFail();
+  };
+
+  /**
+   * A parenthesized parameter list, one type per parameter (`a, b int` is
+   * two). Go lists either name every parameter or none, so both readings are
+   * tried: with names first, then as bare types.
+   */
+  const paramList = (): { types: TypeExpr[]; variadic: boolean } => {
+    expect('(');
+    const attempt = (withNames: boolean) => {
+      const types: TypeExpr[] = [];
+      let variadic = false;
+      let pendingNames = 0;
+      while (!is(')')) {
+        if (variadic) throw new ParseFail();
+        if (withNames) {
+          ident();
+          pendingNames++;
+          if (is(',') || is(')')) {
+            if (is(',')) pos++;
+            continue;
+          }
+        }
+        if (is('...')) {
+          pos++;
+          variadic = true;
+        }
+        const type = parseType();
+        const entry: TypeExpr = variadic ? { kind: 'slice', elem: type } : type;
+        for (let i = 0; i < Math.max(1, pendingNames); i++) types.push(entry);
+        if (variadic && pendingNames > 1) throw new ParseFail();
+        pendingNames = 0;
+        if (is(',')) pos++;
+        else if (!is(')')) throw new ParseFail();
+      }
+      if (pendingNames > 0) throw new ParseFail();
+      pos++;
+      return { types, variadic };
+    };
+    const start = pos;
+    try {
+      return attempt(true);
+    } catch (err) {
+      if (!(err instanceof ParseFail)) throw err;
+      pos = start;
+      return attempt(false);
+    }
+  };
+
+  const funcTail = (): TypeExpr => {
+    const { types: params, variadic } = paramList();
+    let results: TypeExpr[] = [];
+    if (is('(')) {
+      const parsed = paramList();
+      if (parsed.variadic) throw new ParseFail();
+      results = parsed.types;
+    } else if (startsType()) {
+      results = [parseType()];
+    }
+    return { kind: 'func', params, results, variadic };
+  };
+
+  const structBody = (): TypeExpr => {
+    expect('{');
+    const fields: TypeExprField[] = [];
+    while (!is('}')) {
+      if (is(';')) {
+        pos++;
+        continue;
+      }
+      let names: string[] = [];
+      const embedded = is('*') || (peek()?.type === 'ident' && (is('.', 1) || is(';', 1) || is('}', 1) || peek(1)?.type === 'string'));
+      if (!embedded) {
+        names = [ident()];
+        while (is(',')) {
+          pos++;
+          names.push(ident());
+        }
+      }
+      const type = parseType();
+      const tag = peek()?.type === 'string' ? unquoteTag(tokens[pos++].text) : undefined;
+      fields.push({ names, type, ...(tag !== undefined ? { tag } : {}) });
+    }
+    pos++;
+    return { kind: 'struct', fields };
+  };
+
+  const parseType = (): TypeExpr => {
+    const token = peek();
+    if (!token) throw new ParseFail();
+    switch (token.text) {
+      case '*':
+        pos++;
+        return { kind: 'pointer', elem: parseType() };
+      case '(': {
+        pos++;
+        const inner = parseType();
+        expect(')');
+        return inner;
+      }
+      case '[': {
+        if (is(']', 1)) {
+          pos += 2;
+          return { kind: 'slice', elem: parseType() };
+        }
+        const length = bracketed('[', ']');
+        return { kind: 'array', length, elem: parseType() };
+      }
+      case 'map': {
+        pos++;
+        expect('[');
+        const key = parseType();
+        expect(']');
+        return { kind: 'map', key, value: parseType() };
+      }
+      case 'chan': {
+        pos++;
+        if (is('<-')) {
+          pos++;
+          return { kind: 'chan', dir: 'send', elem: parseType() };
+        }
+        return { kind: 'chan', dir: 'both', elem: parseType() };
+      }
+      case '<-':
+        pos++;
+        expect('chan');
+        return { kind: 'chan', dir: 'recv', elem: parseType() };
+      case 'func':
+        pos++;
+        return funcTail();
+      case 'struct':
+        pos++;
+        return structBody();
+      case 'interface': {
+        pos++;
+        const body = bracketed('{', '}');
+        return body ? { kind: 'interface', text: `interface { ${body} }` } : { kind: 'named', name: 'any', builtin: true };
+      }
+      default:
+        return named();
+    }
+  };
+
+  const parseUnion = (): TypeExpr => {
+    const terms: { tilde: boolean; type: TypeExpr }[] = [];
+    for (;;) {
+      const tilde = is('~');
+      if (tilde) pos++;
+      terms.push({ tilde, type: parseType() });
+      if (!is('|')) break;
+      pos++;
+    }
+    return terms.length === 1 && !terms[0].tilde ? terms[0].type : { kind: 'union', terms };
+  };
+
+  return {
+    parseType,
+    parseUnion,
+    funcTail,
+    done: () => pos === tokens.length,
+  };
+};
+
+const parseWith = (text: string, options: TypeExprOptions, run: (parser: ReturnType<typeof createParser>) => TypeExpr): TypeExpr => {
+  const tokens = tokenize(text);
+  if (!tokens || tokens.length === 0) return { kind: 'unknown', text };
+  try {
+    const parser = createParser(text, tokens, options);
+    const expr = run(parser);
+    return parser.done() ? expr : { kind: 'unknown', text };
+  } catch (err) {
+    if (err instanceof ParseFail) return { kind: 'unknown', text };
+    throw err;
+  }
+};
+
+/** Parse a Go type (`map[string][]*User`, `chan<- Event`, `func(context.Context) error`) */
+export const parseTypeExpr = (text: string, options: TypeExprOptions = {}): TypeExpr =>
+  parseWith(text.trim(), options, parser => parser.parseType());
+
+/** Parse a type parameter constraint, which may be a union (`~int | ~string`) */
+export const parseConstraintExpr = (text: string, options: TypeExprOptions = {}): TypeExpr =>
+  parseWith(text.trim(), options, parser => parser.parseUnion());
+
+/**
+ * Params and results of a recorded signature (`Name([]byte, ...Option) (int, error)`),
+ * as a func type; null when it isn't one.
+ */
+export const parseSignatureTypeExpr = (signature: string, options: TypeExprOptions = {}): Extract<TypeExpr, { kind: 'func' }> | null => {
+  const open = signature.indexOf('(');
+  if (open < 0) return null;
+  const expr = parseWith(signature.substring(open), options, parser => parser.funcTail());
+  return expr.kind === 'func' ? expr : null;
+};
+
+// ============================================================================
+// FORMATTING AND TRAVERSAL
+// ============================================================================
+
+/** Go syntax for a type expression, spaced like normalized type strings */
+export const formatTypeExpr = (expr: TypeExpr): string => {
+  switch (expr.kind) {
+    case 'named': {
+      const name = expr.package ? `${expr.package}.${expr.name}` : expr.name;
+      return expr.typeArgs ? `${name}[${expr.typeArgs.map(formatTypeExpr).join(', ')}]` : name;
+    }
+    case 'pointer': return `*${formatTypeExpr(expr.elem)}`;
+    case 'slice': return `[]${formatTypeExpr(expr.elem)}`;
+    case 'array': return `[${expr.length}]${formatTypeExpr(expr.elem)}`;
+    case 'map': return `map[${formatTypeExpr(expr.key)}]${formatTypeExpr(expr.value)}`;
+    case 'chan': {
+      if (expr.dir === 'recv') return `<-chan ${formatTypeExpr(expr.elem)}`;
+      if (expr.dir === 'send') return `chan<- ${formatTypeExpr(expr.elem)}`;
+      // `chan <-chan T` would read as `chan<- chan T`
+      const elem = expr.elem.kind === 'chan' && expr.elem.dir === 'recv' ? `(${formatTypeExpr(expr.elem)})` : formatTypeExpr(expr.elem);
+      return `chan ${elem}`;
+    }
+    case 'func': {
+      const params = expr.params.map((p, i) =>
+        expr.variadic && i === expr.params.length - 1 && p.kind === 'slice' ? `...${formatTypeExpr(p.elem)}` : formatTypeExpr(p));
+      const results = expr.results.map(formatTypeExpr);
+      const tail = results.length === 0 ? '' : results.length === 1 ? ` ${results[0]}` : ` (${results.join(', ')})`;
+      return `func(${params.join(', ')})${tail}`;
+    }
+    case 'struct': {
+      if (expr.fields.length === 0) return 'struct{}';
+      const fields = expr.fields.map(f => {
+        const decl = f.names.length > 0 ? `${f.names.join(', ')} ${formatTypeExpr(f.type)}` : formatTypeExpr(f.type);
+        return f.tag !== undefined ? `${decl} \`${f.tag}\`` : decl;
+      });
+      return `struct { ${fields.join('; ')} }`;
+    }
+    case 'interface': return expr.text;
+    case 'union': return expr.terms.map(t => `${t.tilde ? '~' : ''}${formatTypeExpr(t.type)}`).join(' | ');
+    case 'unknown': return expr.text;
+  }
+};
+
+/** Immediate component types: element, key and value, params and results, fields, type args */
+export const typeExprChildren = (expr: TypeExpr): TypeExpr[] => {
+  switch (expr.kind) {
+    case 'named': return expr.typeArgs ?? [];
+    case 'pointer': case 'slice': case 'array': case 'chan': return [expr.elem];
+    case 'map': return [expr.key, expr.value];
+    case 'func': return [...expr.params, ...expr.results];
+    case 'struct': return expr.fields.map(f => f.type);
+    case 'union': return expr.terms.map(t => t.type);
+    default: return [];
+  }
+};
+
+/** Does `expr` or any type nested in it satisfy `predicate`? */
+export const someTypeExpr = (expr: TypeExpr, predicate: (e: TypeExpr) => boolean): boolean =>
+  predicate(expr) || typeExprChildren(expr).some(child => someTypeExpr(child, predicate));
+
+/** Named types mentioned anywhere in `expr`, outermost first */
+export const namedTypesIn = (expr: TypeExpr): Extract<TypeExpr, { kind: 'named' }>[] => {
+  const found: Extract<TypeExpr, { kind: 'named' }>[] = [];
+  const visit = (e: TypeExpr) => {
+    if (e.kind === 'named') found.push(e);
+    typeExprChildren(e).forEach(visit);
+  };
+  visit(expr);
+  return found;
+};
+
+// ============================================================================
+// GRAPH SLOTS
+// ============================================================================
+
+export type TypeExprSlotKind = 'param' | 'result' | 'field' | 'declared';
+
+export interface TypeExprSlot {
+  /** Func/method (params, results), struct (fields), or const/var (declared type) */
+  nodeId: string;
+  name: string;
+  label: NodeLabel;
+  filePath: string;
+  slot: TypeExprSlotKind;
+  /** Position among the node's params, results or fields, from 0 */
+  index: number;
+  /** Field name (`field` slots) */
+  fieldName?: string;
+  /** The type as recorded (`...Option` for a variadic param) */
+  text: string;
+  expr: TypeExpr;
+}
+
+export interface TypeExprSlotOptions {
+  /** Slot kinds to visit (default all) */
+  slots?: TypeExprSlotKind[];
+}
+
+/** Typed slots of one node, qualifiers resolved against its file's imports */
+export const getTypeExprSlots = (node: GraphNode, imports: FileImport[] = []): TypeExprSlot[] => {
+  const { name, filePath, signature, fields, declaredType } = node.properties;
+  const base = { nodeId: node.id, name, label: node.label, filePath };
+  const options = { imports };
+  const slots: TypeExprSlot[] = [];
+
+  if (signature && (node.label === 'Function' || node.label === 'Method')) {
+    const texts = parseSignature(signature);
+    const func = parseSignatureTypeExpr(signature, options);
+    if (texts && func) {
+      // Counts differ only if the signature didn't parse cleanly; fall back to per-string parsing then
+      const params = func.params.length === texts.params.length ? func.params : texts.params.map(t => parseTypeExpr(t, options));
+      const results = func.results.length === texts.results.length ? func.results : texts.results.map(t => parseTypeExpr(t, options));
+      texts.params.forEach((text, index) => slots.push({ ...base, slot: 'param', index, text, expr: params[index] }));
+      texts.results.forEach((text, index) => slots.push({ ...base, slot: 'result', index, text, expr: results[index] }));
+    }
+  }
+  fields?.forEach((field, index) => {
+    slots.push({ ...base, slot: 'field', index, fieldName: field.name, text: field.typeString, expr: parseTypeExpr(field.typeString, options) });
+  });
+  if (declaredType && (node.label === 'Const' || node.label === 'Static')) {
+    slots.push({ ...base, slot: 'declared', index: 0, text: declaredType, expr: parseTypeExpr(declaredType, options) });
+  }
+  return slots;
+};
+
+/** Declared imports per file path, from File nodes */
+export const getFileImports = (graph: KnowledgeGraph): Map<string, FileImport[]> => {
+  const imports = new Map<string, FileImport[]>();
+  graph.forEachNode(node => {
+    if (node.label === 'File' && node.properties.imports) imports.set(node.properties.filePath, node.properties.imports);
+  });
+  return imports;
+};
+
+/**
+ * Typed slots across the graph whose type satisfies `predicate`, sorted by
+ * file, line and position. The predicate sees the slot's own type; use
+ * someTypeExpr inside it to match nested types too.
+ */
+export const findTypeExprSlots = (
+  graph: KnowledgeGraph,
+  predicate: (expr: TypeExpr, slot: TypeExprSlot) => boolean,
+  options: TypeExprSlotOptions = {},
+): TypeExprSlot[] => {
+  const kinds = options.slots ? new Set(options.slots) : null;
+  const imports = getFileImports(graph);
+  const lines = new Map<string, number>();
+  const found: TypeExprSlot[] = [];
+  graph.forEachNode(node => {
+    if (!node.properties.filePath?.endsWith('.go')) return;
+    if (!node.properties.signature && !node.properties.fields && !node.properties.declaredType) return;
+    for (const slot of getTypeExprSlots(node, imports.get(node.properties.filePath))) {
+      if (kinds && !kinds.has(slot.slot)) continue;
+      if (!predicate(slot.expr, slot)) continue;
+      found.push(slot);
+      lines.set(slot.nodeId, node.properties.startLine ?? 0);
+    }
+  });
+  const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+  const SLOT_ORDER: Record<TypeExprSlotKind, number> = { param: 0, result: 1, field: 2, declared: 3 };
+  return found.sort((a, b) =>
+    cmp(a.filePath, b.filePath) || lines.get(a.nodeId)! - lines.get(b.nodeId)! || cmp(a.nodeId, b.nodeId) ||
+    SLOT_ORDER[a.slot] - SLOT_ORDER[b.slot] || a.index - b.index);
+};
@@ -0,0 +1,57 @@
+/**
+ * Type Usages
+ *
+ * Every place a Go type is referenced, read off the USES edges built by the
+ * type-usage processor: which declaration refers to it, where, how (field,
+ * parameter, conversion, ...) and through what wrapping (`[]*T` is T wrapped
+ * in slice then pointer). The basis for "who depends on this struct" and for
+ * sizing the impact of changing a type.
+ *
+ * References inside a function body are attributed to the function; those at
+ * package level to the declaration they appear in, or to the File node.
+ */
+
+import { KnowledgeGraph, NodeLabel, TypeUsageKind, TypeWrapping } from './types.js';
+
+export interface TypeUsage {
+  /** Declaration the reference appears in */
+  sourceId: string;
+  sourceName: string;
+  sourceLabel: NodeLabel;
+  filePath: string;
+  line: number;
+  column: number;
+  usage: TypeUsageKind;
+  wrapping: TypeWrapping[];
+}
+
+/** Usage sites of the type node `typeId`, sorted by file, line, column */
+export const getTypeUsages = (graph: KnowledgeGraph, typeId: string): TypeUsage[] => {
+  const usages: TypeUsage[] = [];
+  for (const rel of graph.iterRelationships()) {
+    if (rel.type !== 'USES' || rel.targetId !== typeId || !rel.usageSites) continue;
+    const source = graph.getNode(rel.sourceId);
+    if (!source) continue;
+    for (const site of rel.usageSites) {
+      usages.push({
+        sourceId: source.id,
+        sourceName: source.properties.name,
+        sourceLabel: source.label,
+        filePath: source.properties.filePath,
+        line: site.line,
+        column: site.column,
+        usage: site.usage,
+        wrapping: site.wrapping,
+      });
+    }
+  }
+  return usages.sort((a, b) =>
+    (a.filePath < b.filePath ? -1 : a.filePath > b.filePath ? 1 : 0) || a.line - b.line || a.column - b.column);
+};
+
+/** Usage counts of `typeId` by kind, e.g. { parameter: 4, field: 1 } */
+export const countTypeUsagesByKind = (graph: KnowledgeGraph, typeId: string): Partial<Record<TypeUsageKind, number>> => {
+  const counts: Partial<Record<TypeUsageKind, number>> = {};
+  for (const usage of getTypeUsages(graph, typeId)) counts[usage.usage] = (counts[usage.usage] ?? 0) + 1;
+  return counts;
+};
@@ -35,11 +35,137 @@ export type NodeLabel =
   | 'Template';
 
 
+/** A field of a struct-like type */
+export interface StructField {
+  name: string,
+  /** Declared type as written, normalized (`*Base`, `map[string]int`) */
+  typeString: string,
+  /** Raw struct tag without quotes (`json:"name,omitempty"`) */
+  tag?: string,
+  exported: boolean,
+  /** Anonymous field — name is the type's base name */
+  embedded: boolean,
+  /** Leading and/or trailing comment text, markers stripped */
+  doc?: string,
+  /** 0-based row of the field name */
+  line?: number,
+}
+
+/**
+ * An import declared by a file.
+ * - 'default': bound under the package's own name (`import "fmt"` -> fmt)
+ * - 'alias': bound under an explicit name (`import f "fmt"`)
+ * - 'dot': members usable unqualified (`import . "fmt"`)
+ * - 'blank': imported for side effects only (`import _ "net/http/pprof"`)
+ */
+export interface FileImport {
+  path: string,
+  /** Name the import binds in the file: package name, alias, '.', or '_' */
+  localName: string,
+  kind: 'default' | 'alias' | 'dot' | 'blank',
+  /** 0-based row of the import spec */
+  line: number,
+}
+
+/** A Go type parameter: `[K comparable, V any]` -> {K, comparable}, {V, any} */
+export interface TypeParam {
+  name: string,
+  /** Constraint expression, normalized (`any`, `~int | ~string`, `fmt.Stringer`) */
+  constraint: string,
+}
+
+/** Go test function kinds, from the `Test`/`Benchmark`/`Fuzz`/`Example` name prefix */
+export type GoTestKind = 'test' | 'benchmark' | 'fuzz' | 'example';
+
+/**
+ * How a named type is used at a reference site:
+ *   field / embedded      struct field type, or embedded in a struct or interface
+ *   parameter / result / receiver
+ *   variable / constant   declared type in a var/const spec
+ *   composite-literal     `T{...}`
+ *   conversion            `[]T(x)`, `(*T)(x)`
+ *   allocation            `new(T)`, `make([]T, n)`
+ *   type-assertion / type-switch
+ *   underlying / alias    right-hand side of `type X T` / `type X = T`
+ *   constraint            type parameter constraint
+ */
+export type TypeUsageKind =
+  | 'field' | 'embedded' | 'parameter' | 'result' | 'receiver' | 'variable' | 'constant'
+  | 'composite-literal' | 'conversion' | 'allocation' | 'type-assertion' | 'type-switch'
+  | 'underlying' | 'alias' | 'constraint' | 'other';
+
+/** One layer around a type reference, outermost first: `[]*T` -> ['slice', 'pointer'] */
+export type TypeWrapping =
+  | 'pointer' | 'slice' | 'array' | 'map-key' | 'map-value' | 'chan' | 'variadic' | 'func' | 'type-argument';
+
+/** A `replace` directive from go.mod */
+export interface GoModuleReplace {
+  oldPath: string,
+  /** Only this version is replaced; every version when omitted */
+  oldVersion?: string,
+  newPath: string,
+  newVersion?: string,
+  /** Repo-relative directory when newPath is a local path inside the repo */
+  localDir?: string,
+}
+
+/** A Go module: one go.mod and the directory tree it owns */
+export interface GoModule {
+  /** Module path from the `module` line (`github.com/acme/api`) */
+  path: string,
+  /** Repo-relative directory holding the go.mod; '' for the repo root */
+  dir: string,
+  goVersion?: string,
+  replaces: GoModuleReplace[],
+}
+
+/**
+ * How a Go call's results are thrown away:
+ *   statement   `f()` as a statement; `defer f()` and `go f()` likewise
+ *   blank       some results assigned to `_` (`_ = f()`, `v, _ := f()`)
+ */
+export type DiscardKind = 'statement' | 'defer' | 'go' | 'blank';
+
+/** A call site whose results (some or all) are discarded, recorded on the calling func/method */
+export interface DiscardedCall {
+  /** Called name (`Close` in `f.Close()`) */
+  callee: string,
+  /** Call-site context, as for call resolution (see GoCallContext) */
+  qualifier?: string,
+  qualifierPackage?: string,
+  qualifierType?: string,
+  /** 0-based row and byte column of the call expression (row as in callLines) */
+  line: number,
+  column: number,
+  discard: DiscardKind,
+  /** blank only: values on the left-hand side, and the positions that are `_` */
+  resultCount?: number,
+  blankResults?: number[],
+}
+
+/** A reference to a type, carried on USES edges */
+export interface TypeUsageSite {
+  /** 0-based row and byte column of the type name */
+  line: number,
+  column: number,
+  usage: TypeUsageKind,
+  wrapping: TypeWrapping[],
+}
+
 export type NodeProperties = {
   name: string,
   filePath: string,
   startLine?: number,
   endLine?: number,
+  /** Last row of the whole definition, body included (startLine/endLine span only the name) */
+  definitionEndLine?: number,
+  /** 0-based byte column of the name on startLine */
+  startColumn?: number,
+  /** UTF-8 byte offsets of the whole definition, end exclusive */
+  startByte?: number,
+  endByte?: number,
+  /** Hash of the definition's syntax tree minus comments, formatting and the name (see getBodyHash); equal hashes mean the implementation didn't change */
+  bodyHash?: string,
   language?: string,
   isExported?: boolean,
   // Optional AST-derived framework hint (e.g. @Controller, @GetMapping)
@@ -51,6 +177,8 @@ export type NodeProperties = {
   symbolCount?: number,
   keywords?: string[],
   description?: string,
+  /** Leading doc comment (or docstring), comment markers stripped */
+  docComment?: string,
   enrichedBy?: 'heuristic' | 'llm',
   // Process-specific properties
   processType?: 'intra_community' | 'cross_community',
@@ -61,6 +189,45 @@ export type NodeProperties = {
   // Entry point scoring (computed by process detection)
   entryPointScore?: number,
   entryPointReason?: string,
+  // Go interface details (parse-time declared methods + flattened method set)
+  methodSignatures?: string[],
+  embeddedTypes?: string[],
+  methodSet?: string[],
+  // Go function/method details: signature (`Push(T)`, `Map([]T, func(T) U) []U`) and receiver (base type, pointer-ness, variable)
+  signature?: string,
+  receiverType?: string,
+  receiverPointer?: boolean,
+  receiverName?: string,
+  fields?: StructField[],
+  // Go generic functions and types
+  typeParams?: TypeParam[],
+  // Go named types (TypeAlias label): `type A = B` (alias) vs `type A B` (defined), and what follows the name
+  isAlias?: boolean,
+  underlyingType?: string,
+  // Go package-level const/var: declared type, initializer text, resolved iota value
+  declaredType?: string,
+  value?: string,
+  constValue?: number,
+  // Go funcs/methods: cyclomatic complexity, gocyclo rules (see computeGoComplexity)
+  complexity?: number,
+  // Go funcs/methods: calls whose results are discarded (see extractGoDiscardedCalls)
+  discardedCalls?: DiscardedCall[],
+  // Go test functions (_test.go files only)
+  isTest?: boolean,
+  testKind?: GoTestKind,
+  // Go files and their symbols: owning module and the package's import path
+  modulePath?: string,
+  importPath?: string,
+  // go.mod File nodes: the parsed module
+  goMod?: GoModule,
+  // Go files and their symbols: build constraint from the name and header (`linux && !cgo`, see build-constraints)
+  buildConstraint?: string,
+  // Key/values written by registered symbol annotators (see annotators.ts)
+  meta?: Record<string, string>,
+  // File nodes: sha256 of the content that was parsed (see hashContent)
+  contentHash?: string,
+  // File nodes: declared imports (Go)
+  imports?: FileImport[],
 }
 
 export type RelationshipType = 
@@ -94,6 +261,10 @@ export interface GraphRelationship {
   reason: string,
   /** Step number for STEP_IN_PROCESS relationships (1-indexed) */
   step?: number,
+  /** Call-site rows for CALLS relationships (0-based, like startLine) */
+  callLines?: number[],
+  /** Reference sites for USES relationships (source declaration -> type) */
+  usageSites?: TypeUsageSite[],
 }
 
 export interface KnowledgeGraph {
@@ -110,10 +281,14 @@ export interface KnowledgeGraph {
   forEachRelationship: (fn: (rel: GraphRelationship) => void) => void,
   /** Lookup a single node by id — O(1) */
   getNode: (id: string) => GraphNode | undefined,
+  /** Lookup a single relationship by id — O(1) */
+  getRelationship: (id: string) => GraphRelationship | undefined,
   nodeCount: number,
   relationshipCount: number,
   addNode: (node: GraphNode) => void,
   addRelationship: (relationship: GraphRelationship) => void,
+  removeRelationship: (relationshipId: string) => boolean,
   removeNode: (nodeId: string) => boolean,
+  removeNodes: (nodeIds: Iterable<string>) => number,
   removeNodesByFile: (filePath: string) => number,
 }
@@ -0,0 +1,235 @@
+/**
+ * Unused Unexported Symbols
+ *
+ * Pre-PR cleanup aid for Go: unexported functions, methods, types, constants
+ * and variables that nothing else in their package refers to. An unexported
+ * identifier can only be used from its own package (directory), so only that
+ * package's files are consulted.
+ *
+ * A symbol counts as used when either
+ *   - another symbol in the package has a CALLS/USES edge to it, or
+ *   - its name appears as an identifier token in the package's files outside
+ *     its own definition (comments and string literals don't count).
+ * The token scan is what catches non-call references — type names in
+ * signatures, constants in expressions, functions passed as values. Because
+ * it is lexical, a field or method of the same name elsewhere in the package
+ * also keeps a symbol alive; the detector errs toward not flagging.
+ *
+ * Never flagged:
+ *   - `init` and `main` functions, and `_`
+ *   - symbols declared in _test.go files (test helpers)
+ *   - names that appear in a struct tag in the package
+ *
+ * Limitations — results are candidates to review, not proof of dead code:
+ *   - reflection (`reflect.Value.MethodByName`), `//go:linkname`, cgo
+ *     `//export` and templates that name methods are invisible here
+ *   - unexported methods can satisfy an interface through a value that is
+ *     only ever used as that interface; if the interface lives in the same
+ *     package its method list mentions the name, so such methods are kept,
+ *     but that is a lexical accident rather than a method-set check
+ *   - files excluded from the index (ignored paths, size limit, build-tagged
+ *     variants not parsed) are not scanned
+ */
+
+import { KnowledgeGraph, GraphNode, NodeLabel } from './types.js';
+import { getSymbolLineRange } from './symbol-authors.js';
+import { goPackageDir, isGoTestFile } from '../../lib/utils.js';
+
+export interface UnusedSymbol {
+  id: string;
+  name: string;
+  label: NodeLabel;
+  filePath: string;
+  startLine?: number;
+}
+
+/** Reads a repo-relative file; null when it can't be read */
+export type UnusedSymbolFileReader = (relativePath: string) => Promise<string | null>;
+
+const CANDIDATE_LABELS = new Set<NodeLabel>([
+  'Function', 'Method', 'Struct', 'Interface', 'Type', 'TypeAlias', 'Const', 'Static',
+]);
+
+const REFERENCE_EDGE_TYPES = new Set(['CALLS', 'USES']);
+
+const isUnexportedGoName = (name: string): boolean => {
+  const first = name.charAt(0);
+  // Go: exported iff the first character is an upper-case letter
+  return first !== '' && first === first.toLowerCase() && name !== '_';
+};
+
+const isIdentStart = (c: string): boolean =>
+  (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c === '_' || c > '\x7f';
+
+const isIdentPart = (c: string): boolean => isIdentStart(c) || (c >= '0' && c <= '9');
+
+/**
+ * Identifier tokens of Go source with their 0-based rows, skipping comments,
+ * string, raw string, and rune literals. Keywords are included; callers only
+ * look up symbol names, which can't be keywords.
+ */
+export const scanGoIdentifiers = (source: string): Map<string, number[]> => {
+  const tokens = new Map<string, number[]>();
+  let row = 0;
+  let i = 0;
+  const n = source.length;
+
+  while (i < n) {
+    const c = source[i];
+    if (c === '\n') {
+      row++;
+      i++;
+    } else if (c === '/' && source[i + 1] === '/') {
+      while (i < n && source[i] !== '\n') i++;
+    } else if (c === '/' && source[i + 1] === '*') {
+      i += 2;
+      while (i < n && !(source[i] === '*' && source[i + 1] === '/')) {
+        if (source[i] === '\n') row++;
+        i++;
+      }
+      i += 2;
+    } else if (c === '"' || c === '\'') {
+      i++;
+      while (i < n && source[i] !== c && source[i] !== '\n') {
+        i += source[i] === '\\' ? 2 : 1;
+      }
+      i++;
+    } else if (c === '`') {
+      i++;
+      while (i < n && source[i] !== '`') {
+        if (source[i] === '\n') row++;
+        i++;
+      }
+      i++;
+    } else if (isIdentStart(c)) {
+      const start = i;
+      while (i < n && isIdentPart(source[i])) i++;
+      const word = source.substring(start, i);
+      let rows = tokens.get(word);
+      if (!rows) {
+        rows = [];
+        tokens.set(word, rows);
+      }
+      rows.push(row);
+    } else if (c >= '0' && c <= '9') {
+      // Numeric literal, so `0x1f` doesn't yield an `x1f` token
+      while (i < n && (isIdentPart(source[i]) || source[i] === '.')) i++;
+    } else {
+      i++;
+    }
+  }
+  return tokens;
+};
+
+/** Words in a struct tag (`json:"name,omitempty" db:"helper"` -> json, name, omitempty, db, helper) */
+const tagWords = (tag: string): string[] => tag.split(/[^A-Za-z0-9_]+/).filter(Boolean);
+
+/**
+ * Unexported Go symbols with no reference inside their package, sorted by
+ * file then line. File contents are read through `readFile` (e.g. a
+ * FileSource's readFile), one package at a time.
+ */
+export const findUnusedPrivateSymbols = async (
+  graph: KnowledgeGraph,
+  readFile: UnusedSymbolFileReader,
+): Promise<UnusedSymbol[]> => {
+  const goFilesByDir = new Map<string, string[]>();
+  const symbolsByDir = new Map<string, GraphNode[]>();
+  const tagWordsByDir = new Map<string, Set<string>>();
+
+  graph.forEachNode(node => {
+    const filePath = node.properties.filePath;
+    if (!filePath?.endsWith('.go')) return;
+    const dir = goPackageDir(filePath);
+    if (node.label === 'File') {
+      const files = goFilesByDir.get(dir) ?? [];
+      files.push(filePath);
+      goFilesByDir.set(dir, files);
+      return;
+    }
+    for (const field of (node.properties.fields ?? [])) {
+      if (!field.tag) continue;
+      const words = tagWordsByDir.get(dir) ?? new Set<string>();
+      tagWords(field.tag).forEach(w => words.add(w));
+      tagWordsByDir.set(dir, words);
+    }
+    if (CANDIDATE_LABELS.has(node.label)) {
+      const symbols = symbolsByDir.get(dir) ?? [];
+      symbols.push(node);
+      symbolsByDir.set(dir, symbols);
+    }
+  });
+
+  const referenced = new Set<string>();
+  graph.forEachRelationship(rel => {
+    if (!REFERENCE_EDGE_TYPES.has(rel.type) || rel.sourceId === rel.targetId) return;
+    const source = graph.getNode(rel.sourceId);
+    const target = graph.getNode(rel.targetId);
+    if (source && target && goPackageDir(source.properties.filePath) === goPackageDir(target.properties.filePath)) {
+      referenced.add(rel.targetId);
+    }
+  });
+
+  const unused: UnusedSymbol[] = [];
+
+  for (const [dir, symbols] of symbolsByDir) {
+    const candidates = symbols.filter(node => {
+      const { name, filePath } = node.properties;
+      if (!isUnexportedGoName(name) || isGoTestFile(filePath) || referenced.has(node.id)) return false;
+      if ((name === 'init' || name === 'main') && node.label === 'Function') return false;
+      return !tagWordsByDir.get(dir)?.has(name);
+    });
+    if (candidates.length === 0) continue;
+
+    // Rows where each name is declared, per file: not references to one another
+    const declarationRows = new Map<string, Set<string>>();
+    for (const node of symbols) {
+      if (node.properties.startLine === undefined) continue;
+      const key = `${node.properties.filePath}:${node.properties.name}`;
+      const rows = declarationRows.get(key) ?? new Set<string>();
+      rows.add(String(node.properties.startLine));
+      declarationRows.set(key, rows);
+    }
+
+    const tokensByFile = new Map<string, Map<string, number[]>>();
+    for (const filePath of (goFilesByDir.get(dir) ?? [])) {
+      const content = await readFile(filePath);
+      if (content !== null) tokensByFile.set(filePath, scanGoIdentifiers(content));
+    }
+
+    for (const node of candidates) {
+      const { name, filePath } = node.properties;
+      const ownRange = getSymbolLineRange(node);
+      // A method's receiver names its type; that alone doesn't use the type
+      const receiverRows = new Set<string>();
+      for (const other of symbols) {
+        if (other.label === 'Method' && other.properties.receiverType === name && other.properties.startLine !== undefined) {
+          receiverRows.add(`${other.properties.filePath}:${other.properties.startLine}`);
+        }
+      }
+
+      let used = false;
+      for (const [tokenFile, tokens] of tokensByFile) {
+        const declared = declarationRows.get(`${tokenFile}:${name}`);
+        used = (tokens.get(name) ?? []).some(row => {
+          if (tokenFile === filePath && ownRange && row >= ownRange[0] && row <= ownRange[1]) return false;
+          if (declared?.has(String(row))) return false;
+          return !receiverRows.has(`${tokenFile}:${row}`);
+        });
+        if (used) break;
+      }
+      if (used) continue;
+
+      unused.push({
+        id: node.id,
+        name,
+        label: node.label,
+        filePath,
+        ...(node.properties.startLine !== undefined ? { startLine: node.properties.startLine } : {}),
+      });
+    }
+  }
+
+  return unused.sort((a, b) =>
+    a.filePath.localeCompare(b.filePath) || (a.startLine ?? 0) - (b.startLine ?? 0) || a.name.localeCompare(b.name));
+};
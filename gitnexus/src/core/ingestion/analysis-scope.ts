@@ -0,0 +1,86 @@
+/**
+ * Analysis Scope
+ *
+ * Limits a run to one area of the repo, for monorepos where only part of
+ * the tree matters: a subtree (`root`) and/or Go package patterns
+ * (`packages`). A file is in scope when its directory passes both. Files
+ * outside aren't parsed and get no nodes, except go.mod files, which are
+ * kept wherever they are so import paths still resolve against the real
+ * modules. Package-qualified calls from the scope into a repo package
+ * outside it become ExternalCalls with reason 'out-of-scope' and the
+ * package's import path (see call-processor), apart from calls into
+ * third-party packages.
+ *
+ * Package patterns follow `go list`:
+ *   example.com/app/internal/auth       that package only
+ *   example.com/app/internal/auth/...   it and every package below it
+ *   ./internal/auth/...                 the same, by directory (leading `./`)
+ *   example.com/app/.../mock            `...` matches any string
+ * Import-path patterns only match packages inside a module.
+ */
+
+import { GoModule } from '../graph/types.js';
+import { createGoModuleResolver } from '../graph/modules.js';
+import { isGoModPath } from './go-module-processor.js';
+import { goPackageDir } from '../../lib/utils.js';
+
+export interface AnalysisScope {
+  /** Subtree to analyze: `internal/auth`, `./internal/auth/...` */
+  root?: string;
+  /** Go package patterns (see header); a file must match one */
+  packages?: string[];
+}
+
+const isRelativePattern = (pattern: string): boolean =>
+  pattern === '.' || pattern === '..' || pattern.startsWith('./') || pattern.startsWith('../');
+
+/** `./internal/auth/...` -> `internal/auth`; '' for the repo root */
+export const normalizeScopeRoot = (root: string): string =>
+  root.trim().replace(/\\/g, '/').replace(/\/\.\.\.$/, '').replace(/^\.(\/|$)/, '').replace(/^\/+|\/+$/g, '');
+
+/** `go list` pattern as a RegExp; `x/...` also matches `x` itself */
+const packagePatternToRegExp = (pattern: string): RegExp => {
+  const parts = pattern.split('...').map(part => part.replace(/[.*+?^${}()|[\]\\]/g, '\\$&'));
+  let source = parts.join('.*');
+  if (pattern.endsWith('/...')) source = `${parts.slice(0, -1).join('.*').replace(/\/$/, '')}(?:/.*)?`;
+  return new RegExp(`^${source}$`);
+};
+
+/** Is the scope empty, i.e. the whole repo? */
+export const isWholeRepoScope = (scope?: AnalysisScope): boolean =>
+  !scope || (!normalizeScopeRoot(scope.root ?? '') && !(scope.packages ?? []).some(p => p.trim()));
+
+/**
+ * Predicate for repo-relative file paths in `scope`. `modules` map
+ * import-path patterns to directories and should list every go.mod in the
+ * repo, in scope or not.
+ */
+export const createScopeFilter = (scope: AnalysisScope, modules: GoModule[]): ((filePath: string) => boolean) => {
+  const root = normalizeScopeRoot(scope.root ?? '');
+  const patterns = (scope.packages ?? []).map(p => p.trim()).filter(Boolean);
+  const byDir = patterns.filter(isRelativePattern).map(packagePatternToRegExp);
+  const byImportPath = patterns.filter(p => !isRelativePattern(p)).map(packagePatternToRegExp);
+  const resolver = createGoModuleResolver(modules);
+  const decided = new Map<string, boolean>();
+
+  const dirInScope = (dir: string): boolean => {
+    if (root && dir !== root && !dir.startsWith(root + '/')) return false;
+    if (patterns.length === 0) return true;
+    // Relative patterns are matched against `./dir`, so `./...` covers the root package too
+    const relative = dir ? `./${dir}` : '.';
+    if (byDir.some(re => re.test(relative))) return true;
+    const importPath = byImportPath.length > 0 ? resolver.importPathForDir(dir) : null;
+    return importPath !== null && byImportPath.some(re => re.test(importPath));
+  };
+
+  return (filePath: string) => {
+    if (isGoModPath(filePath)) return true;
+    const dir = goPackageDir(filePath);
+    let inScope = decided.get(dir);
+    if (inScope === undefined) {
+      inScope = dirInScope(dir);
+      decided.set(dir, inScope);
+    }
+    return inScope;
+  };
+};
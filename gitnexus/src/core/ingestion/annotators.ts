@@ -0,0 +1,395 @@
+/**
+ * Symbol Annotators
+ *
+ * Hooks for attaching domain-specific metadata to symbols without forking
+ * the parser: tagging handlers with the HTTP route a framework registers
+ * them under, marking generated code, recording feature flags. Metadata is
+ * string key/values, stored on the node as `properties.meta` and travelling
+ * with it: JSON export, the graph cache, name search (`meta` filter), the
+ * graph API, and the Kuzu `meta` column (JSON text, for Cypher:
+ * `WHERE f.meta CONTAINS '"route":"/users"'`).
+ *
+ * Two kinds:
+ *   - Symbol annotators (registerAnnotator) see each symbol of a parsed file
+ *     with the file's syntax tree and the symbol's definition node, and write
+ *     that symbol's `meta`.
+ *   - File annotators (registerFileAnnotator) see each parsed file's tree
+ *     once every file is parsed, and can look up and write any symbol of the
+ *     graph by id or name: `mux.HandleFunc("/users", handleUsers)` in main.go
+ *     tags handleUsers wherever it is declared.
+ *
+ * Ordering:
+ *   - Symbol annotators run after a file's symbols are extracted and before
+ *     onSymbol listeners see them; per symbol, in registration order, each
+ *     seeing the meta left by the ones before it, so a later annotator can
+ *     override an earlier key. Symbols of a file come in source order.
+ *   - File annotators run after parsing (every chunk of a full analyze; the
+ *     changed files of an incremental update), after all symbol annotators,
+ *     so listeners don't see their writes. Per file in path order, then in
+ *     registration order.
+ *
+ * Threads: annotators are functions registered on the main thread and
+ * can't be sent to workers, so they run on the main thread. With workers
+ * (for symbol annotators) and after parsing (for file annotators) that
+ * means each file of a covered language is parsed a second time there;
+ * restrict annotators with `languages` to keep that cost down.
+ *
+ * Errors: an annotator that throws has its writes for that symbol (or, for
+ * a file annotator, that file) discarded; the run goes on, with the other
+ * annotators, symbols and files. Each failure is logged, and the first per
+ * file and annotator is reported as a parse error at stage 'annotate' (the
+ * file stays indexed).
+ *
+ * Symbols from registered language parsers have no syntax tree and aren't
+ * seen by symbol annotators; file annotators can still look them up.
+ */
+
+import Parser from 'tree-sitter';
+import { KnowledgeGraph, GraphNode, NodeLabel, NodeProperties } from '../graph/types.js';
+import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
+import { getLanguageFromFilename, createByteOffsetMapper, getSymbolPosition } from './utils.js';
+import type { ASTCache } from './ast-cache.js';
+import type { FileParseError } from './workers/parse-worker.js';
+import { goPackageDir } from '../../lib/utils.js';
+
+/** A symbol as an annotator sees it; only `meta` is written back */
+export interface AnnotatedSymbol {
+  readonly id: string;
+  readonly label: NodeLabel;
+  readonly properties: Readonly<NodeProperties>;
+  /** Metadata so far; non-string values are stored as strings */
+  meta: Record<string, string>;
+}
+
+export interface AnnotatedFile {
+  path: string;
+  content: string;
+  language: SupportedLanguages;
+  /** Root of the file's tree-sitter tree */
+  rootNode: any;
+}
+
+/** `node` is the symbol's definition (`function_declaration`, `type_spec`, ...) */
+export type SymbolAnnotator = (symbol: AnnotatedSymbol, file: AnnotatedFile, node: any) => void;
+
+/** A symbol found through a SymbolLookup; `properties.meta` is its meta so far */
+export interface LookedUpSymbol {
+  readonly id: string;
+  readonly label: NodeLabel;
+  readonly properties: Readonly<NodeProperties>;
+}
+
+/** Any symbol of the graph, for file annotators */
+export interface SymbolLookup {
+  /** The symbol with this graph node id */
+  byId(id: string): LookedUpSymbol | undefined;
+  /**
+   * Symbols named `name`: those of the annotated file first, then of its
+   * directory (its Go package), then the rest by path and line.
+   */
+  byName(name: string): LookedUpSymbol[];
+  /** Set (or, with undefined, remove) a meta key of the symbol with this id */
+  setMeta(id: string, key: string, value: string | number | boolean | undefined): void;
+}
+
+export type FileAnnotator = (file: AnnotatedFile, symbols: SymbolLookup) => void;
+
+export interface AnnotatorOptions {
+  /** Name used in warnings and parse errors (default `annotator #n`) */
+  name?: string;
+  /** Languages to run on (default all) */
+  languages?: SupportedLanguages[];
+}
+
+interface RegisteredAnnotator {
+  name: string;
+  annotate: SymbolAnnotator;
+  languages: Set<string> | null;
+}
+
+interface RegisteredFileAnnotator {
+  name: string;
+  annotate: FileAnnotator;
+  languages: Set<string> | null;
+}
+
+const registeredAnnotators: RegisteredAnnotator[] = [];
+const registeredFileAnnotators: RegisteredFileAnnotator[] = [];
+
+const annotatorCount = () => registeredAnnotators.length + registeredFileAnnotators.length;
+
+/** Register a symbol annotator; annotators run in registration order */
+export const registerAnnotator = (annotate: SymbolAnnotator, options: AnnotatorOptions = {}): void => {
+  registeredAnnotators.push({
+    name: options.name ?? `annotator #${annotatorCount() + 1}`,
+    annotate,
+    languages: options.languages ? new Set<string>(options.languages) : null,
+  });
+};
+
+/** Register a file annotator; they run after parsing, in registration order */
+export const registerFileAnnotator = (annotate: FileAnnotator, options: AnnotatorOptions = {}): void => {
+  registeredFileAnnotators.push({
+    name: options.name ?? `annotator #${annotatorCount() + 1}`,
+    annotate,
+    languages: options.languages ? new Set<string>(options.languages) : null,
+  });
+};
+
+export const clearAnnotators = (): void => {
+  registeredAnnotators.length = 0;
+  registeredFileAnnotators.length = 0;
+};
+
+const annotatorsFor = (language: string): RegisteredAnnotator[] =>
+  registeredAnnotators.filter(a => !a.languages || a.languages.has(language));
+
+const fileAnnotatorsFor = (language: string): RegisteredFileAnnotator[] =>
+  registeredFileAnnotators.filter(a => !a.languages || a.languages.has(language));
+
+/** Does any registered symbol annotator run on this language? */
+export const hasAnnotators = (language: string): boolean => annotatorsFor(language).length > 0;
+
+/** Is any file annotator registered? */
+export const hasFileAnnotators = (): boolean => registeredFileAnnotators.length > 0;
+
+const errorMessage = (err: unknown): string => (err instanceof Error ? err.message : String(err));
+
+/** Meta as stored: string values, no empty record */
+const storeMeta = (node: GraphNode, meta: Record<string, unknown>): void => {
+  const stored: Record<string, string> = {};
+  for (const [key, value] of Object.entries(meta)) {
+    if (value !== undefined && value !== null) stored[key] = String(value);
+  }
+  if (Object.keys(stored).length > 0) node.properties.meta = stored;
+  else delete node.properties.meta;
+};
+
+/**
+ * Name and definition nodes of `nodes` in the tree, found by the positions
+ * the parser recorded: the name by row, text and byte column, the
+ * definition as the outermost ancestor spanning startByte..endByte.
+ */
+const locateSymbols = (rootNode: any, content: string, nodes: GraphNode[]): Map<GraphNode, any> => {
+  const toByte = createByteOffsetMapper(content);
+  const names = new Set(nodes.map(n => n.properties.name));
+  const leaves = new Map<string, any[]>();
+  const stack = [rootNode];
+  while (stack.length > 0) {
+    const node = stack.pop();
+    const children = node.namedChildren ?? [];
+    if (children.length === 0) {
+      if (!names.has(node.text)) continue;
+      const key = `${node.startPosition.row}:${node.text}`;
+      if (!leaves.has(key)) leaves.set(key, []);
+      leaves.get(key)!.push(node);
+      continue;
+    }
+    for (let i = children.length - 1; i >= 0; i--) stack.push(children[i]);
+  }
+
+  const located = new Map<GraphNode, any>();
+  for (const node of nodes) {
+    const candidates = leaves.get(`${node.properties.startLine}:${node.properties.name}`) ?? [];
+    const nameNode = candidates.find(c =>
+      getSymbolPosition(content, toByte, c, null).startColumn === node.properties.startColumn) ?? candidates[0];
+    if (!nameNode) continue;
+    let definition = nameNode.parent ?? nameNode;
+    if (node.properties.startByte !== undefined) {
+      for (let current = nameNode.parent; current; current = current.parent) {
+        if (toByte(current.startIndex) === node.properties.startByte && toByte(current.endIndex) === node.properties.endByte) {
+          definition = current;
+        }
+      }
+    }
+    located.set(node, definition);
+  }
+  return located;
+};
+
+/**
+ * Run the registered annotators over the symbols of one parsed file (see
+ * header). Failures go to `errors`.
+ */
+export const annotateSymbols = (
+  file: AnnotatedFile,
+  nodes: GraphNode[],
+  errors?: FileParseError[],
+): void => {
+  const annotators = annotatorsFor(file.language);
+  if (annotators.length === 0 || nodes.length === 0) return;
+
+  const located = locateSymbols(file.rootNode, file.content, nodes);
+  const reported = new Set<string>();
+  for (const node of nodes) {
+    const definition = located.get(node);
+    if (!definition) continue;
+    for (const annotator of annotators) {
+      const meta = { ...(node.properties.meta ?? {}) };
+      try {
+        annotator.annotate({ id: node.id, label: node.label, properties: node.properties, meta }, file, definition);
+      } catch (err) {
+        console.warn(`  ${annotator.name} failed on ${node.id}: ${errorMessage(err)}`);
+        if (!reported.has(annotator.name)) {
+          reported.add(annotator.name);
+          errors?.push({ filePath: file.path, stage: 'annotate', message: `${annotator.name}: ${errorMessage(err)}` });
+        }
+        continue;
+      }
+      storeMeta(node, meta);
+    }
+  }
+};
+
+/**
+ * Annotate symbols parsed in workers: re-parse their files on the main
+ * thread, for languages an annotator covers.
+ */
+export const annotateParsedFiles = async (
+  files: { path: string; content: string }[],
+  nodesByFile: Map<string, GraphNode[]>,
+  errors?: FileParseError[],
+): Promise<void> => {
+  if (registeredAnnotators.length === 0) return;
+  let parser: Parser | null = null;
+  for (const file of files) {
+    const nodes = nodesByFile.get(file.path);
+    const language = getLanguageFromFilename(file.path);
+    if (!nodes?.length || !language || !hasAnnotators(language)) continue;
+
+    parser ??= await loadParser();
+    let tree;
+    try {
+      await loadLanguage(language, file.path);
+      tree = parser.parse(file.content, undefined, { bufferSize: 1024 * 256 });
+    } catch (err) {
+      errors?.push({ filePath: file.path, stage: 'annotate', message: errorMessage(err) });
+      continue;
+    }
+    annotateSymbols({ path: file.path, content: file.content, language, rootNode: tree.rootNode }, nodes, errors);
+  }
+};
+
+// ============================================================================
+// File annotators
+// ============================================================================
+
+/** Labels that aren't symbols a file annotator would tag */
+const NON_SYMBOL_LABELS = new Set<string>(['Project', 'Package', 'Module', 'Folder', 'File', 'Community', 'Process', 'Import']);
+
+const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+
+
+/** Symbol nodes by name, by path then line */
+const indexSymbolsByName = (graph: KnowledgeGraph): Map<string, GraphNode[]> => {
+  const byName = new Map<string, GraphNode[]>();
+  graph.forEachNode(node => {
+    const { name, filePath } = node.properties;
+    if (NON_SYMBOL_LABELS.has(node.label) || !name || !filePath) return;
+    const nodes = byName.get(name);
+    if (nodes) nodes.push(node);
+    else byName.set(name, [node]);
+  });
+  for (const nodes of byName.values()) {
+    nodes.sort((a, b) =>
+      cmp(a.properties.filePath, b.properties.filePath) ||
+      (a.properties.startLine ?? 0) - (b.properties.startLine ?? 0) ||
+      cmp(a.id, b.id));
+  }
+  return byName;
+};
+
+/**
+ * Lookup for one annotator run on `filePath`. Writes go to `pending` (node
+ * id -> meta) and reach the graph only once the annotator returns.
+ */
+const createSymbolLookup = (
+  graph: KnowledgeGraph,
+  byName: Map<string, GraphNode[]>,
+  filePath: string,
+  pending: Map<string, Record<string, string | undefined>>,
+): SymbolLookup => {
+  const view = (node: GraphNode): LookedUpSymbol => {
+    const meta = pending.get(node.id);
+    if (!meta) return { id: node.id, label: node.label, properties: node.properties };
+    const merged = Object.fromEntries(Object.entries(meta).filter(([, v]) => v !== undefined)) as Record<string, string>;
+    return {
+      id: node.id,
+      label: node.label,
+      properties: Object.keys(merged).length > 0 ? { ...node.properties, meta: merged } : { ...node.properties, meta: undefined },
+    };
+  };
+  const isSymbol = (node: GraphNode | undefined): node is GraphNode =>
+    !!node && !NON_SYMBOL_LABELS.has(node.label);
+  const dir = goPackageDir(filePath);
+  const rank = (node: GraphNode) =>
+    node.properties.filePath === filePath ? 0 : goPackageDir(node.properties.filePath) === dir ? 1 : 2;
+
+  return {
+    byId: (id) => {
+      const node = graph.getNode(id);
+      return isSymbol(node) ? view(node) : undefined;
+    },
+    byName: (name) => {
+      const nodes = byName.get(name) ?? [];
+      // Stable sort keeps path/line order within each rank
+      return [...nodes].sort((a, b) => rank(a) - rank(b)).map(view);
+    },
+    setMeta: (id, key, value) => {
+      const node = graph.getNode(id);
+      if (!isSymbol(node)) throw new Error(`no symbol ${id}`);
+      if (!pending.has(id)) pending.set(id, { ...(node.properties.meta ?? {}) });
+      pending.get(id)![key] = value === undefined ? undefined : String(value);
+    },
+  };
+};
+
+/**
+ * Run the registered file annotators over `files` (see header), once their
+ * symbols and every other file's are in the graph. Trees come from
+ * `astCache` when it still holds them, else the file is parsed again.
+ * Failures go to `errors`.
+ */
+export const annotateFiles = async (
+  graph: KnowledgeGraph,
+  files: { path: string; content: string }[],
+  errors?: FileParseError[],
+  astCache?: ASTCache,
+): Promise<void> => {
+  if (registeredFileAnnotators.length === 0) return;
+  let parser: Parser | null = null;
+  let byName: Map<string, GraphNode[]> | null = null;
+  for (const file of [...files].sort((a, b) => cmp(a.path, b.path))) {
+    const language = getLanguageFromFilename(file.path);
+    const annotators = language ? fileAnnotatorsFor(language) : [];
+    // Same limit as parsing: larger files were never indexed
+    if (!language || annotators.length === 0 || file.content.length > 512 * 1024) continue;
+
+    let tree = astCache?.get(file.path);
+    if (!tree) {
+      parser ??= await loadParser();
+      try {
+        await loadLanguage(language, file.path);
+        tree = parser.parse(file.content, undefined, { bufferSize: 1024 * 256 });
+      } catch (err) {
+        errors?.push({ filePath: file.path, stage: 'annotate', message: errorMessage(err) });
+        continue;
+      }
+    }
+    byName ??= indexSymbolsByName(graph);
+
+    const annotated: AnnotatedFile = { path: file.path, content: file.content, language, rootNode: tree.rootNode };
+    for (const annotator of annotators) {
+      const pending = new Map<string, Record<string, string | undefined>>();
+      try {
+        annotator.annotate(annotated, createSymbolLookup(graph, byName, file.path, pending));
+      } catch (err) {
+        console.warn(`  ${annotator.name} failed on ${file.path}: ${errorMessage(err)}`);
+        errors?.push({ filePath: file.path, stage: 'annotate', message: `${annotator.name}: ${errorMessage(err)}` });
+        continue;
+      }
+      for (const [id, meta] of pending) storeMeta(graph.getNode(id)!, meta);
+    }
+  }
+};
@@ -0,0 +1,346 @@
+/**
+ * Go Build Constraints
+ *
+ * Decides which Go files belong to a build for a target platform, the way
+ * `go/build` does, so a package with `conn_linux.go` and `conn_windows.go`
+ * is indexed once instead of with both (conflicting) variants. A file is
+ * built when all of these hold:
+ *   - its name doesn't start with `_` (or `.`, which every scan skips)
+ *   - the name's GOOS/GOARCH suffix matches (`_linux`, `_arm64`,
+ *     `_linux_arm64`, before an optional `_test`)
+ *   - its `//go:build` line in the header matches, or when there is none,
+ *     every `// +build` line before the header's last blank line does
+ *
+ * Tags are matched as in go/build's matchTag: the target GOOS and GOARCH,
+ * `unix` for Unix-like GOOS, `linux` also for android, `darwin` for ios,
+ * `solaris` for illumos, `cgo` when cgo is enabled, the compiler (`gc`),
+ * release tags `go1.1` up to the target version, and user tags. A
+ * constraint that doesn't parse excludes the file, as `go build` rejects it.
+ *
+ * With `allVariants` nothing is excluded; instead every file records its
+ * combined constraint (see fileConstraintExpression), and its symbols carry
+ * it as `buildConstraint`, e.g. `linux && amd64 && !cgo`.
+ */
+
+import os from 'os';
+
+export interface BuildConstraintOptions {
+  /** Target GOOS (default: $GOOS, else the host's) */
+  goos?: string;
+  /** Target GOARCH (default: $GOARCH, else the host's) */
+  goarch?: string;
+  /** Extra build tags, as passed to `go build -tags` */
+  tags?: string[];
+  /** Enable the `cgo` tag (default: $CGO_ENABLED, else on for the host and off when cross-compiling) */
+  cgo?: boolean;
+  /** Newest release tag to satisfy, e.g. `1.22` or `go1.22` (default DEFAULT_GO_VERSION) */
+  goVersion?: string;
+  /** Keep every variant, tagging symbols with their constraint instead of filtering */
+  allVariants?: boolean;
+}
+
+export interface BuildContext {
+  goos: string;
+  goarch: string;
+  cgo: boolean;
+  tags: Set<string>;
+  /** Minor version of the newest release tag (`go1.N`) */
+  goMinor: number;
+}
+
+/** Release tags satisfied by default: go1.1 through this version */
+export const DEFAULT_GO_VERSION = '1.24';
+
+/** GOOS values go/build recognizes in file names (go/build/syslist.go) */
+const KNOWN_OS = new Set([
+  'aix', 'android', 'darwin', 'dragonfly', 'freebsd', 'hurd', 'illumos', 'ios', 'js', 'linux', 'nacl',
+  'netbsd', 'openbsd', 'plan9', 'solaris', 'wasip1', 'windows', 'zos',
+]);
+
+/** GOARCH values go/build recognizes in file names */
+const KNOWN_ARCH = new Set([
+  '386', 'amd64', 'amd64p32', 'arm', 'armbe', 'arm64', 'arm64be', 'loong64', 'mips', 'mipsle', 'mips64',
+  'mips64le', 'mips64p32', 'mips64p32le', 'ppc', 'ppc64', 'ppc64le', 'riscv', 'riscv64', 's390', 's390x',
+  'sparc', 'sparc64', 'wasm',
+]);
+
+/** GOOS values that satisfy the `unix` tag */
+const UNIX_OS = new Set([
+  'aix', 'android', 'darwin', 'dragonfly', 'freebsd', 'hurd', 'illumos', 'ios', 'linux', 'netbsd',
+  'openbsd', 'solaris',
+]);
+
+/** Node's process.platform / process.arch -> GOOS / GOARCH */
+const NODE_PLATFORM_GOOS: Record<string, string> = {
+  aix: 'aix', android: 'android', darwin: 'darwin', freebsd: 'freebsd', linux: 'linux',
+  netbsd: 'netbsd', openbsd: 'openbsd', sunos: 'solaris', win32: 'windows',
+};
+const NODE_ARCH_GOARCH: Record<string, string> = {
+  arm: 'arm', arm64: 'arm64', ia32: '386', loong64: 'loong64', mips: 'mips', mipsel: 'mipsle',
+  ppc: 'ppc', riscv64: 'riscv64', s390: 's390', s390x: 's390x', x64: 'amd64',
+};
+
+const hostGOOS = (): string => NODE_PLATFORM_GOOS[process.platform] ?? process.platform;
+
+const hostGOARCH = (): string => {
+  if (process.arch === 'ppc64') return os.endianness() === 'LE' ? 'ppc64le' : 'ppc64';
+  return NODE_ARCH_GOARCH[process.arch] ?? process.arch;
+};
+
+const parseGoMinor = (version: string): number => {
+  const match = /^(?:go)?1\.(\d+)/.exec(version.trim());
+  if (!match) throw new Error(`Invalid Go version: ${version}`);
+  return parseInt(match[1], 10);
+};
+
+/**
+ * The build context for `options`, filling in what's unset from the
+ * environment like the go command does ($GOOS, $GOARCH, $CGO_ENABLED),
+ * then from the host.
+ */
+export const createBuildContext = (options: BuildConstraintOptions = {}): BuildContext => {
+  const goos = options.goos || process.env.GOOS || hostGOOS();
+  const goarch = options.goarch || process.env.GOARCH || hostGOARCH();
+  const envCgo = process.env.CGO_ENABLED;
+  const cgo = options.cgo
+    ?? (envCgo === '1' ? true : envCgo === '0' ? false : goos === hostGOOS() && goarch === hostGOARCH());
+  return {
+    goos,
+    goarch,
+    cgo,
+    tags: new Set((options.tags ?? []).map(t => t.trim()).filter(Boolean)),
+    goMinor: parseGoMinor(options.goVersion ?? DEFAULT_GO_VERSION),
+  };
+};
+
+/** `linux/amd64 (cgo, tags: integration)`, for log lines */
+export const describeBuildContext = (ctx: BuildContext): string => {
+  const extras = [ctx.cgo ? 'cgo' : '', ctx.tags.size > 0 ? `tags: ${[...ctx.tags].join(',')}` : ''].filter(Boolean);
+  return `${ctx.goos}/${ctx.goarch}${extras.length > 0 ? ` (${extras.join(', ')})` : ''}`;
+};
+
+/** Does a single build tag hold in `ctx`? (go/build matchTag) */
+export const matchBuildTag = (tag: string, ctx: BuildContext): boolean => {
+  if (tag === ctx.goos || tag === ctx.goarch || tag === 'gc') return true;
+  if (tag === 'cgo') return ctx.cgo;
+  if (tag === 'unix') return UNIX_OS.has(ctx.goos);
+  if (tag === 'linux' && ctx.goos === 'android') return true;
+  if (tag === 'darwin' && ctx.goos === 'ios') return true;
+  if (tag === 'solaris' && ctx.goos === 'illumos') return true;
+  const release = /^go1\.(\d+)$/.exec(tag);
+  if (release) return parseInt(release[1], 10) <= ctx.goMinor;
+  return ctx.tags.has(tag);
+};
+
+// ============================================================================
+// CONSTRAINT EXPRESSIONS
+// ============================================================================
+
+export type BuildExpr =
+  | { op: 'tag'; tag: string }
+  | { op: 'not'; x: BuildExpr }
+  | { op: 'and' | 'or'; x: BuildExpr; y: BuildExpr };
+
+const TAG_PATTERN = /^[A-Za-z0-9_.]+$/;
+
+/** Parse a `//go:build` expression (`linux && (amd64 || arm64)`); null when malformed */
+export const parseBuildExpr = (text: string): BuildExpr | null => {
+  const tokens = text.match(/&&|\|\||[!()]|[^\s!()&|]+|\S/g) ?? [];
+  let pos = 0;
+
+  const parseOr = (): BuildExpr | null => {
+    let x = parseAnd();
+    while (x && tokens[pos] === '||') {
+      pos++;
+      const y = parseAnd();
+      x = y ? { op: 'or', x, y } : null;
+    }
+    return x;
+  };
+  const parseAnd = (): BuildExpr | null => {
+    let x = parseNot();
+    while (x && tokens[pos] === '&&') {
+      pos++;
+      const y = parseNot();
+      x = y ? { op: 'and', x, y } : null;
+    }
+    return x;
+  };
+  const parseNot = (): BuildExpr | null => {
+    const token = tokens[pos++];
+    if (token === '!') {
+      const x = parseNot();
+      return x ? { op: 'not', x } : null;
+    }
+    if (token === '(') {
+      const x = parseOr();
+      if (tokens[pos++] !== ')') return null;
+      return x;
+    }
+    return token !== undefined && TAG_PATTERN.test(token) ? { op: 'tag', tag: token } : null;
+  };
+
+  const expr = parseOr();
+  return expr && pos === tokens.length ? expr : null;
+};
+
+/**
+ * The expression of `// +build` lines: space-separated options are OR-ed,
+ * comma-separated terms AND-ed, and the lines AND-ed together.
+ */
+const parsePlusBuildLines = (lines: string[]): BuildExpr | null => {
+  let result: BuildExpr | null = null;
+  for (const line of lines) {
+    let lineExpr: BuildExpr | null = null;
+    for (const option of line.trim().split(/\s+/).filter(Boolean)) {
+      let optionExpr: BuildExpr | null = null;
+      for (const term of option.split(',')) {
+        const negated = term.startsWith('!');
+        const tag = negated ? term.substring(1) : term;
+        if (!TAG_PATTERN.test(tag)) return null;
+        const termExpr: BuildExpr = negated ? { op: 'not', x: { op: 'tag', tag } } : { op: 'tag', tag };
+        optionExpr = optionExpr ? { op: 'and', x: optionExpr, y: termExpr } : termExpr;
+      }
+      if (!optionExpr) continue;
+      lineExpr = lineExpr ? { op: 'or', x: lineExpr, y: optionExpr } : optionExpr;
+    }
+    if (!lineExpr) continue;
+    result = result ? { op: 'and', x: result, y: lineExpr } : lineExpr;
+  }
+  return result;
+};
+
+export const evalBuildExpr = (expr: BuildExpr, ctx: BuildContext): boolean => {
+  switch (expr.op) {
+    case 'tag': return matchBuildTag(expr.tag, ctx);
+    case 'not': return !evalBuildExpr(expr.x, ctx);
+    case 'and': return evalBuildExpr(expr.x, ctx) && evalBuildExpr(expr.y, ctx);
+    case 'or': return evalBuildExpr(expr.x, ctx) || evalBuildExpr(expr.y, ctx);
+  }
+};
+
+const PRECEDENCE = { or: 1, and: 2, not: 3, tag: 4 };
+
+/** `//go:build` syntax, parenthesized only where precedence needs it */
+export const formatBuildExpr = (expr: BuildExpr, parent = 0): string => {
+  let text: string;
+  switch (expr.op) {
+    case 'tag': return expr.tag;
+    case 'not': return `!${formatBuildExpr(expr.x, PRECEDENCE.not)}`;
+    case 'and': text = `${formatBuildExpr(expr.x, PRECEDENCE.and)} && ${formatBuildExpr(expr.y, PRECEDENCE.and)}`; break;
+    case 'or': text = `${formatBuildExpr(expr.x, PRECEDENCE.or)} || ${formatBuildExpr(expr.y, PRECEDENCE.or)}`; break;
+  }
+  return PRECEDENCE[expr.op] < parent ? `(${text})` : text;
+};
+
+// ============================================================================
+// FILES
+// ============================================================================
+
+/**
+ * Tags implied by a file name: `[goos]`, `[goarch]` or `[goos, goarch]`
+ * (go/build goodOSArchFile). Everything before the first `_` is the base
+ * name, so `linux.go` and `windows_test.go` are unconstrained.
+ */
+export const fileNameBuildTags = (filePath: string): string[] => {
+  const base = filePath.substring(filePath.lastIndexOf('/') + 1);
+  const stem = base.split('.')[0];
+  const underscore = stem.indexOf('_');
+  if (underscore < 0) return [];
+  const parts = stem.substring(underscore).split('_');
+  if (parts[parts.length - 1] === 'test') parts.pop();
+  const n = parts.length;
+  if (n >= 2 && KNOWN_OS.has(parts[n - 2]) && KNOWN_ARCH.has(parts[n - 1])) return [parts[n - 2], parts[n - 1]];
+  if (n >= 1 && (KNOWN_OS.has(parts[n - 1]) || KNOWN_ARCH.has(parts[n - 1]))) return [parts[n - 1]];
+  return [];
+};
+
+export interface BuildHeader {
+  /** The header's constraint; null when it has none */
+  expr: BuildExpr | null;
+  /** A constraint line that doesn't parse */
+  invalid?: string;
+  /** The package clause was reached, so the header is complete */
+  complete: boolean;
+}
+
+/**
+ * The build constraint in a Go file's header: the comments and blank lines
+ * before the package clause. `//go:build` wins; `// +build` lines only count
+ * when a blank line follows them within the header. `complete` is false
+ * when the text ends inside the header, e.g. a prefix of a large file.
+ */
+export const parseBuildHeader = (content: string): BuildHeader => {
+  const lines = content.split('\n');
+  let goBuild: string | null = null;
+  const plusBuild: { line: string; index: number }[] = [];
+  let lastBlank = -1;
+  let inBlock = false;
+  let complete = false;
+
+  for (let i = 0; i < lines.length; i++) {
+    let line = lines[i].trim();
+    if (inBlock) {
+      const close = line.indexOf('*/');
+      if (close < 0) continue;
+      inBlock = false;
+      line = line.substring(close + 2).trim();
+      if (line === '') continue;
+    }
+    if (line === '') {
+      lastBlank = i;
+      continue;
+    }
+    if (line.startsWith('//')) {
+      if (/^\/\/go:build(\s|$)/.test(line)) goBuild ??= line.substring('//go:build'.length).trim();
+      else if (/^\/\/\s*\+build(\s|$)/.test(line)) plusBuild.push({ line: line.replace(/^\/\/\s*\+build/, ''), index: i });
+      continue;
+    }
+    if (line.startsWith('/*')) {
+      if (!line.includes('*/', 2)) inBlock = true;
+      continue;
+    }
+    complete = true;
+    break;
+  }
+
+  if (goBuild !== null) {
+    const expr = parseBuildExpr(goBuild);
+    return expr ? { expr, complete } : { expr: null, invalid: `//go:build ${goBuild}`, complete };
+  }
+  const counted = plusBuild.filter(p => p.index < lastBlank).map(p => p.line);
+  if (counted.length === 0) return { expr: null, complete };
+  const expr = parsePlusBuildLines(counted);
+  return expr ? { expr, complete } : { expr: null, invalid: `// +build ${counted.join(' / ')}`, complete };
+};
+
+/**
+ * A Go file's whole constraint, name and header combined, in `//go:build`
+ * syntax (`linux && amd64 && !cgo`); undefined when the file is built
+ * everywhere. Recorded as `buildConstraint` on the file's nodes.
+ */
+export const fileConstraintExpression = (filePath: string, content: string): string | undefined => {
+  const parts = fileNameBuildTags(filePath);
+  const header = parseBuildHeader(content);
+  if (header.expr) parts.push(formatBuildExpr(header.expr, PRECEDENCE.and));
+  else if (header.invalid) parts.push(header.invalid);
+  return parts.length > 0 ? parts.join(' && ') : undefined;
+};
+
+/** Is this a Go source file that build constraints apply to? */
+export const isGoSourceFile = (filePath: string): boolean => filePath.endsWith('.go');
+
+/** The checks that need only the path: the `_` prefix and the name's GOOS/GOARCH */
+export const matchBuildFileName = (filePath: string, ctx: BuildContext): boolean => {
+  const base = filePath.substring(filePath.lastIndexOf('/') + 1);
+  if (base.startsWith('_') || base.startsWith('.')) return false;
+  return fileNameBuildTags(filePath).every(tag => matchBuildTag(tag, ctx));
+};
+
+/** The check on the file's header; `header` must be complete or the whole file */
+export const matchBuildHeader = (header: BuildHeader, ctx: BuildContext): boolean =>
+  !header.invalid && (!header.expr || evalBuildExpr(header.expr, ctx));
+
+/** Is the Go file at `filePath` with `content` part of the build for `ctx`? (go/build MatchFile) */
+export const matchBuildFile = (filePath: string, content: string, ctx: BuildContext): boolean =>
+  matchBuildFileName(filePath, ctx) && matchBuildHeader(parseBuildHeader(content), ctx);
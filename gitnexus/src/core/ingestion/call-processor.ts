@@ -1,14 +1,21 @@
 import { KnowledgeGraph } from '../graph/types.js';
+import { ExternalCall, externalCallKey } from '../graph/call-graph.js';
+import { getGoModules, createGoModuleResolver, GoModuleResolver } from '../graph/modules.js';
 import { ASTCache } from './ast-cache.js';
 import { SymbolTable } from './symbol-table.js';
 import { ImportMap } from './import-processor.js';
 import Parser from 'tree-sitter';
 import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
 import { LANGUAGE_QUERIES } from './tree-sitter-queries.js';
-import { generateId } from '../../lib/utils.js';
+import { generateId, goPackageDir } from '../../lib/utils.js';
 import { getLanguageFromFilename, yieldToEventLoop } from './utils.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
+import { createGoCallContextExtractor, collectGoImportAliases, GoCallContext, GO_PREDECLARED, GO_PREDECLARED_TYPES, goSymbolIdName } from './go-metadata.js';
 import type { ExtractedCall } from './workers/parse-worker.js';
 
+/** Collector for calls without a target node, keyed by externalCallKey */
+export type ExternalCallMap = Map<string, ExternalCall>;
+
 /**
  * Node types that represent function/method definitions across languages.
  * Used to find the enclosing function for a call site.
@@ -59,6 +66,15 @@ const findEnclosingFunction = (
       let funcName: string | null = null;
       let label = 'Function';
       
+      // Go ids carry the receiver and an ordinal (see goSymbolIdName), so build them from the tree
+      if (filePath.endsWith('.go') && (current.type === 'function_declaration' || current.type === 'method_declaration')) {
+        const nameNode = current.childForFieldName?.('name');
+        if (nameNode) {
+          const goLabel = current.type === 'method_declaration' ? 'Method' : 'Function';
+          return generateId(goLabel, `${filePath}:${goSymbolIdName(nameNode, goLabel)}`);
+        }
+      }
+
       // Different node types have different name locations
       // Swift init/deinit — handle before generic cases (more specific)
       if (current.type === 'init_declaration' || current.type === 'deinit_declaration') {
@@ -143,9 +159,12 @@ export const processCalls = async (
   astCache: ASTCache,
   symbolTable: SymbolTable,
   importMap: ImportMap,
-  onProgress?: (current: number, total: number) => void
+  onProgress?: (current: number, total: number) => void,
+  externalCalls?: ExternalCallMap
 ) => {
   const parser = await loadParser();
+  const goModules = createGoModuleResolver(getGoModules(graph));
+  const isAnalyzedDir = createAnalyzedDirLookup(graph);
 
   for (let i = 0; i < files.length; i++) {
     const file = files[i];
@@ -191,6 +210,10 @@ export const processCalls = async (
       continue;
     }
 
+    const goCallContext = language === SupportedLanguages.Go
+      ? createGoCallContextExtractor(tree.rootNode)
+      : null;
+
     // 3. Process each call match
     matches.forEach(match => {
       const captureMap: Record<string, any> = {};
@@ -207,33 +230,26 @@ export const processCalls = async (
       // Skip common built-ins and noise
       if (isBuiltInOrNoise(calledName)) return;
 
-      // 4. Resolve the target using priority strategy (returns confidence)
-      const resolved = resolveCallTarget(
-        calledName,
-        file.path,
-        symbolTable,
-        importMap
-      );
-
-      if (!resolved) return;
-
-      // 5. Find the enclosing function (caller)
+      // 4. Find the enclosing function (caller)
       const callNode = captureMap['call'];
       const enclosingFuncId = findEnclosingFunction(callNode, file.path, symbolTable);
-      
+
       // Use enclosing function as source, fallback to file for top-level calls
       const sourceId = enclosingFuncId || generateId('File', file.path);
-      
-      const relId = generateId('CALLS', `${sourceId}:${calledName}->${resolved.nodeId}`);
 
-      graph.addRelationship({
-        id: relId,
+      // 5. Resolve the target using priority strategy (returns confidence)
+      const call: ResolvableCall = {
+        filePath: file.path,
+        calledName,
         sourceId,
-        targetId: resolved.nodeId,
-        type: 'CALLS',
-        confidence: resolved.confidence,
-        reason: resolved.reason,
-      });
+        line: callNode.startPosition.row,
+        ...(goCallContext ? goCallContext(callNode) : {}),
+      };
+      const resolved = goCallContext
+        ? resolveGoCallTarget(call, graph, symbolTable, importMap, goModules, isAnalyzedDir)
+        : resolveCallTarget(calledName, file.path, symbolTable, importMap);
+
+      recordCall(graph, call, resolved, externalCalls);
     });
 
     // Tree is now owned by the LRU cache — no manual delete needed
@@ -246,9 +262,69 @@ export const processCalls = async (
 interface ResolveResult {
   nodeId: string;
   confidence: number;  // 0-1: how sure are we?
-  reason: string;      // 'import-resolved' | 'same-file' | 'fuzzy-global'
+  reason: string;      // 'import-resolved' | 'same-file' | 'fuzzy-global' | 'same-package' | 'receiver-typed' | 'dot-import'
 }
 
+/** A call site ready for resolution (worker-extracted or sequential) */
+type ResolvableCall = Pick<ExtractedCall, 'filePath' | 'calledName' | 'sourceId' | 'line'> & GoCallContext;
+
+/**
+ * Outcome of resolving one call: a target node, an external reason (kept as
+ * an ExternalCall), or null for calls that aren't edges at all (builtins,
+ * type conversions).
+ */
+type CallResolution = ResolveResult | ExternalCall['reason'] | null;
+
+/** Add the CALLS edge, or record the call as external; repeat call sites add their line */
+const recordCall = (
+  graph: KnowledgeGraph,
+  call: ResolvableCall,
+  resolved: CallResolution,
+  externalCalls?: ExternalCallMap
+) => {
+  if (!resolved) return;
+
+  if (typeof resolved === 'string') {
+    if (!externalCalls) return;
+    const external: ExternalCall = {
+      sourceId: call.sourceId,
+      filePath: call.filePath,
+      calleeName: call.qualifier ? `${call.qualifier}.${call.calledName}` : call.calledName,
+      reason: resolved,
+      callLines: [call.line],
+      ...(call.qualifierPackage ? { packagePath: call.qualifierPackage }
+        : call.dotImports?.length === 1 ? { packagePath: call.dotImports[0] }
+        : {}),
+    };
+    const key = externalCallKey(external);
+    const existing = externalCalls.get(key);
+    if (existing) addCallLine(existing, call.line);
+    else externalCalls.set(key, external);
+    return;
+  }
+
+  const relId = generateId('CALLS', `${call.sourceId}:${call.calledName}->${resolved.nodeId}`);
+  const existing = graph.getRelationship(relId);
+  if (existing) {
+    addCallLine(existing, call.line);
+    return;
+  }
+  graph.addRelationship({
+    id: relId,
+    sourceId: call.sourceId,
+    targetId: resolved.nodeId,
+    type: 'CALLS',
+    confidence: resolved.confidence,
+    reason: resolved.reason,
+    callLines: [call.line],
+  });
+};
+
+const addCallLine = (target: { callLines?: number[] }, line: number) => {
+  if (!target.callLines) target.callLines = [];
+  if (!target.callLines.includes(line)) target.callLines.push(line);
+};
+
 /**
  * Resolve a function call to its target node ID using priority strategy:
  * A. Check imported files first (highest confidence)
@@ -291,6 +367,141 @@ const resolveCallTarget = (
   return null;
 };
 
+/**
+ * Could repo directory `dir` be the package at import path `pkg`? Only
+ * when the import path ends in the whole directory path: `errors` never
+ * matches `internal/errors`.
+ */
+const goPackageMatches = (pkg: string, dir: string): boolean =>
+  dir !== '' && (pkg === dir || pkg.endsWith('/' + dir));
+
+/**
+ * Is `dir` the package imported as `importPath` from `fromFile`? Exact when
+ * the repo has go.mod files (see graph/modules.ts), by path shape otherwise.
+ */
+const goImportMatches = (goModules: GoModuleResolver, importPath: string, fromFile: string, dir: string): boolean =>
+  goModules.modules.length > 0
+    ? goModules.resolveImportDir(importPath, fromFile) === dir
+    : goPackageMatches(importPath, dir);
+
+const GO_TYPE_LABELS = new Set(['Struct', 'Interface', 'TypeAlias']);
+
+/** Does a repo directory hold analyzed Go files? Built on first use */
+const createAnalyzedDirLookup = (graph: KnowledgeGraph): ((dir: string) => boolean) => {
+  let dirs: Set<string> | null = null;
+  return (dir: string) => {
+    if (!dirs) {
+      dirs = new Set();
+      graph.forEachNode(node => {
+        if (node.label === 'File' && node.properties.filePath.endsWith('.go')) dirs!.add(goPackageDir(node.properties.filePath));
+      });
+    }
+    return dirs.has(dir);
+  };
+};
+
+/**
+ * Why a call into `importPath` has no target: the package is in the repo
+ * but wasn't analyzed (outside the analysis scope), or it's outside the repo.
+ */
+const externalPackageReason = (
+  goModules: GoModuleResolver,
+  isAnalyzedDir: (dir: string) => boolean,
+  importPath: string,
+  fromFile: string,
+): ExternalCall['reason'] => {
+  const dir = goModules.resolveImportDir(importPath, fromFile);
+  return dir !== null && !isAnalyzedDir(dir) ? 'out-of-scope' : 'external-package';
+};
+
+/**
+ * Go-aware resolution. A Go package is a directory, so unqualified calls
+ * resolve within it; `pkg.F()` resolves only into the imported package; and
+ * `x.M()` on a variable of known type resolves to that type's method.
+ * Calls that leave the repo, or the analysis scope, are reported as
+ * external instead of being fuzzy-matched to a same-named repo symbol.
+ */
+const resolveGoCallTarget = (
+  call: ResolvableCall,
+  graph: KnowledgeGraph,
+  symbolTable: SymbolTable,
+  importMap: ImportMap,
+  goModules: GoModuleResolver,
+  isAnalyzedDir: (dir: string) => boolean,
+): CallResolution => {
+  const { calledName, filePath } = call;
+  const defs = symbolTable.lookupFuzzy(calledName);
+  const fileDir = goPackageDir(filePath);
+
+  if (call.qualifierPackage) {
+    const pkg = call.qualifierPackage;
+    const inPackage = defs.filter(d => goImportMatches(goModules, pkg, filePath, goPackageDir(d.filePath)));
+    const candidates = inPackage.filter(d => d.type === 'Function');
+    if (candidates.length === 0) {
+      // `models.ID(x)` is a conversion into a repo package, not a call
+      if (inPackage.some(d => GO_TYPE_LABELS.has(d.type))) return null;
+      return externalPackageReason(goModules, isAnalyzedDir, pkg, filePath);
+    }
+    const importedFiles = importMap.get(filePath);
+    const best = candidates.find(d => importedFiles?.has(d.filePath)) ?? candidates[0];
+    return { nodeId: best.nodeId, confidence: 0.9, reason: 'import-resolved' };
+  }
+
+  if (call.qualifierType) {
+    const dotIdx = call.qualifierType.lastIndexOf('.');
+    const typePkg = dotIdx >= 0 ? call.qualifierType.substring(0, dotIdx) : '';
+    const typeName = dotIdx >= 0 ? call.qualifierType.substring(dotIdx + 1) : call.qualifierType;
+    // `db.Conn`: `db` is whatever the file imports under that name
+    const typeImportPath = typePkg
+      ? collectGoImportAliases(graph.getNode(generateId('File', filePath))?.properties.imports ?? []).get(typePkg)
+      : undefined;
+    const method = typePkg && !typeImportPath ? undefined : defs.find(d => {
+      if (d.type !== 'Method') return false;
+      if (graph.getNode(d.nodeId)?.properties.receiverType !== typeName) return false;
+      const dir = goPackageDir(d.filePath);
+      return typeImportPath ? goImportMatches(goModules, typeImportPath, filePath, dir) : dir === fileDir;
+    });
+    if (method) return { nodeId: method.nodeId, confidence: 0.95, reason: 'receiver-typed' };
+    // A known type without a matching method: promoted through embedding, an
+    // interface method, or a type outside the repo. Never fuzzy-matched.
+    if (typeImportPath) {
+      const isRepoType = symbolTable.lookupFuzzy(typeName).some(d =>
+        GO_TYPE_LABELS.has(d.type) && goImportMatches(goModules, typeImportPath, filePath, goPackageDir(d.filePath)));
+      return isRepoType ? 'unresolved' : externalPackageReason(goModules, isAnalyzedDir, typeImportPath, filePath);
+    }
+    if (!typePkg) {
+      if (GO_PREDECLARED_TYPES.has(typeName)) return 'unresolved';
+      if (symbolTable.lookupFuzzy(typeName).some(d => GO_TYPE_LABELS.has(d.type) && goPackageDir(d.filePath) === fileDir)) {
+        return 'unresolved';
+      }
+    }
+    // The type's package isn't imported or the type isn't declared: unknown
+  }
+
+  if (!call.qualifier) {
+    if (GO_PREDECLARED.has(calledName)) return null;
+    const samePackage = defs.filter(d => goPackageDir(d.filePath) === fileDir);
+    const fn = samePackage.find(d => d.type === 'Function');
+    if (fn) return { nodeId: fn.nodeId, confidence: 0.95, reason: 'same-package' };
+    // `MyType(x)` is a conversion, not a call
+    if (samePackage.some(d => GO_TYPE_LABELS.has(d.type))) return null;
+    // Dot imports make another package's identifiers usable unqualified
+    if (call.dotImports?.length) {
+      const dotFn = defs.find(d =>
+        d.type === 'Function' && call.dotImports!.some(pkg => goImportMatches(goModules, pkg, filePath, goPackageDir(d.filePath))));
+      if (dotFn) return { nodeId: dotFn.nodeId, confidence: 0.85, reason: 'dot-import' };
+      if (defs.length === 0) {
+        return call.dotImports.length === 1
+          ? externalPackageReason(goModules, isAnalyzedDir, call.dotImports[0], filePath)
+          : 'external-package';
+      }
+    }
+    return 'unresolved';
+  }
+
+  return resolveCallTarget(calledName, filePath, symbolTable, importMap) ?? 'unresolved';
+};
+
 /**
  * Filter out common built-in functions and noise
  * that shouldn't be tracked as calls
@@ -390,8 +601,11 @@ export const processCallsFromExtracted = async (
   extractedCalls: ExtractedCall[],
   symbolTable: SymbolTable,
   importMap: ImportMap,
-  onProgress?: (current: number, total: number) => void
+  onProgress?: (current: number, total: number) => void,
+  externalCalls?: ExternalCallMap
 ) => {
+  const goModules = createGoModuleResolver(getGoModules(graph));
+  const isAnalyzedDir = createAnalyzedDirLookup(graph);
   // Group by file for progress reporting
   const byFile = new Map<string, ExtractedCall[]>();
   for (const call of extractedCalls) {
@@ -406,31 +620,19 @@ export const processCallsFromExtracted = async (
   const totalFiles = byFile.size;
   let filesProcessed = 0;
 
-  for (const [_filePath, calls] of byFile) {
+  for (const [filePath, calls] of byFile) {
     filesProcessed++;
     if (filesProcessed % 100 === 0) {
       onProgress?.(filesProcessed, totalFiles);
       await yieldToEventLoop();
     }
 
+    const isGo = getLanguageFromFilename(filePath) === SupportedLanguages.Go;
     for (const call of calls) {
-      const resolved = resolveCallTarget(
-        call.calledName,
-        call.filePath,
-        symbolTable,
-        importMap
-      );
-      if (!resolved) continue;
-
-      const relId = generateId('CALLS', `${call.sourceId}:${call.calledName}->${resolved.nodeId}`);
-      graph.addRelationship({
-        id: relId,
-        sourceId: call.sourceId,
-        targetId: resolved.nodeId,
-        type: 'CALLS',
-        confidence: resolved.confidence,
-        reason: resolved.reason,
-      });
+      const resolved = isGo
+        ? resolveGoCallTarget(call, graph, symbolTable, importMap, goModules, isAnalyzedDir)
+        : resolveCallTarget(call.calledName, call.filePath, symbolTable, importMap);
+      recordCall(graph, call, resolved, externalCalls);
     }
   }
 
@@ -0,0 +1,159 @@
+/**
+ * Doc Comments
+ *
+ * Finds the comment block documenting a definition and returns its text
+ * without comment markers. A doc comment is the run of comments directly
+ * above the declaration (no blank line in between, not trailing other code),
+ * or a Python docstring. Shared by the parse worker and sequential fallback.
+ */
+
+import { SupportedLanguages } from '../../config/supported-languages.js';
+
+const COMMENT_NODE_TYPES = new Set(['comment', 'line_comment', 'block_comment', 'doc_comment']);
+
+/**
+ * Wrappers a declaration can sit in while its doc comment sits outside:
+ * `export function`, Go `type X struct{}` (type_declaration > type_spec),
+ * Python decorators, JS `const f = () => {}`.
+ */
+const DECLARATION_WRAPPERS = new Set([
+  'export_statement', 'decorated_definition',
+  'type_declaration', 'const_declaration', 'var_declaration',
+  'lexical_declaration', 'variable_declaration',
+  'ambient_declaration', 'template_declaration',
+]);
+
+/** Go declarations that may group several specs in parentheses */
+const GROUPED_DECLARATIONS = new Set(['type_declaration', 'const_declaration', 'var_declaration']);
+
+/** Nodes skipped between a doc comment and its declaration (Rust #[attr]) */
+const TRANSPARENT_NODE_TYPES = new Set(['attribute_item']);
+
+/** Go directives (`//go:generate`, `//nolint:x`, `//line`) are not documentation */
+const GO_DIRECTIVE = /^\/\/(line |extern |export |[a-z0-9]+:[a-z0-9])/;
+
+const isComment = (node: any): boolean => COMMENT_NODE_TYPES.has(node?.type);
+
+/** Remove the common leading indentation of non-blank lines */
+const dedent = (lines: string[]): string[] => {
+  const indents = lines
+    .filter(l => l.trim().length > 0)
+    .map(l => l.match(/^[ \t]*/)![0].length);
+  const common = indents.length > 0 ? Math.min(...indents) : 0;
+  return lines.map(l => l.substring(Math.min(common, l.match(/^[ \t]*/)![0].length)));
+};
+
+const trimBlankEdges = (lines: string[]): string[] => {
+  let start = 0;
+  let end = lines.length;
+  while (start < end && lines[start].trim() === '') start++;
+  while (end > start && lines[end - 1].trim() === '') end--;
+  return lines.slice(start, end);
+};
+
+/** Strip markers from one comment's text, returning its lines */
+const stripCommentMarkers = (text: string): string[] => {
+  const trimmed = text.trim();
+  if (trimmed.startsWith('/*')) {
+    const body = trimmed.replace(/^\/\*+!?/, '').replace(/\*+\/$/, '');
+    const lines = body.split('\n').map(l => l.replace(/\s+$/, ''));
+    // Javadoc-style gutters: " * text"
+    const continuation = lines.slice(1).filter(l => l.trim().length > 0);
+    if (continuation.length > 0 && continuation.every(l => /^\s*\*/.test(l))) {
+      return lines.map((l, i) => i === 0 ? l : l.replace(/^\s*\*/, ''));
+    }
+    return lines;
+  }
+  return trimmed.split('\n').map(l => l.trim().replace(/^(\/\/[\/!]?|#+|--)/, '').replace(/\s+$/, ''));
+};
+
+/** Join comment nodes into cleaned doc text; empty string if nothing remains */
+export const formatDocComment = (comments: string[], language?: SupportedLanguages): string => {
+  const lines: string[] = [];
+  for (const text of comments) {
+    if (language === SupportedLanguages.Go && GO_DIRECTIVE.test(text.trim())) continue;
+    lines.push(...stripCommentMarkers(text));
+  }
+  return trimBlankEdges(dedent(lines)).join('\n');
+};
+
+/**
+ * Comments directly above `node` among its siblings: contiguous lines,
+ * ending on the line before the node, excluding a comment that trails the
+ * previous statement on its own line.
+ */
+const collectLeadingComments = (node: any): string[] => {
+  const comments: string[] = [];
+  // Named siblings only — statement terminators (Go `\n`) are anonymous tokens
+  let nextStartRow = node.startPosition.row;
+  let prev = node.previousNamedSibling;
+  while (prev && TRANSPARENT_NODE_TYPES.has(prev.type)) {
+    nextStartRow = prev.startPosition.row;
+    prev = prev.previousNamedSibling;
+  }
+  while (prev && isComment(prev)) {
+    if (prev.endPosition.row < nextStartRow - 1) break;
+    const before = prev.previousNamedSibling;
+    if (before && !isComment(before) && before.endPosition.row === prev.startPosition.row) break;
+    comments.unshift(prev.text);
+    nextStartRow = prev.startPosition.row;
+    prev = before;
+  }
+  return comments;
+};
+
+/** First statement of a Python def/class body, if it's a string literal */
+const extractPythonDocstring = (decl: any): string | undefined => {
+  if (decl.type !== 'function_definition' && decl.type !== 'class_definition') return undefined;
+  const first = decl.childForFieldName?.('body')?.namedChildren?.[0];
+  const str = first?.type === 'expression_statement' ? first.namedChildren?.[0] : undefined;
+  if (str?.type !== 'string') return undefined;
+  const body = str.text.replace(/^[rRuUbB]*("""|'''|"|')/, '').replace(/("""|'''|"|')$/, '');
+  const lines = body.split('\n');
+  // PEP 257: the first line carries no indentation of its own
+  const doc = trimBlankEdges([lines[0].trim(), ...dedent(lines.slice(1))]).join('\n');
+  return doc || undefined;
+};
+
+/**
+ * Doc comment for a captured definition.
+ *
+ * @param nameNode - The @name capture; the walk starts at its declaration
+ */
+export const extractDocComment = (nameNode: any, language: SupportedLanguages): string | undefined => {
+  let current = nameNode?.parent;
+  if (!current) return undefined;
+
+  if (language === SupportedLanguages.Python) {
+    const docstring = extractPythonDocstring(current);
+    if (docstring) return docstring;
+  }
+
+  // Walk out through wrappers until a comment run is found
+  for (let depth = 0; current && depth < 4; depth++) {
+    const comments = collectLeadingComments(current);
+    if (comments.length > 0) {
+      const doc = formatDocComment(comments, language);
+      return doc || undefined;
+    }
+    const parent = current.parent;
+    if (!parent || !DECLARATION_WRAPPERS.has(parent.type)) break;
+    // Only the first spec of a grouped declaration inherits the group's comment
+    if (GROUPED_DECLARATIONS.has(parent.type)) {
+      const firstSpec = parent.namedChildren?.find((c: any) => !isComment(c));
+      if (firstSpec && firstSpec.startIndex !== current.startIndex) break;
+    }
+    current = parent;
+  }
+  return undefined;
+};
+
+/**
+ * Comment on the same line after `node` (`Name string // display name`).
+ */
+export const extractTrailingComment = (node: any, language: SupportedLanguages): string | undefined => {
+  const next = node.nextNamedSibling;
+  if (!next || !isComment(next) || next.startPosition.row !== node.endPosition.row) return undefined;
+  const doc = formatDocComment([next.text], language);
+  return doc || undefined;
+};
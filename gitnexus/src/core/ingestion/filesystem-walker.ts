@@ -1,7 +1,13 @@
 import fs from 'fs/promises';
 import path from 'path';
 import { glob } from 'glob';
-import { shouldIgnorePath } from '../../config/ignore-service.js';
+import { shouldIgnorePath, createPathFilter, isGeneratedSource, GENERATED_HEADER_SCAN_BYTES } from '../../config/ignore-service.js';
+import { getIgnoredPaths } from '../../storage/git.js';
+import { VirtualFS, createCommitFS, joinFSPath } from './virtual-fs.js';
+import {
+  BuildConstraintOptions, BuildContext, createBuildContext, describeBuildContext, isGoSourceFile,
+  matchBuildFileName, matchBuildHeader, parseBuildHeader,
+} from './build-constraints.js';
 
 export interface FileEntry {
   path: string;
@@ -19,18 +25,52 @@ export interface FilePath {
   path: string;
 }
 
+/**
+ * User-level scan filters, applied on top of the built-in ignore rules.
+ * Order: built-in rules, .gitignore, include/exclude globs (exclude wins,
+ * see createPathFilter), then the generated-file check, then Go build
+ * constraints.
+ */
+export interface ScanOptions {
+  /** Honor .gitignore / .git/info/exclude for working-tree scans (default true) */
+  gitignore?: boolean;
+  /** Globs to keep; everything else is dropped (see globToRegExp) */
+  include?: string[];
+  /** Globs to drop, e.g. `*_gen.go`, `vendor/**` */
+  exclude?: string[];
+  /** Drop files with a `// Code generated ... DO NOT EDIT.` header */
+  skipGenerated?: boolean;
+  /** Target platform and tags for Go build constraints; the host's when unset (see build-constraints) */
+  build?: BuildConstraintOptions;
+}
+
 const READ_CONCURRENCY = 32;
 
+/** Files read per `git cat-file` call when checking commits for generated files and build constraints */
+const CONTENT_CHECK_BATCH = 500;
+
 /** Skip files larger than 512KB — they're usually generated/vendored and crash tree-sitter */
 const MAX_FILE_SIZE = 512 * 1024;
 
+/** Bytes read first when looking for a build constraint; the whole file if the header runs longer */
+const BUILD_HEADER_SCAN_BYTES = 4096;
+
+/** The build context a scan filters Go files by, or null when every variant is kept */
+const scanBuildContext = (options: ScanOptions): BuildContext | null =>
+  options.build?.allVariants ? null : createBuildContext(options.build);
+
+const warnSkippedByConstraints = (count: number, ctx: BuildContext): void => {
+  if (count > 0) console.warn(`  Skipped ${count} Go files excluded by build constraints for ${describeBuildContext(ctx)}`);
+};
+
 /**
  * Phase 1: Scan repository — stat files to get paths + sizes, no content loaded.
  * Memory: ~10MB for 100K files vs ~1GB+ with content.
  */
 export const walkRepositoryPaths = async (
   repoPath: string,
-  onProgress?: (current: number, total: number, filePath: string) => void
+  onProgress?: (current: number, total: number, filePath: string) => void,
+  options: ScanOptions = {},
 ): Promise<ScannedFile[]> => {
   const files = await glob('**/*', {
     cwd: repoPath,
@@ -38,10 +78,34 @@ export const walkRepositoryPaths = async (
     dot: false,
   });
 
-  const filtered = files.filter(file => !shouldIgnorePath(file));
+  return filterRepositoryPaths(repoPath, files.map(file => file.replace(/\\/g, '/')), onProgress, options);
+};
+
+/**
+ * The scan's filters for given working-tree paths: dot-files, built-in
+ * rules, .gitignore, include/exclude, the size limit and generated files.
+ * Paths that don't exist or aren't regular files are dropped too.
+ */
+export const filterRepositoryPaths = async (
+  repoPath: string,
+  relativePaths: string[],
+  onProgress?: (current: number, total: number, filePath: string) => void,
+  options: ScanOptions = {},
+): Promise<ScannedFile[]> => {
+  const userFilter = createPathFilter(options);
+  const buildContext = scanBuildContext(options);
+  let filtered = relativePaths
+    .filter(file => !file.split('/').some(part => part.startsWith('.')))
+    .filter(file => !shouldIgnorePath(file) && userFilter(file));
+  if (options.gitignore !== false) {
+    const ignored = getIgnoredPaths(repoPath, filtered);
+    if (ignored.size > 0) filtered = filtered.filter(file => !ignored.has(file));
+  }
   const entries: ScannedFile[] = [];
   let processed = 0;
   let skippedLarge = 0;
+  let skippedGenerated = 0;
+  let skippedConstrained = 0;
 
   for (let start = 0; start < filtered.length; start += READ_CONCURRENCY) {
     const batch = filtered.slice(start, start + READ_CONCURRENCY);
@@ -49,11 +113,20 @@ export const walkRepositoryPaths = async (
       batch.map(async relativePath => {
         const fullPath = path.join(repoPath, relativePath);
         const stat = await fs.stat(fullPath);
+        if (!stat.isFile()) return null;
         if (stat.size > MAX_FILE_SIZE) {
           skippedLarge++;
           return null;
         }
-        return { path: relativePath.replace(/\\/g, '/'), size: stat.size };
+        if (options.skipGenerated && await isGeneratedFile(fullPath)) {
+          skippedGenerated++;
+          return null;
+        }
+        if (buildContext && isGoSourceFile(relativePath) && !await matchesBuildContext(fullPath, relativePath, buildContext)) {
+          skippedConstrained++;
+          return null;
+        }
+        return { path: relativePath, size: stat.size };
       })
     );
 
@@ -71,10 +144,43 @@ export const walkRepositoryPaths = async (
   if (skippedLarge > 0) {
     console.warn(`  Skipped ${skippedLarge} large files (>${MAX_FILE_SIZE / 1024}KB, likely generated/vendored)`);
   }
+  if (skippedGenerated > 0) {
+    console.warn(`  Skipped ${skippedGenerated} generated files`);
+  }
+  if (buildContext) warnSkippedByConstraints(skippedConstrained, buildContext);
 
   return entries;
 };
 
+/** Reads only the head of the file — enough for the generated-code marker */
+const isGeneratedFile = async (fullPath: string): Promise<boolean> => {
+  const handle = await fs.open(fullPath, 'r');
+  try {
+    const buffer = Buffer.alloc(GENERATED_HEADER_SCAN_BYTES);
+    const { bytesRead } = await handle.read(buffer, 0, buffer.length, 0);
+    return isGeneratedSource(buffer.subarray(0, bytesRead).toString('utf-8'));
+  } finally {
+    await handle.close();
+  }
+};
+
+/** Is the Go file part of the build? Reads the head of the file, or all of it when the header is longer */
+const matchesBuildContext = async (fullPath: string, relativePath: string, ctx: BuildContext): Promise<boolean> => {
+  if (!matchBuildFileName(relativePath, ctx)) return false;
+  const handle = await fs.open(fullPath, 'r');
+  try {
+    const buffer = Buffer.alloc(BUILD_HEADER_SCAN_BYTES);
+    const { bytesRead } = await handle.read(buffer, 0, buffer.length, 0);
+    let header = parseBuildHeader(buffer.subarray(0, bytesRead).toString('utf-8'));
+    if (!header.complete && bytesRead === buffer.length) {
+      header = parseBuildHeader(await fs.readFile(fullPath, 'utf-8'));
+    }
+    return matchBuildHeader(header, ctx);
+  } finally {
+    await handle.close();
+  }
+};
+
 /**
  * Phase 2: Read file contents for a specific set of relative paths.
  * Returns a Map for O(1) lookup. Silently skips files that fail to read.
@@ -119,3 +225,140 @@ export const walkRepository = async (
     .filter(f => contents.has(f.path))
     .map(f => ({ path: f.path, content: contents.get(f.path)! }));
 };
+
+// ============================================================================
+// FILE SOURCES
+// ============================================================================
+
+/**
+ * Where the pipeline reads repository files from: the working tree, a
+ * commit in the git object store (no checkout needed), or any VirtualFS.
+ * Paths are always slash-separated, relative to the repository root.
+ */
+export interface FileSource {
+  /** Paths + sizes, with ignore rules and the size limit applied */
+  scan: (onProgress?: (current: number, total: number, filePath: string) => void) => Promise<ScannedFile[]>;
+  /** Contents for relative paths; unreadable or missing paths are left out */
+  read: (relativePaths: string[]) => Promise<Map<string, string>>;
+  /** One file's content, or null if it doesn't exist */
+  readFile: (relativePath: string) => Promise<string | null>;
+}
+
+export const createWorkingTreeSource = (repoPath: string, options: ScanOptions = {}): FileSource => ({
+  scan: (onProgress) => walkRepositoryPaths(repoPath, onProgress, options),
+  read: (relativePaths) => readFileContents(repoPath, relativePaths),
+  readFile: async (relativePath) => {
+    try {
+      return await fs.readFile(path.join(repoPath, relativePath), 'utf-8');
+    } catch {
+      return null;
+    }
+  },
+});
+
+/**
+ * Files as of `commit`, read with `git ls-tree` / `git cat-file` (see
+ * createCommitFS). The working tree is never touched, so this works on bare
+ * repositories and for paths that have since been deleted. Only committed
+ * files are listed, so .gitignore needs no separate check.
+ */
+export const createCommitSource = (repoPath: string, commit: string, options: ScanOptions = {}): FileSource =>
+  createFSSource(createCommitFS(repoPath, commit), options);
+
+const toText = (content: string | Buffer): string => (typeof content === 'string' ? content : content.toString('utf-8'));
+
+/**
+ * Files of a VirtualFS (zip archive, in-memory map, ...). Dot-files and
+ * dot-directories are skipped like in working-tree scans, but there is no
+ * .gitignore to honor, so `gitignore` has no effect. Sizes the FS doesn't
+ * report are taken from the content, which is then read once at scan time,
+ * as are Go files for their build constraints.
+ */
+export const createFSSource = (fsys: VirtualFS, options: ScanOptions = {}): FileSource => {
+  const readText = async (relativePath: string): Promise<string | null> => {
+    try {
+      return toText(await fsys.readFile(relativePath));
+    } catch {
+      return null;
+    }
+  };
+
+  const read = async (relativePaths: string[]): Promise<Map<string, string>> => {
+    if (fsys.readFiles) return fsys.readFiles(relativePaths);
+    const contents = new Map<string, string>();
+    for (let start = 0; start < relativePaths.length; start += READ_CONCURRENCY) {
+      const batch = relativePaths.slice(start, start + READ_CONCURRENCY);
+      const results = await Promise.all(batch.map(readText));
+      results.forEach((content, i) => {
+        if (content !== null) contents.set(batch[i], content);
+      });
+    }
+    return contents;
+  };
+
+  const listFiles = async (dir: string, out: { path: string; size?: number }[]): Promise<void> => {
+    for (const entry of await fsys.readDir(dir)) {
+      if (entry.name.startsWith('.')) continue;
+      const entryPath = joinFSPath(dir, entry.name);
+      if (entry.isDirectory) await listFiles(entryPath, out);
+      else out.push({ path: entryPath, ...(entry.size !== undefined ? { size: entry.size } : {}) });
+    }
+  };
+
+  return {
+    scan: async (onProgress) => {
+      const userFilter = createPathFilter(options);
+      const buildContext = scanBuildContext(options);
+      const listed: { path: string; size?: number }[] = [];
+      await listFiles('.', listed);
+      const files = listed.filter(file => !shouldIgnorePath(file.path) && userFilter(file.path));
+
+      let entries: ScannedFile[] = [];
+      let skippedLarge = 0;
+      for (let start = 0; start < files.length; start += READ_CONCURRENCY) {
+        const batch = files.slice(start, start + READ_CONCURRENCY);
+        const sizes = await Promise.all(batch.map(async file => {
+          if (file.size !== undefined) return file.size;
+          const content = await readText(file.path);
+          return content === null ? null : Buffer.byteLength(content);
+        }));
+        batch.forEach((file, i) => {
+          const size = sizes[i];
+          if (size !== null && size > MAX_FILE_SIZE) skippedLarge++;
+          else if (size !== null) entries.push({ path: file.path, size });
+          onProgress?.(start + i + 1, files.length, file.path);
+        });
+      }
+      if (skippedLarge > 0) {
+        console.warn(`  Skipped ${skippedLarge} large files (>${MAX_FILE_SIZE / 1024}KB, likely generated/vendored)`);
+      }
+      if (options.skipGenerated) {
+        const generated = new Set<string>();
+        for (let start = 0; start < entries.length; start += CONTENT_CHECK_BATCH) {
+          const batch = entries.slice(start, start + CONTENT_CHECK_BATCH).map(e => e.path);
+          for (const [p, content] of await read(batch)) {
+            if (isGeneratedSource(content)) generated.add(p);
+          }
+        }
+        if (generated.size > 0) {
+          entries = entries.filter(e => !generated.has(e.path));
+          console.warn(`  Skipped ${generated.size} generated files`);
+        }
+      }
+      if (buildContext) {
+        const excluded = new Set(entries.filter(e => isGoSourceFile(e.path) && !matchBuildFileName(e.path, buildContext)).map(e => e.path));
+        const goPaths = entries.filter(e => isGoSourceFile(e.path) && !excluded.has(e.path)).map(e => e.path);
+        for (let start = 0; start < goPaths.length; start += CONTENT_CHECK_BATCH) {
+          for (const [p, content] of await read(goPaths.slice(start, start + CONTENT_CHECK_BATCH))) {
+            if (!matchBuildHeader(parseBuildHeader(content), buildContext)) excluded.add(p);
+          }
+        }
+        if (excluded.size > 0) entries = entries.filter(e => !excluded.has(e.path));
+        warnSkippedByConstraints(excluded.size, buildContext);
+      }
+      return entries;
+    },
+    read,
+    readFile: readText,
+  };
+};
@@ -0,0 +1,663 @@
+/**
+ * Go Interface Processor
+ *
+ * Go interfaces compose by embedding (`type ReadCloser interface { Reader; Closer }`),
+ * and the embedded interface is often declared in another file. Parsing records
+ * each interface's own methods + embedded names; this pass resolves the embeds
+ * across the whole graph and materialises:
+ * - methodSet: the flattened, sorted method signatures of every Go interface
+ * - EXTENDS: interface -> embedded interface, when the embed is in the repo
+ *
+ * Go satisfaction is structural, so a second pass (processGoImplementations)
+ * matches concrete types' method sets against those interface method sets
+ * and emits IMPLEMENTS edges. Those method sets follow the spec's selector
+ * rules for promotion through embedded fields; getGoMethodSet exposes them
+ * per type, including where each method comes from.
+ */
+
+import { KnowledgeGraph, GraphNode, FileImport } from '../graph/types.js';
+import { getGoModules, createGoModuleResolver } from '../graph/modules.js';
+import { generateId, goPackageDir } from '../../lib/utils.js';
+
+/**
+ * Method sets of standard-library interfaces that are commonly embedded.
+ * Lets `interface { io.Reader; io.Closer }` flatten without the stdlib source.
+ */
+export const GO_STDLIB_INTERFACES: Record<string, string[]> = {
+  'error': ['Error() string'],
+  'any': [],
+  'fmt.Stringer': ['String() string'],
+  'io.Reader': ['Read([]byte) (int, error)'],
+  'io.Writer': ['Write([]byte) (int, error)'],
+  'io.Closer': ['Close() error'],
+  'io.Seeker': ['Seek(int64, int) (int64, error)'],
+  'io.ReaderAt': ['ReadAt([]byte, int64) (int, error)'],
+  'io.WriterAt': ['WriteAt([]byte, int64) (int, error)'],
+  'io.ByteReader': ['ReadByte() (byte, error)'],
+  'io.ByteWriter': ['WriteByte(byte) error'],
+  'io.StringWriter': ['WriteString(string) (int, error)'],
+  'io.ReadWriter': ['Read([]byte) (int, error)', 'Write([]byte) (int, error)'],
+  'io.ReadCloser': ['Read([]byte) (int, error)', 'Close() error'],
+  'io.WriteCloser': ['Write([]byte) (int, error)', 'Close() error'],
+  'io.ReadWriteCloser': ['Read([]byte) (int, error)', 'Write([]byte) (int, error)', 'Close() error'],
+  'sort.Interface': ['Len() int', 'Less(int, int) bool', 'Swap(int, int)'],
+  'context.Context': [
+    'Deadline() (time.Time, bool)', 'Done() <-chan struct{}', 'Err() error', 'Value(any) any',
+  ],
+  'http.Handler': ['ServeHTTP(http.ResponseWriter, *http.Request)'],
+  'json.Marshaler': ['MarshalJSON() ([]byte, error)'],
+  'json.Unmarshaler': ['UnmarshalJSON([]byte) error'],
+};
+
+const isGoInterface = (node: GraphNode): boolean =>
+  node.label === 'Interface' && node.properties.language === 'go';
+
+/** What an embed names: a repo interface, a known standard-library one (its methods), or neither */
+export type GoEmbedResolution = { node: GraphNode } | { stdlib: string[] } | null;
+
+/**
+ * Build a resolver from an embedded type reference to the interface it
+ * names. Unqualified names resolve within the same package (directory),
+ * else to a predeclared interface (`error`, `any`). Qualified `pkg.Name`
+ * resolves through the embedding file's import of `pkg`: to the repo
+ * directory the import path names (exact with go.mod files, by path shape
+ * otherwise, like definition.ts), or, for an import from outside the repo,
+ * to GO_STDLIB_INTERFACES. Generic embeds (`Container[T]`) resolve by their
+ * base name.
+ */
+/** Where a file's package qualifier points: a repo directory, an import from outside the repo, or nothing imported */
+type GoQualifierTarget = { dir: string } | { externalPath: string } | null;
+
+/**
+ * Resolve `pkg` in `pkg.Name` through the file's imports: exact with go.mod
+ * files, by path shape otherwise (like definition.ts).
+ */
+const createGoQualifierResolver = (graph: KnowledgeGraph) => {
+  const goDirs = new Set<string>();
+  graph.forEachNode(node => {
+    if (node.label === 'File' && node.properties.filePath.endsWith('.go')) goDirs.add(goPackageDir(node.properties.filePath));
+  });
+  const goModules = createGoModuleResolver(getGoModules(graph));
+
+  const packageDir = (importPath: string, fromFile: string): string | null => {
+    if (goModules.modules.length > 0) return goModules.resolveImportDir(importPath, fromFile);
+    // Standard library paths have no dot in their first element
+    if (!importPath.split('/')[0].includes('.')) return null;
+    const dirs = [...goDirs].filter(d => d !== '' && (importPath === d || importPath.endsWith('/' + d)));
+    return dirs.sort((a, b) => b.length - a.length)[0] ?? null;
+  };
+
+  return (pkg: string, fromFile: string): GoQualifierTarget => {
+    const imports: FileImport[] = graph.getNode(generateId('File', fromFile))?.properties.imports ?? [];
+    const imp = imports.find(i => i.localName === pkg && i.kind !== 'dot' && i.kind !== 'blank');
+    if (!imp) return null;
+    const dir = packageDir(imp.path, fromFile);
+    return dir === null ? { externalPath: imp.path } : { dir };
+  };
+};
+
+export const createGoInterfaceResolver = (graph: KnowledgeGraph) => {
+  const byName = new Map<string, GraphNode[]>();
+  graph.forEachNode(node => {
+    if (!isGoInterface(node)) return;
+    let list = byName.get(node.properties.name);
+    if (!list) {
+      list = [];
+      byName.set(node.properties.name, list);
+    }
+    list.push(node);
+  });
+  const resolveQualifier = createGoQualifierResolver(graph);
+
+  return (embed: string, fromFile: string): GoEmbedResolution => {
+    const base = embed.replace(/\[.*\]$/, '');
+    const dotIdx = base.lastIndexOf('.');
+    const pkg = dotIdx >= 0 ? base.substring(0, dotIdx) : '';
+    const name = dotIdx >= 0 ? base.substring(dotIdx + 1) : base;
+    const candidates = byName.get(name) ?? [];
+
+    if (!pkg) {
+      const fromDir = goPackageDir(fromFile);
+      const node = candidates.find(c => goPackageDir(c.properties.filePath) === fromDir);
+      if (node) return { node };
+      const stdlib = GO_STDLIB_INTERFACES[name];
+      return stdlib ? { stdlib } : null;
+    }
+    const target = resolveQualifier(pkg, fromFile);
+    if (!target) return null;
+    if ('externalPath' in target) {
+      // Keyed by package name: `encoding/json` -> `json.Marshaler`
+      const path = target.externalPath;
+      const stdlib = GO_STDLIB_INTERFACES[`${path.substring(path.lastIndexOf('/') + 1)}.${name}`];
+      return stdlib ? { stdlib } : null;
+    }
+    const node = candidates.find(c => goPackageDir(c.properties.filePath) === target.dir);
+    return node ? { node } : null;
+  };
+};
+
+/**
+ * Compute flattened method sets for all Go interfaces and link embeds.
+ * Embedding cycles (invalid Go, but possible mid-edit) are cut rather than looped.
+ */
+export const processGoInterfaces = (graph: KnowledgeGraph): void => {
+  const resolve = createGoInterfaceResolver(graph);
+  const memo = new Map<string, string[]>();
+
+  const flatten = (node: GraphNode, visiting: Set<string>): string[] => {
+    const cached = memo.get(node.id);
+    if (cached) return cached;
+    if (visiting.has(node.id)) return [];
+    visiting.add(node.id);
+
+    const methods = new Set<string>(node.properties.methodSignatures ?? []);
+    for (const embed of (node.properties.embeddedTypes ?? [])) {
+      const target = resolve(embed, node.properties.filePath);
+      if (!target) continue;
+      const embedded = 'node' in target ? flatten(target.node, visiting) : target.stdlib;
+      for (const m of embedded) methods.add(m);
+    }
+
+    visiting.delete(node.id);
+    const result = [...methods].sort();
+    memo.set(node.id, result);
+    return result;
+  };
+
+  graph.forEachNode(node => {
+    if (!isGoInterface(node)) return;
+
+    const methodSet = flatten(node, new Set());
+    node.properties.methodSet = methodSet;
+    if (!node.properties.description) {
+      node.properties.description = methodSet.length > 0
+        ? `methods: ${methodSet.join('; ')}`
+        : 'empty interface';
+    }
+
+    for (const embed of (node.properties.embeddedTypes ?? [])) {
+      const resolved = resolve(embed, node.properties.filePath);
+      const target = resolved && 'node' in resolved ? resolved.node : null;
+      if (!target || target.id === node.id) continue;
+      graph.addRelationship({
+        id: generateId('EXTENDS', `${node.id}->${target.id}`),
+        sourceId: node.id,
+        targetId: target.id,
+        type: 'EXTENDS',
+        confidence: 1.0,
+        reason: 'go-embed',
+      });
+    }
+  });
+};
+
+// ============================================================================
+// METHOD SETS
+// ============================================================================
+
+/** A method callable on a Go type, and how it got there */
+export interface GoMethodSetEntry {
+  name: string;
+  /** `Name(params) results`, as in methodSignatures */
+  signature: string;
+  /** Declared on the type itself, or promoted from an embedded field */
+  origin: 'declared' | 'promoted';
+  /** Embedded fields leading to the method's owner (`['Base', 'Logger']`); empty when declared */
+  via: string[];
+  /** Method node, when the method is declared in the repo on a concrete type */
+  methodId?: string;
+  /** Type declaring the method (a struct or defined type, or an interface) */
+  ownerId?: string;
+  ownerName: string;
+  pointerReceiver: boolean;
+  /** In the method set of T; every entry is in the method set of *T */
+  inValueSet: boolean;
+}
+
+/** A method name promoted from several embedded fields at the same depth; selecting it doesn't compile */
+export interface GoAmbiguousSelector {
+  name: string;
+  /** Embedded fields between the type and the colliding declarations */
+  depth: number;
+  candidates: { via: string[]; ownerName: string; kind: 'method' | 'field'; signature?: string }[];
+}
+
+export interface GoMethodSet {
+  typeId: string;
+  typeName: string;
+  /** Callable methods, sorted by name */
+  methods: GoMethodSetEntry[];
+  /** Names left out of `methods` because their promotion is ambiguous */
+  ambiguous: GoAmbiguousSelector[];
+  /** Embedded types outside the repo, whose methods can't be listed (`{ via: ['Mutex'], type: 'sync.Mutex' }`) */
+  unresolvedEmbeds: { via: string[]; type: string }[];
+}
+
+const typeKey = (dir: string, name: string) => `${dir}\0${name}`;
+
+/** Field name of an embedded type: `*log.Logger` -> `Logger`, `List[T]` -> `List` */
+const embeddedFieldName = (embed: string): string => {
+  const base = embed.replace(/^\*/, '').replace(/\[.*\]$/, '');
+  return base.substring(base.lastIndexOf('.') + 1);
+};
+
+const methodName = (signature: string): string => signature.substring(0, signature.indexOf('(')).trim();
+
+/**
+ * Named Go types by package directory + name, with the methods declared on
+ * them (in any file of the package), and resolvers for embedded type names.
+ */
+const createGoTypeIndex = (graph: KnowledgeGraph) => {
+  const resolveInterface = createGoInterfaceResolver(graph);
+  const types = new Map<string, GraphNode>();
+  const aliases: GraphNode[] = [];
+  const ownMethods = new Map<string, GraphNode[]>();
+
+  graph.forEachNode(node => {
+    if (node.properties.language !== 'go') return;
+    const { filePath } = node.properties;
+    if (node.label === 'Method' && node.properties.receiverType && node.properties.signature) {
+      const key = typeKey(goPackageDir(filePath), node.properties.receiverType);
+      const list = ownMethods.get(key);
+      if (list) list.push(node);
+      else ownMethods.set(key, [node]);
+    }
+    if (node.label !== 'Struct' && node.label !== 'TypeAlias') return;
+    // An alias names an existing type rather than declaring one
+    if (node.properties.isAlias) {
+      aliases.push(node);
+      return;
+    }
+    types.set(typeKey(goPackageDir(filePath), node.properties.name), node);
+  });
+
+  const resolveQualifier = createGoQualifierResolver(graph);
+  /** Key of the repo type `embed` names in `fromFile` */
+  const resolveType = (embed: string, fromFile: string): string | undefined => {
+    const base = embed.replace(/\[.*\]$/, '');
+    const dotIdx = base.lastIndexOf('.');
+    if (dotIdx < 0) {
+      const key = typeKey(goPackageDir(fromFile), base);
+      return types.has(key) ? key : undefined;
+    }
+    const target = resolveQualifier(base.substring(0, dotIdx), fromFile);
+    if (!target || !('dir' in target)) return undefined;
+    const key = typeKey(target.dir, base.substring(dotIdx + 1));
+    return types.has(key) ? key : undefined;
+  };
+
+  // Methods declared on an alias receiver belong to the aliased type
+  for (const alias of aliases) {
+    const dir = goPackageDir(alias.properties.filePath);
+    const own = ownMethods.get(typeKey(dir, alias.properties.name));
+    const targetKey = alias.properties.underlyingType?.startsWith('*') ? undefined
+      : resolveType(alias.properties.underlyingType ?? '', alias.properties.filePath);
+    if (!own || !targetKey) continue;
+    ownMethods.set(targetKey, [...(ownMethods.get(targetKey) ?? []), ...own]);
+  }
+
+  return { types, ownMethods, resolveType, resolveInterface };
+};
+
+type GoTypeIndex = ReturnType<typeof createGoTypeIndex>;
+
+interface EmbedLevelEntry {
+  /** A repo type (key into the index) or an interface's flattened methods */
+  key?: string;
+  iface?: { id?: string; name: string; methods: string[] };
+  via: string[];
+  /** Reached through a pointer embed somewhere on the path */
+  indirect: boolean;
+  /** Reached more than once at this depth, so all its names collide */
+  multiples: boolean;
+}
+
+interface SelectorCandidate {
+  kind: 'method' | 'field';
+  entry: GoMethodSetEntry;
+  multiples: boolean;
+}
+
+/**
+ * Method set of a concrete named type, by the selector rules of the spec
+ * (as in go/types NewMethodSet): embedded fields are searched breadth
+ * first, a name at a shallower depth (method or field) shadows deeper ones,
+ * and a name found more than once at its shallowest depth is ambiguous.
+ * A promoted pointer-receiver method is in T's method set only when the
+ * path to it goes through a pointer embed; interface methods always are.
+ */
+const computeGoMethodSet = (index: GoTypeIndex, key: string): GoMethodSet => {
+  const node = index.types.get(key)!;
+  const methods: GoMethodSetEntry[] = [];
+  const ambiguous: GoAmbiguousSelector[] = [];
+  const unresolvedEmbeds: GoMethodSet['unresolvedEmbeds'] = [];
+  const decided = new Set<string>();
+  const seen = new Set<string>();
+
+  let level: EmbedLevelEntry[] = [{ key, via: [], indirect: false, multiples: false }];
+  for (let depth = 0; level.length > 0; depth++) {
+    const found = new Map<string, SelectorCandidate[]>();
+    const add = (name: string, candidate: SelectorCandidate) => {
+      const list = found.get(name);
+      if (list) list.push(candidate);
+      else found.set(name, [candidate]);
+    };
+    const next: EmbedLevelEntry[] = [];
+
+    for (const entry of level) {
+      const origin = depth === 0 ? 'declared' : 'promoted';
+      if (entry.iface) {
+        if (seen.has(`iface\0${entry.iface.id ?? entry.iface.name}`)) continue;
+        seen.add(`iface\0${entry.iface.id ?? entry.iface.name}`);
+        for (const signature of entry.iface.methods) {
+          add(methodName(signature), {
+            kind: 'method',
+            multiples: entry.multiples,
+            entry: {
+              name: methodName(signature), signature, origin, via: entry.via,
+              ...(entry.iface.id ? { ownerId: entry.iface.id } : {}),
+              ownerName: entry.iface.name, pointerReceiver: false, inValueSet: true,
+            },
+          });
+        }
+        continue;
+      }
+
+      if (seen.has(entry.key!)) continue;
+      seen.add(entry.key!);
+      const owner = index.types.get(entry.key!)!;
+      for (const method of index.ownMethods.get(entry.key!) ?? []) {
+        const pointerReceiver = method.properties.receiverPointer === true;
+        add(method.properties.name, {
+          kind: 'method',
+          multiples: entry.multiples,
+          entry: {
+            name: method.properties.name, signature: method.properties.signature!, origin, via: entry.via,
+            methodId: method.id, ownerId: owner.id, ownerName: owner.properties.name,
+            pointerReceiver, inValueSet: !pointerReceiver || entry.indirect,
+          },
+        });
+      }
+      for (const field of owner.properties.fields ?? []) {
+        if (field.embedded) continue;
+        add(field.name, {
+          kind: 'field',
+          multiples: entry.multiples,
+          entry: {
+            name: field.name, signature: field.typeString, origin, via: entry.via,
+            ownerId: owner.id, ownerName: owner.properties.name, pointerReceiver: false, inValueSet: true,
+          },
+        });
+      }
+
+      for (const rawEmbed of (owner.properties.embeddedTypes ?? [])) {
+        const embed = rawEmbed.replace(/^\*/, '');
+        const fieldName = embeddedFieldName(rawEmbed);
+        // The embedded field itself is a field of this type
+        add(fieldName, {
+          kind: 'field',
+          multiples: entry.multiples,
+          entry: {
+            name: fieldName, signature: rawEmbed, origin, via: entry.via,
+            ownerId: owner.id, ownerName: owner.properties.name, pointerReceiver: false, inValueSet: true,
+          },
+        });
+        const via = [...entry.via, fieldName];
+        const indirect = entry.indirect || rawEmbed.startsWith('*');
+        const resolvedIface = index.resolveInterface(embed, owner.properties.filePath);
+        const iface = resolvedIface && 'node' in resolvedIface ? resolvedIface.node : undefined;
+        const ifaceMethods = iface ? iface.properties.methodSet : resolvedIface && 'stdlib' in resolvedIface ? resolvedIface.stdlib : undefined;
+        if (ifaceMethods) {
+          next.push({
+            iface: { ...(iface ? { id: iface.id } : {}), name: iface?.properties.name ?? embed, methods: ifaceMethods },
+            via, indirect, multiples: entry.multiples,
+          });
+          continue;
+        }
+        const embeddedKey = index.resolveType(embed, owner.properties.filePath);
+        if (embeddedKey) next.push({ key: embeddedKey, via, indirect, multiples: entry.multiples });
+        else unresolvedEmbeds.push({ via, type: rawEmbed });
+      }
+    }
+
+    for (const [name, candidates] of found) {
+      if (decided.has(name)) continue;
+      decided.add(name);
+      const isAmbiguous = candidates.length > 1 || candidates[0].multiples;
+      // Colliding fields only matter here for hiding methods
+      if (isAmbiguous && candidates.some(c => c.kind === 'method')) {
+        ambiguous.push({
+          name,
+          depth,
+          candidates: candidates.map(c => ({
+            via: c.entry.via,
+            ownerName: c.entry.ownerName,
+            kind: c.kind,
+            ...(c.kind === 'method' ? { signature: c.entry.signature } : {}),
+          })),
+        });
+      } else if (!isAmbiguous && candidates[0].kind === 'method') {
+        methods.push(candidates[0].entry);
+      }
+    }
+
+    // A type reached along several paths at the next depth collides with itself
+    const counts = new Map<string, number>();
+    const levelKey = (e: EmbedLevelEntry) => e.key ?? `iface\0${e.iface!.id ?? e.iface!.name}`;
+    for (const e of next) counts.set(levelKey(e), (counts.get(levelKey(e)) ?? 0) + 1);
+    const merged = new Map<string, EmbedLevelEntry>();
+    for (const e of next) {
+      const k = levelKey(e);
+      if (merged.has(k)) continue;
+      merged.set(k, counts.get(k)! > 1 ? { ...e, multiples: true } : e);
+    }
+    level = [...merged.values()];
+  }
+
+  const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+  return {
+    typeId: node.id,
+    typeName: node.properties.name,
+    methods: methods.sort((a, b) => cmp(a.name, b.name)),
+    ambiguous: ambiguous.sort((a, b) => cmp(a.name, b.name)),
+    unresolvedEmbeds,
+  };
+};
+
+/** Method set of an interface: its flattened methods, tagged with the embed they came from */
+const interfaceMethodSet = (graph: KnowledgeGraph, iface: GraphNode): GoMethodSet => {
+  const resolve = createGoInterfaceResolver(graph);
+  const own = new Set(iface.properties.methodSignatures ?? []);
+  const viaEmbed = new Map<string, { embed: string; owner?: GraphNode }>();
+  const unresolvedEmbeds: GoMethodSet['unresolvedEmbeds'] = [];
+  for (const embed of iface.properties.embeddedTypes ?? []) {
+    const resolved = resolve(embed, iface.properties.filePath);
+    const target = resolved && 'node' in resolved ? resolved.node : undefined;
+    const embedded = target ? target.properties.methodSet : resolved && 'stdlib' in resolved ? resolved.stdlib : undefined;
+    if (!embedded) {
+      unresolvedEmbeds.push({ via: [embeddedFieldName(embed)], type: embed });
+      continue;
+    }
+    for (const m of embedded) if (!viaEmbed.has(m)) viaEmbed.set(m, { embed, ...(target ? { owner: target } : {}) });
+  }
+
+  const methods = (iface.properties.methodSet ?? [...own]).map((signature): GoMethodSetEntry => {
+    const from = own.has(signature) ? undefined : viaEmbed.get(signature);
+    const owner = from ? from.owner : iface;
+    return {
+      name: methodName(signature),
+      signature,
+      origin: from ? 'promoted' : 'declared',
+      via: from ? [embeddedFieldName(from.embed)] : [],
+      ...(owner ? { ownerId: owner.id } : {}),
+      ownerName: owner ? owner.properties.name : from!.embed,
+      pointerReceiver: false,
+      inValueSet: true,
+    };
+  });
+  return {
+    typeId: iface.id,
+    typeName: iface.properties.name,
+    methods: methods.sort((a, b) => (a.name < b.name ? -1 : a.name > b.name ? 1 : 0)),
+    ambiguous: [],
+    unresolvedEmbeds,
+  };
+};
+
+/**
+ * Full method set of a Go type, declared and promoted methods alike, or
+ * null when the id isn't a Go struct, defined type or interface. For
+ * interfaces, requires processGoInterfaces to have run (uses methodSet).
+ */
+export const getGoMethodSet = (graph: KnowledgeGraph, typeId: string): GoMethodSet | null => {
+  const node = graph.getNode(typeId);
+  if (!node || node.properties.language !== 'go') return null;
+  if (isGoInterface(node)) return interfaceMethodSet(graph, node);
+  if ((node.label !== 'Struct' && node.label !== 'TypeAlias') || node.properties.isAlias) return null;
+  const index = createGoTypeIndex(graph);
+  const key = typeKey(goPackageDir(node.properties.filePath), node.properties.name);
+  return index.types.get(key)?.id === node.id ? computeGoMethodSet(index, key) : null;
+};
+
+// ============================================================================
+// IMPLEMENTERS
+// ============================================================================
+
+/**
+ * How a concrete type satisfies an interface:
+ * - 'value': T (and therefore *T) has every method
+ * - 'pointer': only *T has every method (some use pointer receivers)
+ * - 'partial': some interface methods are missing from *T
+ */
+export type GoImplementationKind = 'value' | 'pointer' | 'partial';
+
+export interface GoImplementerMatch {
+  typeId: string;
+  typeName: string;
+  filePath: string;
+  kind: GoImplementationKind;
+  /** Interface methods found in the *T method set */
+  matchedMethods: string[];
+  /** Interface methods *T lacks (empty unless kind === 'partial') */
+  missingMethods: string[];
+  /** Interface methods only *T has — why a 'pointer' match isn't 'value' */
+  pointerOnlyMethods: string[];
+}
+
+interface GoTypeMethodSets {
+  node: GraphNode;
+  /** Method set of T */
+  value: Set<string>;
+  /** Method set of *T (superset of value) */
+  pointer: Set<string>;
+}
+
+/**
+ * Value and pointer method sets, as signatures, for every named Go type
+ * (see computeGoMethodSet for promotion, shadowing and ambiguity).
+ */
+const buildGoTypeMethodSets = (graph: KnowledgeGraph): Map<string, GoTypeMethodSets> => {
+  const index = createGoTypeIndex(graph);
+  const result = new Map<string, GoTypeMethodSets>();
+  for (const [key, node] of index.types) {
+    const { methods } = computeGoMethodSet(index, key);
+    result.set(key, {
+      node,
+      value: new Set(methods.filter(m => m.inValueSet).map(m => m.signature)),
+      pointer: new Set(methods.map(m => m.signature)),
+    });
+  }
+  return result;
+};
+
+const matchInterface = (
+  methodSet: string[],
+  sets: GoTypeMethodSets,
+): GoImplementerMatch | null => {
+  const matchedMethods = methodSet.filter(m => sets.pointer.has(m));
+  if (matchedMethods.length === 0) return null;
+  const missingMethods = methodSet.filter(m => !sets.pointer.has(m));
+  const pointerOnlyMethods = matchedMethods.filter(m => !sets.value.has(m));
+  const kind: GoImplementationKind = missingMethods.length > 0
+    ? 'partial'
+    : pointerOnlyMethods.length > 0 ? 'pointer' : 'value';
+  return {
+    typeId: sets.node.id,
+    typeName: sets.node.properties.name,
+    filePath: sets.node.properties.filePath,
+    kind,
+    matchedMethods,
+    missingMethods,
+    pointerOnlyMethods,
+  };
+};
+
+const sortMatches = (matches: GoImplementerMatch[]): GoImplementerMatch[] => {
+  const rank: Record<GoImplementationKind, number> = { value: 0, pointer: 1, partial: 2 };
+  return matches.sort((a, b) =>
+    rank[a.kind] - rank[b.kind] ||
+    b.matchedMethods.length - a.matchedMethods.length ||
+    a.typeName.localeCompare(b.typeName) ||
+    a.filePath.localeCompare(b.filePath));
+};
+
+/**
+ * Find the concrete Go types whose method sets satisfy an interface.
+ * Requires processGoInterfaces to have run (uses methodSet). Empty
+ * interfaces are satisfied by everything and return no matches.
+ *
+ * @param interfaceId - Node id of a Go interface
+ * @param options.includePartial - Also return types with some of the methods
+ */
+export const findGoImplementers = (
+  graph: KnowledgeGraph,
+  interfaceId: string,
+  options: { includePartial?: boolean } = {},
+): GoImplementerMatch[] => {
+  const iface = graph.getNode(interfaceId);
+  if (!iface || !isGoInterface(iface)) return [];
+  const methodSet = iface.properties.methodSet ?? iface.properties.methodSignatures ?? [];
+  if (methodSet.length === 0) return [];
+
+  const matches: GoImplementerMatch[] = [];
+  for (const sets of buildGoTypeMethodSets(graph).values()) {
+    const match = matchInterface(methodSet, sets);
+    if (!match) continue;
+    if (match.kind === 'partial' && !options.includePartial) continue;
+    matches.push(match);
+  }
+  return sortMatches(matches);
+};
+
+/**
+ * Emit IMPLEMENTS edges from every named Go type to each non-empty repo
+ * interface it fully satisfies. Pointer-only satisfaction is kept apart via
+ * the edge reason so consumers can tell `T` from `*T`.
+ */
+export const processGoImplementations = (graph: KnowledgeGraph): number => {
+  const interfaces: GraphNode[] = [];
+  graph.forEachNode(node => {
+    if (isGoInterface(node) && (node.properties.methodSet?.length ?? 0) > 0) interfaces.push(node);
+  });
+  if (interfaces.length === 0) return 0;
+
+  const allSets = [...buildGoTypeMethodSets(graph).values()];
+  let edges = 0;
+  for (const iface of interfaces) {
+    const methodSet = iface.properties.methodSet!;
+    for (const sets of allSets) {
+      const match = matchInterface(methodSet, sets);
+      if (!match || match.kind === 'partial') continue;
+      graph.addRelationship({
+        id: generateId('IMPLEMENTS', `${sets.node.id}->${iface.id}`),
+        sourceId: sets.node.id,
+        targetId: iface.id,
+        type: 'IMPLEMENTS',
+        confidence: 1.0,
+        reason: match.kind === 'value' ? 'go-method-set' : 'go-method-set-pointer',
+      });
+      edges++;
+    }
+  }
+  return edges;
+};
@@ -0,0 +1,1247 @@
+/**
+ * Go Metadata Extraction
+ *
+ * The definition queries only capture a symbol's name and kind. Go carries a
+ * lot of its design in type shapes (interface method sets, receivers, fields),
+ * so this module walks the declaration node around a captured name and pulls
+ * out the details the graph needs. Pure AST helpers — shared by the parse
+ * worker and the sequential fallback in parsing-processor.
+ */
+
+import { NodeProperties, StructField, FileImport, GoTestKind, TypeParam, TypeUsageKind, TypeWrapping, DiscardedCall, DiscardKind } from '../graph/types.js';
+import { generateId, symbolIdName, isGoTestFile } from '../../lib/utils.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
+import { extractDocComment, extractTrailingComment } from './doc-comments.js';
+
+/** Go-specific properties attached to parsed nodes */
+export type GoSymbolMetadata = Pick<NodeProperties,
+  | 'methodSignatures'
+  | 'embeddedTypes'
+  | 'signature'
+  | 'receiverType'
+  | 'receiverPointer'
+  | 'receiverName'
+  | 'fields'
+  | 'isAlias'
+  | 'underlyingType'
+  | 'typeParams'
+  | 'declaredType'
+  | 'value'
+  | 'constValue'
+  | 'isTest'
+  | 'testKind'
+  | 'description'
+  | 'complexity'
+  | 'discardedCalls'
+>;
+
+// ============================================================================
+// TYPE + SIGNATURE FORMATTING
+// ============================================================================
+
+/**
+ * Canonical text for a type expression: collapsed whitespace, and the empty
+ * interface spelled `any` so `interface{}` and `any` compare equal.
+ */
+export const normalizeGoType = (text: string): string => {
+  const collapsed = text.replace(/\s+/g, ' ').trim();
+  return collapsed.replace(/\binterface ?\{ ?\}/g, 'any');
+};
+
+/**
+ * Flatten a parameter_list into its types, dropping parameter names.
+ * `(a, b int, opts ...Option)` -> ['int', 'int', '...Option']
+ */
+export const getGoParameterTypes = (paramList: any): string[] => {
+  if (!paramList) return [];
+  const types: string[] = [];
+  for (const param of (paramList.namedChildren ?? [])) {
+    if (param.type !== 'parameter_declaration' && param.type !== 'variadic_parameter_declaration') continue;
+    const typeNode = param.childForFieldName?.('type');
+    if (!typeNode) continue;
+    const typeText = normalizeGoType(typeNode.text);
+    const formatted = param.type === 'variadic_parameter_declaration' ? `...${typeText}` : typeText;
+    const nameCount = (param.namedChildren ?? []).filter((c: any) => c.type === 'identifier').length;
+    for (let i = 0; i < Math.max(1, nameCount); i++) types.push(formatted);
+  }
+  return types;
+};
+
+/**
+ * Result types of a function. A result is either a bare type or a
+ * parameter_list (possibly with names).
+ */
+export const getGoResultTypes = (resultNode: any): string[] => {
+  if (!resultNode) return [];
+  if (resultNode.type === 'parameter_list') return getGoParameterTypes(resultNode);
+  return [normalizeGoType(resultNode.text)];
+};
+
+const formatResults = (results: string[]): string => {
+  if (results.length === 0) return '';
+  if (results.length === 1) return ` ${results[0]}`;
+  return ` (${results.join(', ')})`;
+};
+
+/**
+ * Name-free signature used to compare methods structurally:
+ * `Read(p []byte) (n int, err error)` -> `Read([]byte) (int, error)`
+ */
+export const formatGoSignature = (name: string, paramsNode: any, resultNode: any): string => {
+  const params = getGoParameterTypes(paramsNode);
+  const results = getGoResultTypes(resultNode);
+  return `${name}(${params.join(', ')})${formatResults(results)}`;
+};
+
+// ============================================================================
+// INTERFACES
+// ============================================================================
+
+/** Interface body element types across tree-sitter-go grammar versions */
+const INTERFACE_METHOD_TYPES = new Set(['method_elem', 'method_spec']);
+const INTERFACE_EMBED_TYPES = new Set(['type_elem', 'interface_type_name', 'constraint_elem']);
+const EMBEDDABLE_TYPE_NODES = new Set(['type_identifier', 'qualified_type', 'generic_type']);
+
+interface GoInterfaceShape {
+  methods: string[];
+  embeds: string[];
+}
+
+/**
+ * Collect declared method signatures and embedded type names from an
+ * interface_type node. Inline anonymous interfaces are flattened in place;
+ * type-set constraints (`~int | ~string`) are not embeds and are skipped.
+ */
+const collectInterfaceShape = (interfaceNode: any, shape: GoInterfaceShape) => {
+  for (const elem of (interfaceNode.namedChildren ?? [])) {
+    if (INTERFACE_METHOD_TYPES.has(elem.type)) {
+      const nameNode = elem.childForFieldName?.('name');
+      if (!nameNode) continue;
+      shape.methods.push(formatGoSignature(
+        nameNode.text,
+        elem.childForFieldName?.('parameters'),
+        elem.childForFieldName?.('result'),
+      ));
+    } else if (INTERFACE_EMBED_TYPES.has(elem.type)) {
+      const parts = (elem.namedChildren ?? []).filter((c: any) => c.type !== 'comment');
+      // interface_type_name wraps the name directly (older grammars)
+      if (parts.length === 0 && elem.text) {
+        shape.embeds.push(normalizeGoType(elem.text));
+        continue;
+      }
+      if (parts.length !== 1) continue;
+      const inner = parts[0];
+      if (inner.type === 'interface_type') {
+        collectInterfaceShape(inner, shape);
+      } else if (EMBEDDABLE_TYPE_NODES.has(inner.type)) {
+        shape.embeds.push(normalizeGoType(inner.text));
+      }
+    } else if (elem.type === 'interface_type') {
+      collectInterfaceShape(elem, shape);
+    } else if (EMBEDDABLE_TYPE_NODES.has(elem.type)) {
+      shape.embeds.push(normalizeGoType(elem.text));
+    }
+  }
+};
+
+const extractInterfaceMetadata = (typeSpec: any): GoSymbolMetadata => {
+  const typeNode = typeSpec.childForFieldName?.('type');
+  if (!typeNode || typeNode.type !== 'interface_type') return {};
+  const shape: GoInterfaceShape = { methods: [], embeds: [] };
+  collectInterfaceShape(typeNode, shape);
+  return {
+    methodSignatures: [...new Set(shape.methods)],
+    embeddedTypes: [...new Set(shape.embeds)],
+  };
+};
+
+// ============================================================================
+// STRUCTS
+// ============================================================================
+
+/** Doc for a field: the comment above it and/or the one trailing it */
+const fieldDoc = (field: any, anchor: any): string | undefined => {
+  const parts = [
+    extractDocComment(anchor, SupportedLanguages.Go),
+    extractTrailingComment(field, SupportedLanguages.Go),
+  ].filter(Boolean);
+  return parts.length > 0 ? parts.join('\n') : undefined;
+};
+
+/** Go exports identifiers that start with an upper-case letter */
+export const isGoExported = (name: string): boolean => {
+  const first = name.charAt(0);
+  return first !== first.toLowerCase() && first === first.toUpperCase();
+};
+
+/** Struct tag literal without its quotes: `json:"name"` */
+const fieldTag = (field: any): string | undefined => {
+  const tagNode = field.childForFieldName?.('tag');
+  if (!tagNode) return undefined;
+  const raw = tagNode.text;
+  if (raw.startsWith('`')) return raw.slice(1, -1);
+  try {
+    return JSON.parse(raw);
+  } catch {
+    return raw.slice(1, -1);
+  }
+};
+
+/** One-line field summary stored as the struct's description */
+const describeFields = (fields: StructField[]): string =>
+  'fields: ' + fields
+    .map(f => f.embedded ? `${f.typeString} (embedded)` : `${f.name} ${f.typeString}`)
+    .join('; ');
+
+/**
+ * Fields of a struct plus its embedded (anonymous) fields, pointer embeds
+ * keeping their `*`: `struct { Base; *log.Logger; name string }` ->
+ * embeddedTypes ['Base', '*log.Logger']. An embedded field is named after
+ * its type (`Logger`), as in Go.
+ */
+const extractStructMetadata = (typeSpec: any): GoSymbolMetadata => {
+  const typeNode = typeSpec.childForFieldName?.('type');
+  if (!typeNode || typeNode.type !== 'struct_type') return {};
+  const fieldList = typeNode.namedChildren?.find((c: any) => c.type === 'field_declaration_list');
+  const embeds: string[] = [];
+  const fields: StructField[] = [];
+  for (const field of (fieldList?.namedChildren ?? [])) {
+    if (field.type !== 'field_declaration') continue;
+    const typeChild = field.childForFieldName?.('type');
+    if (!typeChild) continue;
+    const names = (field.namedChildren ?? []).filter((c: any) => c.type === 'field_identifier');
+    const tag = fieldTag(field);
+    const extras = (doc: string | undefined) => ({
+      ...(tag !== undefined ? { tag } : {}),
+      ...(doc ? { doc } : {}),
+    });
+
+    if (names.length > 0) {
+      const typeString = normalizeGoType(typeChild.text);
+      const doc = fieldDoc(field, names[0]);
+      for (const name of names) {
+        fields.push({
+          name: name.text,
+          typeString,
+          exported: isGoExported(name.text),
+          embedded: false,
+          line: name.startPosition.row,
+          ...extras(doc),
+        });
+      }
+      continue;
+    }
+
+    // The grammar puts the `*` of `*Base` on the field, not in a pointer_type
+    const isPointer = typeChild.type === 'pointer_type' || (field.children ?? []).some((c: any) => c.type === '*');
+    const typeText = normalizeGoType(typeChild.text).replace(/^\*\s*/, '');
+    const typeString = isPointer ? `*${typeText}` : typeText;
+    embeds.push(typeString);
+    const name = typeText.replace(/\[.*\]$/, '').replace(/^.*\./, '');
+    fields.push({
+      name,
+      typeString,
+      exported: isGoExported(name),
+      embedded: true,
+      line: typeChild.startPosition.row,
+      ...extras(fieldDoc(field, typeChild)),
+    });
+  }
+  return {
+    embeddedTypes: embeds,
+    fields,
+    ...(fields.length > 0 ? { description: describeFields(fields) } : {}),
+  };
+};
+
+// ============================================================================
+// TYPE PARAMETERS
+// ============================================================================
+
+/**
+ * Type parameters of a generic function or type spec, in declaration order:
+ * `[K comparable, V any]` -> [{K, comparable}, {V, any}]; `[T, U any]`
+ * shares the constraint. Grammar versions differ on the child node type
+ * (`parameter_declaration` vs `type_parameter_declaration`), but both put
+ * the names in direct identifier children and the constraint in `type`.
+ */
+const extractTypeParams = (decl: any): TypeParam[] => {
+  const list = decl.childForFieldName?.('type_parameters');
+  if (!list) return [];
+  const params: TypeParam[] = [];
+  for (const child of (list.namedChildren ?? [])) {
+    if (child.type !== 'parameter_declaration' && child.type !== 'type_parameter_declaration') continue;
+    const constraintNode = child.childForFieldName?.('type');
+    const constraint = constraintNode ? normalizeGoType(constraintNode.text) : 'any';
+    for (const nameNode of (child.namedChildren ?? [])) {
+      if (nameNode.type === 'identifier') params.push({ name: nameNode.text, constraint });
+    }
+  }
+  return params;
+};
+
+/** `{ typeParams }` when the declaration is generic, else nothing */
+const typeParamsOf = (decl: any): GoSymbolMetadata => {
+  const typeParams = extractTypeParams(decl);
+  return typeParams.length > 0 ? { typeParams } : {};
+};
+
+// ============================================================================
+// NAMED TYPES
+// ============================================================================
+
+/**
+ * `type Celsius = float64` (type_alias) vs `type Celsius float64`
+ * (type_spec). An alias is another name for the same type and shares its
+ * method set; a defined type is new, starts with no methods, and only
+ * converts to its underlying type explicitly.
+ */
+const extractNamedTypeMetadata = (decl: any, name: string): GoSymbolMetadata => {
+  const typeNode = decl.childForFieldName?.('type');
+  if (!typeNode) return {};
+  const isAlias = decl.type === 'type_alias';
+  const underlyingType = normalizeGoType(typeNode.text);
+  const typeParams = decl.childForFieldName?.('type_parameters');
+  const params = typeParams ? normalizeGoType(typeParams.text) : '';
+  return {
+    isAlias,
+    underlyingType,
+    ...typeParamsOf(decl),
+    description: `type ${name}${params}${isAlias ? ' =' : ''} ${underlyingType}`,
+  };
+};
+
+/**
+ * The catch-all `@definition.type` pattern also matches struct and
+ * interface specs, which have their own patterns (and labels). True for
+ * that redundant TypeAlias match, so callers can skip it.
+ */
+export const isRedundantGoTypeMatch = (nameNode: any, label: string): boolean => {
+  if (label !== 'TypeAlias') return false;
+  const decl = nameNode?.parent;
+  if (decl?.type !== 'type_spec') return false;
+  const kind = decl.childForFieldName?.('type')?.type;
+  return kind === 'struct_type' || kind === 'interface_type';
+};
+
+// ============================================================================
+// METHODS
+// ============================================================================
+
+/**
+ * Receiver of a method declaration, base type without pointer or type args:
+ * `func (s *Stack[T]) Push(v T)` -> { receiverType: 'Stack', receiverPointer: true, receiverName: 's' }
+ * An unnamed receiver (`func (*T) M()`) has no receiverName.
+ */
+const extractReceiver = (methodDecl: any): Pick<GoSymbolMetadata, 'receiverType' | 'receiverPointer' | 'receiverName'> => {
+  const receiver = methodDecl.childForFieldName?.('receiver');
+  const param = receiver?.namedChildren?.find((c: any) => c.type === 'parameter_declaration');
+  const typeNode = param?.childForFieldName?.('type');
+  if (!typeNode) return {};
+  const raw = normalizeGoType(typeNode.text).replace(/^\((.*)\)$/, '$1').trim();
+  const receiverPointer = raw.startsWith('*');
+  const receiverType = raw.replace(/^\*\s*/, '').replace(/\[.*\]$/, '');
+  const receiverName = param.childForFieldName?.('name')?.text;
+  return { receiverType, receiverPointer, ...(receiverName ? { receiverName } : {}) };
+};
+
+// ============================================================================
+// CONSTANTS + VARIABLES
+// ============================================================================
+
+const GO_INT_LITERAL = /^(0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO]?[0-7_]+|[0-9][0-9_]*)$/;
+
+const parseGoInt = (text: string): bigint | undefined => {
+  if (!GO_INT_LITERAL.test(text)) return undefined;
+  const clean = text.replace(/_/g, '');
+  // Legacy octal (`0755`) — BigInt wants the 0o prefix
+  if (/^0[0-7]+$/.test(clean)) return BigInt('0o' + clean.slice(1));
+  return BigInt(clean.replace(/^0[oO]/, '0o'));
+};
+
+/**
+ * Integer value of a constant expression, or undefined when it isn't a
+ * plain integer computation (strings, floats, calls, other packages).
+ * `known` holds constants already resolved in the same declaration.
+ */
+const evalGoConstExpr = (expr: any, iota: number, known: Map<string, bigint>): bigint | undefined => {
+  if (!expr) return undefined;
+  switch (expr.type) {
+    case 'int_literal':
+      return parseGoInt(expr.text);
+    case 'iota':
+      return BigInt(iota);
+    case 'identifier':
+      if (expr.text === 'iota') return BigInt(iota);
+      return known.get(expr.text);
+    case 'parenthesized_expression':
+      return evalGoConstExpr(expr.namedChildren?.[0], iota, known);
+    case 'unary_expression': {
+      const operand = evalGoConstExpr(expr.childForFieldName?.('operand'), iota, known);
+      if (operand === undefined) return undefined;
+      const op = expr.childForFieldName?.('operator')?.text ?? expr.child(0)?.text;
+      if (op === '-') return -operand;
+      if (op === '+') return operand;
+      if (op === '^') return ~operand;
+      return undefined;
+    }
+    case 'binary_expression': {
+      const left = evalGoConstExpr(expr.childForFieldName?.('left'), iota, known);
+      const right = evalGoConstExpr(expr.childForFieldName?.('right'), iota, known);
+      if (left === undefined || right === undefined) return undefined;
+      switch (expr.childForFieldName?.('operator')?.text) {
+        case '+': return left + right;
+        case '-': return left - right;
+        case '*': return left * right;
+        case '/': return right === 0n ? undefined : left / right;
+        case '%': return right === 0n ? undefined : left % right;
+        case '<<': return right < 0n || right > 1024n ? undefined : left << right;
+        case '>>': return right < 0n ? undefined : left >> right;
+        case '&': return left & right;
+        case '|': return left | right;
+        case '^': return left ^ right;
+        case '&^': return left & ~right;
+        default: return undefined;
+      }
+    }
+    case 'call_expression': {
+      // Conversions to a named/integer type: `Weekday(iota)`, `time.Duration(5)`
+      const args = expr.childForFieldName?.('arguments')?.namedChildren ?? [];
+      const fn = expr.childForFieldName?.('function');
+      if (args.length !== 1 || !fn || !['identifier', 'selector_expression', 'type_identifier'].includes(fn.type)) {
+        return undefined;
+      }
+      return evalGoConstExpr(args[0], iota, known);
+    }
+    default:
+      return undefined;
+  }
+};
+
+/** Names of a const/var spec — its only direct identifier children */
+const specNames = (spec: any): any[] =>
+  (spec.namedChildren ?? []).filter((c: any) => c.type === 'identifier');
+
+const specValues = (spec: any): any[] =>
+  spec.childForFieldName?.('value')?.namedChildren?.filter((c: any) => c.type !== 'comment') ?? [];
+
+const describeValue = (meta: GoSymbolMetadata): string | undefined => {
+  const parts: string[] = [];
+  if (meta.declaredType) parts.push(`type: ${meta.declaredType}`);
+  if (meta.value !== undefined) parts.push(`value: ${meta.value}`);
+  if (meta.constValue !== undefined && String(meta.constValue) !== meta.value) {
+    parts.push(`resolved: ${meta.constValue}`);
+  }
+  return parts.length > 0 ? parts.join('; ') : undefined;
+};
+
+/**
+ * Declared type and initializer of a const spec, following Go's implicit
+ * repetition: a spec without `= ...` reuses the previous spec's type and
+ * expressions, with iota set to its own index in the block.
+ * `const ( A Weekday = iota; B )` -> B: { declaredType: 'Weekday', value: 'iota', constValue: 1 }
+ */
+const extractConstMetadata = (nameNode: any, constSpec: any): GoSymbolMetadata => {
+  const block = constSpec.parent;
+  const specs = (block?.namedChildren ?? []).filter((c: any) => c.type === 'const_spec');
+  const known = new Map<string, bigint>();
+  let typeNode: any;
+  let values: any[] = [];
+
+  for (let iota = 0; iota < specs.length; iota++) {
+    const spec = specs[iota];
+    const ownValues = specValues(spec);
+    if (ownValues.length > 0) {
+      values = ownValues;
+      typeNode = spec.childForFieldName?.('type');
+    }
+    const names = specNames(spec);
+    const resolved = names.map((_: any, i: number) => evalGoConstExpr(values[i], iota, known));
+    names.forEach((n: any, i: number) => {
+      if (resolved[i] !== undefined) known.set(n.text, resolved[i]!);
+    });
+
+    if (spec.startIndex !== constSpec.startIndex) continue;
+    const index = names.findIndex((n: any) => n.startIndex === nameNode.startIndex);
+    const constValue = index >= 0 ? resolved[index] : undefined;
+    const meta: GoSymbolMetadata = {
+      ...(typeNode ? { declaredType: normalizeGoType(typeNode.text) } : {}),
+      ...(index >= 0 && values[index] ? { value: values[index].text } : {}),
+      ...(constValue !== undefined && constValue >= BigInt(Number.MIN_SAFE_INTEGER) && constValue <= BigInt(Number.MAX_SAFE_INTEGER)
+        ? { constValue: Number(constValue) }
+        : {}),
+    };
+    const description = describeValue(meta);
+    return description ? { ...meta, description } : meta;
+  }
+  return {};
+};
+
+/**
+ * Declared type and initializer text of a var spec. `var a, b = f()` gives
+ * both names the whole multi-value expression.
+ */
+const extractVarMetadata = (nameNode: any, varSpec: any): GoSymbolMetadata => {
+  const typeNode = varSpec.childForFieldName?.('type');
+  const names = specNames(varSpec);
+  const values = specValues(varSpec);
+  const index = names.findIndex((n: any) => n.startIndex === nameNode.startIndex);
+  const valueNode = values.length === 1 ? values[0] : values[index];
+  const meta: GoSymbolMetadata = {
+    ...(typeNode ? { declaredType: normalizeGoType(typeNode.text) } : {}),
+    ...(valueNode ? { value: valueNode.text } : {}),
+  };
+  const description = describeValue(meta);
+  return description ? { ...meta, description } : meta;
+};
+
+// ============================================================================
+// CALL SITES
+// ============================================================================
+
+/** Predeclared functions and types — calls/conversions, never graph edges */
+export const GO_PREDECLARED = new Set([
+  'append', 'cap', 'clear', 'close', 'complex', 'copy', 'delete', 'imag', 'len',
+  'make', 'max', 'min', 'new', 'panic', 'print', 'println', 'real', 'recover',
+  'bool', 'byte', 'complex64', 'complex128', 'error', 'float32', 'float64',
+  'int', 'int8', 'int16', 'int32', 'int64', 'rune', 'string',
+  'uint', 'uint8', 'uint16', 'uint32', 'uint64', 'uintptr', 'any',
+]);
+
+/**
+ * What the call site says about its target beyond the bare name.
+ * - qualifier: operand text of a selector call (`fmt`, `s`, `s.repo`)
+ * - qualifierPackage: import path, when the operand is an imported package
+ * - qualifierType: declared type of the operand variable (`Store`, `db.Conn`)
+ * - dotImports: for unqualified calls, the file's dot-imported package paths
+ */
+export interface GoCallContext {
+  qualifier?: string;
+  qualifierPackage?: string;
+  qualifierType?: string;
+  dotImports?: string[];
+}
+
+/**
+ * Package name a Go import binds by default: the last path segment, skipping
+ * a major-version suffix (`github.com/x/y/v2` -> `y`, `gopkg.in/yaml.v3` -> `yaml`).
+ */
+export const goImportDefaultName = (importPath: string): string => {
+  const segments = importPath.split('/').filter(Boolean);
+  let last = segments[segments.length - 1] ?? importPath;
+  if (/^v\d+$/.test(last) && segments.length > 1) last = segments[segments.length - 2];
+  return last.replace(/\.v\d+$/, '').replace(/^go-/, '');
+};
+
+/** Imports of a Go file in declaration order, with the name each one binds */
+export const extractGoImports = (rootNode: any): FileImport[] => {
+  const imports: FileImport[] = [];
+  const visit = (node: any) => {
+    if (node.type === 'import_spec') {
+      const pathNode = node.childForFieldName?.('path');
+      if (!pathNode) return;
+      const importPath = pathNode.text.replace(/["`]/g, '');
+      const alias = node.childForFieldName?.('name')?.text;
+      const kind: FileImport['kind'] = alias === '.' ? 'dot'
+        : alias === '_' ? 'blank'
+        : alias ? 'alias'
+        : 'default';
+      imports.push({
+        path: importPath,
+        localName: alias || goImportDefaultName(importPath),
+        kind,
+        line: node.startPosition.row,
+      });
+      return;
+    }
+    if (node.type !== 'source_file' && node.type !== 'import_declaration' && node.type !== 'import_spec_list') return;
+    for (const child of (node.namedChildren ?? [])) visit(child);
+  };
+  visit(rootNode);
+  return imports;
+};
+
+/** Map of package names bound in a file to their import paths */
+export const collectGoImportAliases = (imports: FileImport[]): Map<string, string> => {
+  const aliases = new Map<string, string>();
+  for (const imp of imports) {
+    // Dot and blank imports bind no name
+    if (imp.kind === 'dot' || imp.kind === 'blank') continue;
+    aliases.set(imp.localName, imp.path);
+  }
+  return aliases;
+};
+
+const GO_FUNCTION_SCOPES = new Set(['function_declaration', 'method_declaration', 'func_literal']);
+
+/** Named type of a declaration, stripped of pointer and type args */
+export const baseTypeName = (typeText: string): string =>
+  normalizeGoType(typeText).replace(/^\*\s*/, '').replace(/\[.*\]$/, '');
+
+/** Type of a composite/pointer-literal initializer (`T{}`, `&T{}`, `new(T)`) */
+export const initializerType = (expr: any): string | undefined => {
+  if (!expr) return undefined;
+  if (expr.type === 'composite_literal') {
+    const t = expr.childForFieldName?.('type');
+    return t ? baseTypeName(t.text) : undefined;
+  }
+  if (expr.type === 'unary_expression' && expr.childForFieldName?.('operator')?.text === '&') {
+    return initializerType(expr.childForFieldName?.('operand'));
+  }
+  if (expr.type === 'call_expression' && expr.childForFieldName?.('function')?.text === 'new') {
+    const arg = expr.childForFieldName?.('arguments')?.namedChildren?.[0];
+    return arg ? baseTypeName(arg.text) : undefined;
+  }
+  return undefined;
+};
+
+/** Collect `name -> type` for receiver, parameters, and typed locals of one function */
+const collectScopeTypes = (fn: any): Map<string, string> => {
+  const types = new Map<string, string>();
+  const addParams = (list: any) => {
+    for (const param of (list?.namedChildren ?? [])) {
+      if (param.type !== 'parameter_declaration') continue;
+      const typeNode = param.childForFieldName?.('type');
+      if (!typeNode) continue;
+      for (const c of (param.namedChildren ?? [])) {
+        if (c.type === 'identifier') types.set(c.text, baseTypeName(typeNode.text));
+      }
+    }
+  };
+  addParams(fn.childForFieldName?.('receiver'));
+  addParams(fn.childForFieldName?.('parameters'));
+
+  const visit = (node: any) => {
+    // Nested closures get their own scope
+    if (node !== fn && GO_FUNCTION_SCOPES.has(node.type)) return;
+    if (node.type === 'short_var_declaration') {
+      const left = node.childForFieldName?.('left')?.namedChildren ?? [];
+      const right = node.childForFieldName?.('right')?.namedChildren ?? [];
+      left.forEach((id: any, i: number) => {
+        const t = id.type === 'identifier' ? initializerType(right[i]) : undefined;
+        if (t) types.set(id.text, t);
+      });
+    } else if (node.type === 'var_spec') {
+      const typeNode = node.childForFieldName?.('type');
+      const values = node.childForFieldName?.('value')?.namedChildren ?? [];
+      const names = (node.namedChildren ?? []).filter((c: any) => c.type === 'identifier');
+      names.forEach((id: any, i: number) => {
+        const t = typeNode ? baseTypeName(typeNode.text) : initializerType(values[i]);
+        if (t) types.set(id.text, t);
+      });
+    }
+    for (const child of (node.namedChildren ?? [])) visit(child);
+  };
+  const body = fn.childForFieldName?.('body');
+  if (body) visit(body);
+  return types;
+};
+
+/** Call-site context for a call_expression in the extractor's file */
+export type GoCallContextExtractor = (callNode: any) => GoCallContext;
+
+/**
+ * Build a per-file extractor of call-site context. Import aliases are read
+ * once; scope types are cached per enclosing function.
+ */
+export const createGoCallContextExtractor = (rootNode: any, imports: FileImport[] = extractGoImports(rootNode)): GoCallContextExtractor => {
+  const aliases = collectGoImportAliases(imports);
+  const dotImports = imports.filter(i => i.kind === 'dot').map(i => i.path);
+  const scopeCache = new Map<number, Map<string, string>>();
+
+  const lookupType = (callNode: any, name: string): string | undefined => {
+    let current = callNode.parent;
+    while (current) {
+      if (GO_FUNCTION_SCOPES.has(current.type)) {
+        let scope = scopeCache.get(current.startIndex);
+        if (!scope) {
+          scope = collectScopeTypes(current);
+          scopeCache.set(current.startIndex, scope);
+        }
+        const t = scope.get(name);
+        if (t) return t;
+      }
+      current = current.parent;
+    }
+    return undefined;
+  };
+
+  return (callNode: any): GoCallContext => {
+    let fn = callNode.childForFieldName?.('function');
+    // Explicit instantiation can parse as an index: `Map[int](xs)`, `slices.Max[T](xs)`
+    if (fn?.type === 'index_expression') fn = fn.childForFieldName?.('operand');
+    if (fn?.type === 'identifier' && dotImports.length > 0) return { dotImports };
+    if (!fn || fn.type !== 'selector_expression') return {};
+    const operand = fn.childForFieldName?.('operand');
+    if (!operand) return {};
+    const qualifier = normalizeGoType(operand.text);
+    if (operand.type !== 'identifier') return { qualifier };
+
+    const qualifierType = lookupType(callNode, operand.text);
+    if (qualifierType) return { qualifier, qualifierType };
+    const qualifierPackage = aliases.get(operand.text);
+    return qualifierPackage ? { qualifier, qualifierPackage } : { qualifier };
+  };
+};
+
+// ============================================================================
+// SYMBOL IDS
+// ============================================================================
+
+const typeSpecLabel = (spec: any): string => {
+  const kind = spec.childForFieldName?.('type')?.type;
+  if (spec.type === 'type_spec' && kind === 'struct_type') return 'Struct';
+  if (spec.type === 'type_spec' && kind === 'interface_type') return 'Interface';
+  return 'TypeAlias';
+};
+
+interface GoDeclarationKey {
+  label: string;
+  /** Id name before the ordinal: `Name`, or `Recv.Name` for methods */
+  key: string;
+  start: number;
+}
+
+/** Package-level declarations of a file in source order, per tree */
+const declarationCache = new WeakMap<object, GoDeclarationKey[]>();
+
+const collectGoDeclarations = (root: any): GoDeclarationKey[] => {
+  const cacheKey = root.tree ?? root;
+  const cached = declarationCache.get(cacheKey);
+  if (cached) return cached;
+  const decls: GoDeclarationKey[] = [];
+  const addNames = (label: string, spec: any) => {
+    for (const name of specNames(spec)) decls.push({ label, key: name.text, start: name.startIndex });
+  };
+  for (const child of root.namedChildren ?? []) {
+    const name = child.childForFieldName?.('name');
+    switch (child.type) {
+      case 'function_declaration':
+        if (name) decls.push({ label: 'Function', key: name.text, start: name.startIndex });
+        break;
+      case 'method_declaration':
+        if (name) decls.push({ label: 'Method', key: symbolIdName(name.text, extractReceiver(child).receiverType), start: name.startIndex });
+        break;
+      case 'type_declaration':
+        for (const spec of child.namedChildren ?? []) {
+          const specName = spec.childForFieldName?.('name');
+          if ((spec.type === 'type_spec' || spec.type === 'type_alias') && specName) {
+            decls.push({ label: typeSpecLabel(spec), key: specName.text, start: specName.startIndex });
+          }
+        }
+        break;
+      case 'const_declaration':
+        for (const spec of child.namedChildren ?? []) if (spec.type === 'const_spec') addNames('Const', spec);
+        break;
+      case 'var_declaration':
+        for (const spec of child.namedChildren ?? []) {
+          if (spec.type === 'var_spec') addNames('Static', spec);
+          else for (const inner of spec.namedChildren ?? []) if (inner.type === 'var_spec') addNames('Static', inner);
+        }
+        break;
+    }
+  }
+  declarationCache.set(cacheKey, decls);
+  return decls;
+};
+
+/**
+ * Id name (see symbolIdName) of a Go package-level declaration, from its
+ * name node: the receiver's base type for methods, and an ordinal when an
+ * earlier declaration in the file has the same label and name. Computed
+ * from the tree alone, so the parse phase and every later reference to the
+ * declaration agree on it.
+ */
+export const goSymbolIdName = (nameNode: any, label: string): string => {
+  const decl = nameNode?.parent;
+  const receiverType = label === 'Method' && decl?.type === 'method_declaration'
+    ? extractReceiver(decl).receiverType
+    : undefined;
+  const key = symbolIdName(nameNode.text, receiverType);
+  let root = decl;
+  while (root && root.type !== 'source_file') root = root.parent;
+  if (!root) return key;
+  const earlier = collectGoDeclarations(root)
+    .filter(d => d.label === label && d.key === key && d.start < nameNode.startIndex).length;
+  return symbolIdName(nameNode.text, receiverType, earlier + 1);
+};
+
+// ============================================================================
+// TYPE REFERENCES
+// ============================================================================
+
+/** A named type written in a file, before resolution (see extractGoTypeReferences) */
+export interface GoTypeReference {
+  typeName: string;
+  /** Package qualifier as written (`models` in `models.User`) */
+  qualifier?: string;
+  /** Import path bound to the qualifier */
+  qualifierPackage?: string;
+  /** Enclosing func/method, type, or package-level var/const; the File otherwise */
+  sourceId: string;
+  line: number;
+  column: number;
+  usage: TypeUsageKind;
+  wrapping: TypeWrapping[];
+}
+
+/** Type constructors that wrap their element, keyed by node type */
+const TYPE_WRAPPERS: Record<string, TypeWrapping> = {
+  pointer_type: 'pointer',
+  slice_type: 'slice',
+  array_type: 'array',
+  implicit_length_array_type: 'array',
+  channel_type: 'chan',
+};
+
+/** Nodes a reference passes through without changing its usage */
+const TRANSPARENT_TYPE_NODES = new Set([
+  'qualified_type', 'generic_type', 'parenthesized_type', 'negated_type', 'union_type',
+  'type_elem', 'constraint_elem', 'interface_type_name',
+]);
+
+const fieldIs = (parent: any, field: string, node: any): boolean =>
+  parent.childForFieldName?.(field)?.startIndex === node.startIndex &&
+  parent.childForFieldName?.(field)?.endIndex === node.endIndex;
+
+/**
+ * Usage kind and wrapping for the type reference `start`, by walking up to
+ * the construct that consumes it. Null when it isn't a reference (the type
+ * name in its own declaration).
+ */
+const classifyTypeReference = (start: any): { usage: TypeUsageKind; wrapping: TypeWrapping[] } | null => {
+  const wrapping: TypeWrapping[] = [];
+  let node = start;
+  for (let parent = node.parent; parent; node = parent, parent = parent.parent) {
+    const wrapper = TYPE_WRAPPERS[parent.type];
+    if (wrapper) {
+      // An array's length is an expression, not part of the element type
+      if (parent.type === 'array_type' && !fieldIs(parent, 'element', node)) return null;
+      wrapping.push(wrapper);
+      continue;
+    }
+    if (TRANSPARENT_TYPE_NODES.has(parent.type)) continue;
+
+    switch (parent.type) {
+      case 'map_type':
+        wrapping.push(fieldIs(parent, 'key', node) ? 'map-key' : 'map-value');
+        continue;
+      case 'type_arguments':
+        wrapping.push('type-argument');
+        continue;
+      case 'function_type':
+        // Result type written directly on a func type
+        wrapping.push('func');
+        continue;
+      case 'variadic_parameter_declaration':
+      case 'parameter_declaration': {
+        if (parent.type === 'variadic_parameter_declaration') wrapping.push('variadic');
+        const list = parent.parent;
+        const owner = list?.parent;
+        if (list?.type === 'type_parameter_list') return { usage: 'constraint', wrapping: wrapping.reverse() };
+        if (!owner) return null;
+        if (owner.type === 'function_type') {
+          // A func-typed value: keep climbing to where the func type is used
+          wrapping.push('func');
+          node = list;
+          parent = owner;
+          continue;
+        }
+        if (owner.type === 'method_declaration' && fieldIs(owner, 'receiver', list)) {
+          return { usage: 'receiver', wrapping: wrapping.reverse() };
+        }
+        return { usage: fieldIs(owner, 'result', list) ? 'result' : 'parameter', wrapping: wrapping.reverse() };
+      }
+      case 'type_parameter_declaration':
+        return { usage: 'constraint', wrapping: wrapping.reverse() };
+      case 'function_declaration':
+      case 'method_declaration':
+      case 'func_literal':
+      case 'method_spec':
+      case 'method_elem':
+        return fieldIs(parent, 'result', node) ? { usage: 'result', wrapping: wrapping.reverse() } : null;
+      case 'field_declaration': {
+        if (!fieldIs(parent, 'type', node)) return null;
+        if (parent.childForFieldName?.('name')) return { usage: 'field', wrapping: wrapping.reverse() };
+        // 'Embedded *Base' keeps the star outside the type field
+        if ((parent.children ?? []).some((c: any) => c.type === '*')) wrapping.push('pointer');
+        return { usage: 'embedded', wrapping: wrapping.reverse() };
+      }
+      case 'interface_type':
+        return { usage: 'embedded', wrapping: wrapping.reverse() };
+      case 'var_spec':
+        return fieldIs(parent, 'type', node) ? { usage: 'variable', wrapping: wrapping.reverse() } : null;
+      case 'const_spec':
+        return fieldIs(parent, 'type', node) ? { usage: 'constant', wrapping: wrapping.reverse() } : null;
+      case 'composite_literal':
+        return fieldIs(parent, 'type', node) ? { usage: 'composite-literal', wrapping: wrapping.reverse() } : null;
+      case 'type_assertion_expression':
+        return { usage: 'type-assertion', wrapping: wrapping.reverse() };
+      case 'type_case':
+        return { usage: 'type-switch', wrapping: wrapping.reverse() };
+      case 'type_conversion_expression':
+        return { usage: 'conversion', wrapping: wrapping.reverse() };
+      case 'call_expression':
+        return fieldIs(parent, 'function', node) ? { usage: 'conversion', wrapping: wrapping.reverse() } : null;
+      case 'argument_list': {
+        const callee = parent.parent?.childForFieldName?.('function')?.text;
+        const usage: TypeUsageKind = callee === 'new' || callee === 'make' ? 'allocation' : 'other';
+        return { usage, wrapping: wrapping.reverse() };
+      }
+      case 'type_spec':
+        if (fieldIs(parent, 'name', node)) return null;
+        return { usage: 'underlying', wrapping: wrapping.reverse() };
+      case 'type_alias':
+        if (fieldIs(parent, 'name', node)) return null;
+        return { usage: 'alias', wrapping: wrapping.reverse() };
+      default:
+        return { usage: 'other', wrapping: wrapping.reverse() };
+    }
+  }
+  return null;
+};
+
+/** Names declared as type parameters on `decl` (including a generic method's receiver `[T]`) */
+const declaredTypeParams = (decl: any): Set<string> => {
+  const names = new Set<string>();
+  const list = decl.childForFieldName?.('type_parameters');
+  for (const param of list?.namedChildren ?? []) {
+    for (const child of param.namedChildren ?? []) {
+      if (child.type === 'identifier' && !fieldIs(param, 'type', child)) names.add(child.text);
+    }
+  }
+  if (decl.type === 'method_declaration') {
+    const visit = (node: any) => {
+      if (node.type === 'type_arguments') {
+        for (const arg of node.namedChildren ?? []) names.add(arg.text);
+        return;
+      }
+      for (const child of node.namedChildren ?? []) visit(child);
+    };
+    const receiver = decl.childForFieldName?.('receiver');
+    if (receiver) visit(receiver);
+  }
+  return names;
+};
+
+/**
+ * Graph node id of the declaration a reference belongs to, matching the ids
+ * the parse phase gives funcs, methods, types and package-level var/const
+ * specs. Null when a type parameter of that declaration shadows `name`.
+ */
+const enclosingDeclarationId = (node: any, name: string, filePath: string): string | null => {
+  let spec: any = null;
+  for (let current = node.parent; current; current = current.parent) {
+    switch (current.type) {
+      case 'function_declaration':
+      case 'method_declaration': {
+        if (declaredTypeParams(current).has(name)) return null;
+        const fnName = current.childForFieldName?.('name');
+        if (!fnName) return generateId('File', filePath);
+        const fnLabel = current.type === 'method_declaration' ? 'Method' : 'Function';
+        return generateId(fnLabel, `${filePath}:${goSymbolIdName(fnName, fnLabel)}`);
+      }
+      case 'type_spec':
+      case 'type_alias':
+        if (declaredTypeParams(current).has(name)) return null;
+        if (!spec) spec = current;
+        break;
+      case 'var_spec':
+      case 'const_spec':
+        if (!spec) spec = current;
+        break;
+    }
+  }
+  const specName = spec?.childForFieldName?.('name');
+  if (!spec || !specName) return generateId('File', filePath);
+  const label = spec.type === 'var_spec' ? 'Static' : spec.type === 'const_spec' ? 'Const' : typeSpecLabel(spec);
+  return generateId(label, `${filePath}:${goSymbolIdName(specName, label)}`);
+};
+
+/**
+ * Every reference to a named type in a Go file — field, parameter, result,
+ * receiver and variable types, composite literals, conversions, type
+ * assertions and switches, constraints — with the pointer/slice/map/chan
+ * layers around it. Predeclared types are left out; names shadowed by a
+ * declaration's own type parameters too.
+ */
+export const extractGoTypeReferences = (
+  rootNode: any,
+  filePath: string,
+  imports: FileImport[] = extractGoImports(rootNode),
+): GoTypeReference[] => {
+  const aliases = collectGoImportAliases(imports);
+  const refs: GoTypeReference[] = [];
+
+  const visit = (node: any) => {
+    let nameNode: any = null;
+    let qualifier: string | undefined;
+    if (node.type === 'qualified_type') {
+      nameNode = node.childForFieldName?.('name');
+      qualifier = node.childForFieldName?.('package')?.text;
+    } else if (node.type === 'type_identifier' && node.parent?.type !== 'qualified_type') {
+      nameNode = node;
+    }
+
+    if (nameNode && (qualifier || !GO_PREDECLARED_TYPES.has(nameNode.text))) {
+      const classified = classifyTypeReference(node);
+      const sourceId = classified ? enclosingDeclarationId(node, nameNode.text, filePath) : null;
+      if (classified && sourceId) {
+        const qualifierPackage = qualifier ? aliases.get(qualifier) : undefined;
+        refs.push({
+          typeName: nameNode.text,
+          ...(qualifier ? { qualifier } : {}),
+          ...(qualifierPackage ? { qualifierPackage } : {}),
+          sourceId,
+          line: nameNode.startPosition.row,
+          column: nameNode.startPosition.column,
+          usage: classified.usage,
+          wrapping: classified.wrapping,
+        });
+      }
+      if (node.type === 'qualified_type') return;
+    }
+    for (const child of node.namedChildren ?? []) visit(child);
+  };
+  visit(rootNode);
+  return refs;
+};
+
+/** Predeclared type names — never repo types */
+export const GO_PREDECLARED_TYPES = new Set([
+  'any', 'bool', 'byte', 'comparable', 'complex64', 'complex128', 'error', 'float32', 'float64',
+  'int', 'int8', 'int16', 'int32', 'int64', 'rune', 'string',
+  'uint', 'uint8', 'uint16', 'uint32', 'uint64', 'uintptr',
+]);
+
+// ============================================================================
+// COMPLEXITY
+// ============================================================================
+
+/** Nodes that add one path through the function */
+const GO_DECISION_NODES = new Set([
+  'if_statement',
+  'for_statement',
+  'expression_case',
+  'type_case',
+  'communication_case',
+]);
+
+/**
+ * Cyclomatic complexity of a func/method declaration, counted the way
+ * gocyclo does: 1 for the function, plus 1 for each
+ *   - `if` (an `else if` is another `if`; a plain `else` adds nothing)
+ *   - `for`, including `for range`
+ *   - `case` in a switch, type switch or select (`default` adds nothing)
+ *   - `&&` and `||`
+ * Function literals in the body count toward the enclosing function.
+ * `goto`, `break`, `continue`, `defer` and `panic` are not counted.
+ */
+export const computeGoComplexity = (decl: any): number => {
+  const body = decl?.childForFieldName?.('body');
+  if (!body) return 1;
+  let complexity = 1;
+  const stack: any[] = [body];
+  while (stack.length > 0) {
+    const node = stack.pop();
+    if (GO_DECISION_NODES.has(node.type)) {
+      complexity++;
+    } else if (node.type === 'binary_expression') {
+      const operator = node.childForFieldName?.('operator')?.text;
+      if (operator === '&&' || operator === '||') complexity++;
+    }
+    for (const child of node.namedChildren ?? []) stack.push(child);
+  }
+  return complexity;
+};
+
+// ============================================================================
+// DISCARDED CALLS
+// ============================================================================
+
+/** Name a call invokes: `f`, `x.F`, `pkg.F[T]`; null for calls of func literals and other expressions */
+const calledName = (call: any): string | null => {
+  let fn = call.childForFieldName?.('function');
+  if (fn?.type === 'index_expression') fn = fn.childForFieldName?.('operand');
+  if (fn?.type === 'identifier') return fn.text;
+  if (fn?.type === 'selector_expression') return fn.childForFieldName?.('field')?.text ?? null;
+  return null;
+};
+
+/**
+ * Calls in a func/method body whose results are thrown away: used as a
+ * statement (plain, `defer` or `go`), or assigned with some results going
+ * to `_`. Calls in function literals count toward the enclosing function,
+ * as for CALLS edges. Calls of predeclared functions (`close`, `delete`)
+ * are left out. Whether a result is an error is decided later, against the
+ * callee (see findIgnoredErrors). Pass the file's `callContext` when
+ * extracting several declarations; one is built from the tree otherwise.
+ */
+export const extractGoDiscardedCalls = (decl: any, callContext?: GoCallContextExtractor): DiscardedCall[] => {
+  const body = decl?.childForFieldName?.('body');
+  if (!body) return [];
+  let root = decl;
+  while (root.parent) root = root.parent;
+  const contextOf = callContext ?? createGoCallContextExtractor(root);
+
+  const calls: DiscardedCall[] = [];
+  const record = (call: any, discard: DiscardKind, left?: any[]) => {
+    if (call?.type !== 'call_expression') return;
+    const callee = calledName(call);
+    if (!callee) return;
+    const context = contextOf(call);
+    if (!context.qualifier && GO_PREDECLARED.has(callee)) return;
+    const blankResults = (left ?? []).flatMap((id: any, i: number) => (id.type === 'identifier' && id.text === '_' ? [i] : []));
+    if (left && blankResults.length === 0) return;
+    calls.push({
+      callee,
+      ...(context.qualifier ? { qualifier: context.qualifier } : {}),
+      ...(context.qualifierPackage ? { qualifierPackage: context.qualifierPackage } : {}),
+      ...(context.qualifierType ? { qualifierType: context.qualifierType } : {}),
+      line: call.startPosition.row,
+      column: call.startPosition.column,
+      discard,
+      ...(left ? { resultCount: left.length, blankResults } : {}),
+    });
+  };
+  /** `a, _ = f()`: only a single call on the right spreads its results over the left */
+  const recordAssignment = (left: any[], right: any[]) => {
+    if (right.length === 1) record(right[0], 'blank', left);
+  };
+
+  const stack: any[] = [body];
+  while (stack.length > 0) {
+    const node = stack.pop();
+    switch (node.type) {
+      case 'expression_statement':
+        record(node.namedChildren?.[0], 'statement');
+        break;
+      case 'defer_statement':
+      case 'go_statement':
+        record(node.namedChildren?.[0], node.type === 'defer_statement' ? 'defer' : 'go');
+        break;
+      case 'assignment_statement':
+      case 'short_var_declaration':
+        recordAssignment(
+          node.childForFieldName?.('left')?.namedChildren ?? [],
+          node.childForFieldName?.('right')?.namedChildren ?? [],
+        );
+        break;
+      case 'var_spec':
+        recordAssignment(
+          (node.namedChildren ?? []).filter((c: any) => c.type === 'identifier'),
+          node.childForFieldName?.('value')?.namedChildren ?? [],
+        );
+        break;
+    }
+    for (const child of node.namedChildren ?? []) stack.push(child);
+  }
+  return calls.sort((a, b) => a.line - b.line || a.column - b.column);
+};
+
+// ============================================================================
+// TEST FUNCTIONS
+// ============================================================================
+
+const GO_TEST_PREFIXES: [string, GoTestKind][] = [
+  ['Test', 'test'],
+  ['Benchmark', 'benchmark'],
+  ['Fuzz', 'fuzz'],
+  ['Example', 'example'],
+];
+
+/**
+ * Test kind for a top-level function name in a _test.go file, using the
+ * `go test` rule: the prefix must be the whole name or be followed by a
+ * character that isn't a lower-case letter (`Testify` is not a test).
+ * `TestMain` is the package's test setup hook, not a test.
+ */
+export const getGoTestKind = (name: string): GoTestKind | undefined => {
+  if (name === 'TestMain') return undefined;
+  for (const [prefix, kind] of GO_TEST_PREFIXES) {
+    if (!name.startsWith(prefix)) continue;
+    const next = name.charAt(prefix.length);
+    if (next === '' || next === next.toUpperCase()) return kind;
+  }
+  return undefined;
+};
+
+// ============================================================================
+// PUBLIC API
+// ============================================================================
+
+/** `{ discardedCalls }` when the body discards any call results, else nothing */
+const discardedCallsOf = (decl: any, callContext?: GoCallContextExtractor): GoSymbolMetadata => {
+  const discardedCalls = extractGoDiscardedCalls(decl, callContext);
+  return discardedCalls.length > 0 ? { discardedCalls } : {};
+};
+
+/**
+ * Extract Go metadata for a captured definition.
+ *
+ * @param nameNode - The @name capture (its parent is the declaring spec/decl)
+ * @param label - The graph label chosen for the definition
+ * @param filePath - Declaring file; test functions are only tagged in _test.go files
+ * @param callContext - The file's call-context extractor, shared across its definitions
+ */
+export const extractGoSymbolMetadata = (
+  nameNode: any,
+  label: string,
+  filePath?: string,
+  callContext?: GoCallContextExtractor,
+): GoSymbolMetadata => {
+  const decl = nameNode?.parent;
+  if (!decl) return {};
+
+  if (label === 'Function' && decl.type === 'function_declaration') {
+    const signature = formatGoSignature(
+      nameNode.text,
+      decl.childForFieldName?.('parameters'),
+      decl.childForFieldName?.('result'),
+    );
+    const complexity = computeGoComplexity(decl);
+    const discarded = discardedCallsOf(decl, callContext);
+    const testKind = filePath && isGoTestFile(filePath) ? getGoTestKind(nameNode.text) : undefined;
+    if (testKind) return { signature, isTest: true, testKind, description: `go ${testKind}`, complexity, ...discarded };
+    return { signature, ...typeParamsOf(decl), description: `func ${signature}`, complexity, ...discarded };
+  }
+
+  if (label === 'Interface' && decl.type === 'type_spec') {
+    return { ...extractInterfaceMetadata(decl), ...typeParamsOf(decl) };
+  }
+
+  if (label === 'Struct' && decl.type === 'type_spec') {
+    return { ...extractStructMetadata(decl), ...typeParamsOf(decl) };
+  }
+
+  if (label === 'TypeAlias' && (decl.type === 'type_spec' || decl.type === 'type_alias')) {
+    return extractNamedTypeMetadata(decl, nameNode.text);
+  }
+
+  if (label === 'Method' && decl.type === 'method_declaration') {
+    const signature = formatGoSignature(
+      nameNode.text,
+      decl.childForFieldName?.('parameters'),
+      decl.childForFieldName?.('result'),
+    );
+    const receiverNode = decl.childForFieldName?.('receiver');
+    const receiverText = receiverNode ? `${normalizeGoType(receiverNode.text)} ` : '';
+    return {
+      signature,
+      ...extractReceiver(decl),
+      description: `func ${receiverText}${signature}`,
+      complexity: computeGoComplexity(decl),
+      ...discardedCallsOf(decl, callContext),
+    };
+  }
+
+  if (label === 'Const' && decl.type === 'const_spec') {
+    return extractConstMetadata(nameNode, decl);
+  }
+
+  if (label === 'Static' && decl.type === 'var_spec') {
+    return extractVarMetadata(nameNode, decl);
+  }
+
+  return {};
+};
@@ -0,0 +1,144 @@
+/**
+ * Go Module Processor
+ *
+ * Finds every go.mod in the scanned tree, records the parsed module on its
+ * File node, and (after parsing) stamps Go files and their symbols with the
+ * owning module path and the package import path. Import, call and type
+ * resolution read the modules back from the graph (see graph/modules.ts).
+ *
+ * go.mod parsing covers what resolution needs: the `module` and `go` lines
+ * and `replace` directives, single-line or in a block. `require`,
+ * `exclude`, `retract` and `toolchain` are ignored.
+ */
+
+import path from 'path';
+import { KnowledgeGraph, GoModule, GoModuleReplace } from '../graph/types.js';
+import { createGoModuleResolver, getGoModules } from '../graph/modules.js';
+import { generateId, goPackageDir } from '../../lib/utils.js';
+
+/** Reads a repo-relative file, or null when it doesn't exist */
+export type GoModFileReader = (relativePath: string) => Promise<string | null>;
+
+const unquote = (token: string): string =>
+  (token.startsWith('"') && token.endsWith('"')) || (token.startsWith('`') && token.endsWith('`'))
+    ? token.slice(1, -1)
+    : token;
+
+const isLocalPath = (p: string): boolean => p === '.' || p === '..' || p.startsWith('./') || p.startsWith('../');
+
+/** `old [v] => new [v]` */
+const parseReplace = (spec: string, moduleDir: string): GoModuleReplace | null => {
+  const arrow = spec.indexOf('=>');
+  if (arrow < 0) return null;
+  const left = spec.substring(0, arrow).trim().split(/\s+/).map(unquote);
+  const right = spec.substring(arrow + 2).trim().split(/\s+/).map(unquote);
+  if (!left[0] || !right[0]) return null;
+
+  let localDir: string | undefined;
+  if (isLocalPath(right[0])) {
+    const joined = path.posix.normalize(path.posix.join(moduleDir || '.', right[0]));
+    // Replacements pointing outside the repo can't be resolved here
+    if (joined !== '..' && !joined.startsWith('../')) localDir = joined === '.' ? '' : joined;
+  }
+  return {
+    oldPath: left[0],
+    ...(left[1] ? { oldVersion: left[1] } : {}),
+    newPath: right[0],
+    ...(right[1] ? { newVersion: right[1] } : {}),
+    ...(localDir !== undefined ? { localDir } : {}),
+  };
+};
+
+/** Parse go.mod content; null when it has no `module` line */
+export const parseGoMod = (content: string, dir: string): GoModule | null => {
+  let modulePath: string | undefined;
+  let goVersion: string | undefined;
+  const replaces: GoModuleReplace[] = [];
+  let inReplaceBlock = false;
+
+  for (const raw of content.split('\n')) {
+    const line = raw.replace(/\/\/.*$/, '').trim();
+    if (!line) continue;
+    if (inReplaceBlock) {
+      if (line === ')') {
+        inReplaceBlock = false;
+        continue;
+      }
+      const replace = parseReplace(line, dir);
+      if (replace) replaces.push(replace);
+      continue;
+    }
+    const [directive, ...rest] = line.split(/\s+/);
+    const args = rest.join(' ');
+    if (directive === 'module' && rest[0]) {
+      modulePath = unquote(rest[0]);
+    } else if (directive === 'go' && rest[0]) {
+      goVersion = rest[0];
+    } else if (directive === 'replace') {
+      if (args === '(') {
+        inReplaceBlock = true;
+        continue;
+      }
+      const replace = parseReplace(args, dir);
+      if (replace) replaces.push(replace);
+    }
+  }
+
+  if (!modulePath) return null;
+  return { path: modulePath, dir, ...(goVersion ? { goVersion } : {}), replaces };
+};
+
+export const isGoModPath = (p: string): boolean => p === 'go.mod' || p.endsWith('/go.mod');
+
+/** Read and parse every go.mod among `paths`, sorted by directory */
+export const loadGoModules = async (paths: string[], readFile: GoModFileReader): Promise<GoModule[]> => {
+  const modules: GoModule[] = [];
+  for (const p of paths.filter(isGoModPath).sort()) {
+    const content = await readFile(p);
+    if (content === null) continue;
+    const module = parseGoMod(content, p === 'go.mod' ? '' : p.substring(0, p.length - '/go.mod'.length));
+    if (module) modules.push(module);
+  }
+  return modules;
+};
+
+/** Record modules on their go.mod File nodes (replacing any previous ones) */
+export const processGoModules = (graph: KnowledgeGraph, modules: GoModule[]) => {
+  graph.forEachNode(node => {
+    if (node.label === 'File' && node.properties.goMod) delete node.properties.goMod;
+  });
+  for (const module of modules) {
+    const fileNode = graph.getNode(generateId('File', module.dir ? `${module.dir}/go.mod` : 'go.mod'));
+    if (fileNode) fileNode.properties.goMod = module;
+  }
+};
+
+/**
+ * Set modulePath/importPath on Go File nodes and the symbols in them.
+ * Run after parsing; files outside any module are left unstamped.
+ */
+export const annotateGoPackages = (graph: KnowledgeGraph) => {
+  const resolver = createGoModuleResolver(getGoModules(graph));
+  if (resolver.modules.length === 0) return;
+
+  const byDir = new Map<string, { modulePath: string; importPath: string } | null>();
+  graph.forEachNode(node => {
+    const { filePath } = node.properties;
+    if (!filePath?.endsWith('.go') || node.label === 'Folder') return;
+    const dir = goPackageDir(filePath);
+    let pkg = byDir.get(dir);
+    if (pkg === undefined) {
+      const module = resolver.moduleForFile(filePath);
+      const importPath = module ? resolver.importPathForDir(dir) : null;
+      pkg = module && importPath ? { modulePath: module.path, importPath } : null;
+      byDir.set(dir, pkg);
+    }
+    if (pkg) {
+      node.properties.modulePath = pkg.modulePath;
+      node.properties.importPath = pkg.importPath;
+    } else {
+      delete node.properties.modulePath;
+      delete node.properties.importPath;
+    }
+  });
+};
@@ -1,11 +1,13 @@
 import fs from 'fs/promises';
 import path from 'path';
 import { KnowledgeGraph } from '../graph/types.js';
+import { getGoModules, createGoModuleResolver, GoModuleResolver } from '../graph/modules.js';
+import { parseGoMod } from './go-module-processor.js';
 import { ASTCache } from './ast-cache.js';
 import Parser from 'tree-sitter';
 import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
 import { LANGUAGE_QUERIES } from './tree-sitter-queries.js';
-import { generateId } from '../../lib/utils.js';
+import { generateId, isGoTestFile } from '../../lib/utils.js';
 import { getLanguageFromFilename, yieldToEventLoop } from './utils.js';
 import { SupportedLanguages } from '../../config/supported-languages.js';
 import type { ExtractedImport } from './workers/parse-worker.js';
@@ -25,6 +27,8 @@ export interface ImportResolutionContext {
   normalizedFileList: string[];
   suffixIndex: SuffixIndex;
   resolveCache: Map<string, string | null>;
+  /** Go package directory -> its non-test .go files, built on first Go import */
+  goPackageIndex?: Map<string, string[]>;
 }
 
 /** Max entries in the resolve cache. Beyond this, the cache is cleared to bound memory.
@@ -43,6 +47,21 @@ export function buildImportResolutionContext(allPaths: string[]): ImportResoluti
 // LANGUAGE-SPECIFIC CONFIG
 // ============================================================================
 
+/**
+ * Reads a repo-relative config file (tsconfig.json, go.mod, ...), or null if
+ * it doesn't exist. Lets resolution read configs from a commit instead of
+ * the working tree.
+ */
+export type ConfigFileReader = (relativePath: string) => Promise<string | null>;
+
+export const createFsConfigReader = (repoRoot: string): ConfigFileReader => async (relativePath) => {
+  try {
+    return await fs.readFile(path.join(repoRoot, relativePath), 'utf-8');
+  } catch {
+    return null;
+  }
+};
+
 /** TypeScript path alias config parsed from tsconfig.json */
 interface TsconfigPaths {
   /** Map of alias prefix -> target prefix (e.g., "@/" -> "src/") */
@@ -51,23 +70,17 @@ interface TsconfigPaths {
   baseUrl: string;
 }
 
-/** Go module config parsed from go.mod */
-interface GoModuleConfig {
-  /** Module path (e.g., "github.com/user/repo") */
-  modulePath: string;
-}
-
 /**
  * Parse tsconfig.json to extract path aliases.
  * Tries tsconfig.json, tsconfig.app.json, tsconfig.base.json in order.
  */
-async function loadTsconfigPaths(repoRoot: string): Promise<TsconfigPaths | null> {
+async function loadTsconfigPaths(readConfig: ConfigFileReader): Promise<TsconfigPaths | null> {
   const candidates = ['tsconfig.json', 'tsconfig.app.json', 'tsconfig.base.json'];
 
   for (const filename of candidates) {
     try {
-      const tsconfigPath = path.join(repoRoot, filename);
-      const raw = await fs.readFile(tsconfigPath, 'utf-8');
+      const raw = await readConfig(filename);
+      if (raw === null) continue;
       // Strip JSON comments (// and /* */ style) for robustness
       const stripped = raw.replace(/\/\/.*$/gm, '').replace(/\/\*[\s\S]*?\*\//g, '');
       const tsconfig = JSON.parse(stripped);
@@ -103,23 +116,25 @@ async function loadTsconfigPaths(repoRoot: string): Promise<TsconfigPaths | null
 }
 
 /**
- * Parse go.mod to extract module path.
+ * Go modules for resolution: those recorded on the graph's go.mod File
+ * nodes (see go-module-processor), else the repo-root go.mod alone.
  */
-async function loadGoModulePath(repoRoot: string): Promise<GoModuleConfig | null> {
-  try {
-    const goModPath = path.join(repoRoot, 'go.mod');
-    const content = await fs.readFile(goModPath, 'utf-8');
-    const match = content.match(/^module\s+(\S+)/m);
-    if (match) {
-      if (isDev) {
-        console.log(`📦 Loaded Go module path: ${match[1]}`);
-      }
-      return { modulePath: match[1] };
+async function loadGoModuleResolver(graph: KnowledgeGraph, readConfig: ConfigFileReader): Promise<GoModuleResolver | null> {
+  let modules = getGoModules(graph);
+  if (modules.length === 0) {
+    try {
+      const content = await readConfig('go.mod');
+      const root = content ? parseGoMod(content, '') : null;
+      if (root) modules = [root];
+    } catch {
+      // No go.mod
     }
-  } catch {
-    // No go.mod
   }
-  return null;
+  if (modules.length === 0) return null;
+  if (isDev) {
+    console.log(`📦 Loaded Go modules: ${modules.map(m => m.path).join(', ')}`);
+  }
+  return createGoModuleResolver(modules);
 }
 
 /** PHP Composer PSR-4 autoload config */
@@ -128,10 +143,10 @@ interface ComposerConfig {
   psr4: Map<string, string>;
 }
 
-async function loadComposerConfig(repoRoot: string): Promise<ComposerConfig | null> {
+async function loadComposerConfig(readConfig: ConfigFileReader): Promise<ComposerConfig | null> {
   try {
-    const composerPath = path.join(repoRoot, 'composer.json');
-    const raw = await fs.readFile(composerPath, 'utf-8');
+    const raw = await readConfig('composer.json');
+    if (raw === null) return null;
     const composer = JSON.parse(raw);
     const psr4Raw = composer.autoload?.['psr-4'] ?? {};
     const psr4Dev = composer['autoload-dev']?.['psr-4'] ?? {};
@@ -159,24 +174,20 @@ interface SwiftPackageConfig {
   targets: Map<string, string>;
 }
 
-async function loadSwiftPackageConfig(repoRoot: string): Promise<SwiftPackageConfig | null> {
+function loadSwiftPackageConfig(allFileList: string[]): SwiftPackageConfig | null {
   // Swift imports are module-name based (e.g., `import SiuperModel`)
   // SPM convention: Sources/<TargetName>/ or Package/Sources/<TargetName>/
-  // We scan for these directories to build a target map
+  // We collect the subdirectories of these that hold files to build a target map
   const targets = new Map<string, string>();
 
   const sourceDirs = ['Sources', 'Package/Sources', 'src'];
-  for (const sourceDir of sourceDirs) {
-    try {
-      const fullPath = path.join(repoRoot, sourceDir);
-      const entries = await fs.readdir(fullPath, { withFileTypes: true });
-      for (const entry of entries) {
-        if (entry.isDirectory()) {
-          targets.set(entry.name, sourceDir + '/' + entry.name);
-        }
-      }
-    } catch {
-      // Directory doesn't exist
+  for (const filePath of allFileList) {
+    const normalized = filePath.replace(/\\/g, '/');
+    for (const sourceDir of sourceDirs) {
+      if (!normalized.startsWith(sourceDir + '/')) continue;
+      const rest = normalized.substring(sourceDir.length + 1);
+      const slash = rest.indexOf('/');
+      if (slash > 0) targets.set(rest.substring(0, slash), sourceDir + '/' + rest.substring(0, slash));
     }
   }
 
@@ -619,37 +630,37 @@ function resolveJavaStaticImport(
 // GO PACKAGE RESOLUTION
 // ============================================================================
 
+/** Package directory -> non-test .go files directly in it */
+function buildGoPackageIndex(allFileList: string[]): Map<string, string[]> {
+  const index = new Map<string, string[]>();
+  for (const filePath of allFileList) {
+    const normalized = filePath.replace(/\\/g, '/');
+    if (!normalized.endsWith('.go') || isGoTestFile(normalized)) continue;
+    const idx = normalized.lastIndexOf('/');
+    const dir = idx >= 0 ? normalized.substring(0, idx) : '';
+    let files = index.get(dir);
+    if (!files) {
+      files = [];
+      index.set(dir, files);
+    }
+    files.push(filePath);
+  }
+  return index;
+}
+
 /**
- * Resolve a Go internal package import to all .go files in the package directory.
- * Returns an array of file paths.
+ * Resolve a Go import to all .go files of the package, using the module
+ * layout (module dirs, nested modules, local replaces). Empty when the
+ * package is outside the repo.
  */
 function resolveGoPackage(
   importPath: string,
-  goModule: GoModuleConfig,
-  normalizedFileList: string[],
-  allFileList: string[],
+  fromFile: string,
+  goModules: GoModuleResolver,
+  packageIndex: Map<string, string[]>,
 ): string[] {
-  if (!importPath.startsWith(goModule.modulePath)) return [];
-
-  // Strip module path to get relative package path
-  const relativePkg = importPath.slice(goModule.modulePath.length + 1); // e.g., "internal/auth"
-  if (!relativePkg) return [];
-
-  const pkgSuffix = '/' + relativePkg + '/';
-  const matches: string[] = [];
-
-  for (let i = 0; i < normalizedFileList.length; i++) {
-    const normalized = normalizedFileList[i];
-    // File must be directly in the package directory (not a subdirectory)
-    if (normalized.includes(pkgSuffix) && normalized.endsWith('.go') && !normalized.endsWith('_test.go')) {
-      const afterPkg = normalized.substring(normalized.indexOf(pkgSuffix) + pkgSuffix.length);
-      if (!afterPkg.includes('/')) {
-        matches.push(allFileList[i]);
-      }
-    }
-  }
-
-  return matches;
+  const dir = goModules.resolveImportDir(importPath, fromFile);
+  return dir === null ? [] : (packageIndex.get(dir) ?? []);
 }
 
 // ============================================================================
@@ -706,6 +717,7 @@ export const processImports = async (
   onProgress?: (current: number, total: number) => void,
   repoRoot?: string,
   allPaths?: string[],
+  readConfigFile?: ConfigFileReader,
 ) => {
   // Use allPaths (full repo) when available for cross-chunk resolution, else fall back to chunk files
   const allFileList = allPaths ?? files.map(f => f.path);
@@ -723,10 +735,12 @@ export const processImports = async (
 
   // Load language-specific configs once before the file loop
   const effectiveRoot = repoRoot || '';
-  const tsconfigPaths = await loadTsconfigPaths(effectiveRoot);
-  const goModule = await loadGoModulePath(effectiveRoot);
-  const composerConfig = await loadComposerConfig(effectiveRoot);
-  const swiftPackageConfig = await loadSwiftPackageConfig(effectiveRoot);
+  const readConfig = readConfigFile ?? createFsConfigReader(effectiveRoot);
+  const tsconfigPaths = await loadTsconfigPaths(readConfig);
+  const goModules = await loadGoModuleResolver(graph, readConfig);
+  const goPackageIndex = goModules ? buildGoPackageIndex(allFileList) : null;
+  const composerConfig = await loadComposerConfig(readConfig);
+  const swiftPackageConfig = loadSwiftPackageConfig(allFileList);
 
   // Helper: add an IMPORTS edge + update import map
   const addImportEdge = (filePath: string, resolvedPath: string) => {
@@ -840,8 +854,8 @@ export const processImports = async (
         }
 
         // ---- Go: handle package-level imports ----
-        if (language === SupportedLanguages.Go && goModule && rawImportPath.startsWith(goModule.modulePath)) {
-          const pkgFiles = resolveGoPackage(rawImportPath, goModule, normalizedFileList, allFileList);
+        if (language === SupportedLanguages.Go && goModules && goPackageIndex) {
+          const pkgFiles = resolveGoPackage(rawImportPath, file.path, goModules, goPackageIndex);
           if (pkgFiles.length > 0) {
             for (const pkgFile of pkgFiles) {
               addImportEdge(file.path, pkgFile);
@@ -918,6 +932,7 @@ export const processImportsFromExtracted = async (
   onProgress?: (current: number, total: number) => void,
   repoRoot?: string,
   prebuiltCtx?: ImportResolutionContext,
+  readConfigFile?: ConfigFileReader,
 ) => {
   const ctx = prebuiltCtx ?? buildImportResolutionContext(files.map(f => f.path));
   const { allFilePaths, allFileList, normalizedFileList, suffixIndex: index, resolveCache } = ctx;
@@ -926,10 +941,12 @@ export const processImportsFromExtracted = async (
   let totalImportsResolved = 0;
 
   const effectiveRoot = repoRoot || '';
-  const tsconfigPaths = await loadTsconfigPaths(effectiveRoot);
-  const goModule = await loadGoModulePath(effectiveRoot);
-  const composerConfig = await loadComposerConfig(effectiveRoot);
-  const swiftPackageConfig = await loadSwiftPackageConfig(effectiveRoot);
+  const readConfig = readConfigFile ?? createFsConfigReader(effectiveRoot);
+  const tsconfigPaths = await loadTsconfigPaths(readConfig);
+  const goModules = await loadGoModuleResolver(graph, readConfig);
+  if (goModules && !ctx.goPackageIndex) ctx.goPackageIndex = buildGoPackageIndex(allFileList);
+  const composerConfig = await loadComposerConfig(readConfig);
+  const swiftPackageConfig = loadSwiftPackageConfig(allFileList);
 
   const addImportEdge = (filePath: string, resolvedPath: string) => {
     const sourceId = generateId('File', filePath);
@@ -1019,8 +1036,8 @@ export const processImportsFromExtracted = async (
       }
 
       // Go: handle package-level imports
-      if (language === SupportedLanguages.Go && goModule && rawImportPath.startsWith(goModule.modulePath)) {
-        const pkgFiles = resolveGoPackage(rawImportPath, goModule, normalizedFileList, allFileList);
+      if (language === SupportedLanguages.Go && goModules && ctx.goPackageIndex) {
+        const pkgFiles = resolveGoPackage(rawImportPath, filePath, goModules, ctx.goPackageIndex);
         if (pkgFiles.length > 0) {
           for (const pkgFile of pkgFiles) {
             addImportEdge(filePath, pkgFile);
@@ -0,0 +1,323 @@
+/**
+ * Incremental Update
+ *
+ * Brings a graph built at one commit up to another by re-indexing only the
+ * files git reports as changed, instead of re-running the whole pipeline.
+ * The same update applies file changes from any FileSource (see
+ * applyFileChanges), which is how watch mode keeps a live graph current.
+ *
+ * - Deleted files: their nodes and every edge touching them are purged
+ * - Renamed files: old-path nodes are purged and the file is parsed at its
+ *   new path; community/process memberships follow the symbols to their new ids
+ * - Unchanged files whose edges pointed at purged symbols: their imports,
+ *   calls, and heritage are re-resolved so they re-link to the new
+ *   definitions (or drop the edge if the target is gone)
+ * - Changed Go files whose build constraints exclude them from the target
+ *   platform (a new `//go:build` line) are removed like deletions, and so
+ *   are changed files outside a scoped graph's scope
+ *
+ * Communities and processes are not re-detected — run a full analyze for that.
+ * Nor is meta that file annotators in unchanged files wrote on re-parsed
+ * symbols: file annotators run over the changed files only.
+ */
+
+import { KnowledgeGraph, GraphRelationship } from '../graph/types.js';
+import { processStructure } from './structure-processor.js';
+import { processParsing } from './parsing-processor.js';
+import { annotateFiles } from './annotators.js';
+import { processImports, createImportMap } from './import-processor.js';
+import { processCalls, ExternalCallMap } from './call-processor.js';
+import { processHeritage } from './heritage-processor.js';
+import { processTypeUsages } from './type-usage-processor.js';
+import { loadGoModules, processGoModules, annotateGoPackages } from './go-module-processor.js';
+import { processGoInterfaces, processGoImplementations } from './go-interface-processor.js';
+import { createSymbolTable, SymbolTable } from './symbol-table.js';
+import { createASTCache } from './ast-cache.js';
+import { getLanguageFromFilename } from './utils.js';
+import { createCommitSource, FileSource } from './filesystem-walker.js';
+import {
+  BuildConstraintOptions, BuildContext, createBuildContext, isGoSourceFile, matchBuildFileName,
+  matchBuildHeader, parseBuildHeader,
+} from './build-constraints.js';
+import { AnalysisScope, createScopeFilter, isWholeRepoScope } from './analysis-scope.js';
+import { getGoModules } from '../graph/modules.js';
+import { generateId } from '../../lib/utils.js';
+import { shouldIgnorePath } from '../../config/ignore-service.js';
+import { getChangedFiles, GitFileChange } from '../../storage/git.js';
+
+export interface FileChangeResult {
+  changes: GitFileChange[];
+  /** Files parsed again (added, modified, renamed/copied targets) */
+  reparsedFiles: string[];
+  /** Unchanged files whose references were re-resolved */
+  relinkedFiles: string[];
+  removedNodes: number;
+  addedNodes: number;
+  /** Edges from surviving nodes that were dropped because their target was purged */
+  invalidatedEdges: number;
+  /** Old node id -> new node id for symbols carried over by a rename */
+  renamedSymbols: Map<string, string>;
+}
+
+export interface IncrementalUpdateResult extends FileChangeResult {
+  fromCommit: string;
+  toCommit: string;
+}
+
+export interface IncrementalUpdateOptions {
+  /** External-call collector from the original run; entries for touched files are replaced */
+  externalCalls?: ExternalCallMap;
+  /** Build constraints the graph was analyzed with; the host platform when unset */
+  build?: BuildConstraintOptions;
+  /** Scope the graph was analyzed with; the whole repo when unset */
+  scope?: AnalysisScope;
+}
+
+/** Relationship types that are resolved references, not structure */
+const REFERENCE_REL_TYPES = new Set(['CALLS', 'IMPORTS', 'EXTENDS', 'IMPLEMENTS', 'USES', 'INHERITS', 'OVERRIDES']);
+
+/** Relationship types owned by cluster detection — carried over, not recomputed */
+const CLUSTER_REL_TYPES = new Set(['MEMBER_OF', 'STEP_IN_PROCESS']);
+
+/** Edges derived globally by the Go interface passes — rebuilt after every update */
+const GO_DERIVED_REASONS = new Set(['go-method-set', 'go-method-set-pointer', 'go-embed']);
+
+const NON_SYMBOL_LABELS = new Set(['Project', 'Package', 'Module', 'Folder', 'File', 'Community', 'Process']);
+
+const parentDirs = (filePath: string): string[] => {
+  const dirs: string[] = [];
+  let idx = filePath.lastIndexOf('/');
+  while (idx > 0) {
+    filePath = filePath.substring(0, idx);
+    dirs.push(filePath);
+    idx = filePath.lastIndexOf('/');
+  }
+  return dirs;
+};
+
+/** Changed Go files that the build for `ctx` leaves out */
+const constraintExcludedPaths = async (
+  changes: GitFileChange[],
+  source: FileSource,
+  ctx: BuildContext | null,
+): Promise<Set<string>> => {
+  const excluded = new Set<string>();
+  if (!ctx) return excluded;
+  const goPaths = changes.filter(c => c.status !== 'deleted' && isGoSourceFile(c.path)).map(c => c.path);
+  for (const p of goPaths) {
+    if (!matchBuildFileName(p, ctx)) excluded.add(p);
+  }
+  for (const [p, content] of await source.read(goPaths.filter(p => !excluded.has(p)))) {
+    if (!matchBuildHeader(parseBuildHeader(content), ctx)) excluded.add(p);
+  }
+  return excluded;
+};
+
+/** Rebuild resolution state (symbols + import map) from what's left in the graph */
+const rebuildResolutionState = (graph: KnowledgeGraph, symbolTable: SymbolTable) => {
+  const importMap = createImportMap();
+  graph.forEachNode(node => {
+    if (NON_SYMBOL_LABELS.has(node.label) || !node.properties.filePath) return;
+    symbolTable.add(node.properties.filePath, node.properties.name, node.id, node.label);
+  });
+  graph.forEachRelationship(rel => {
+    if (rel.type !== 'IMPORTS') return;
+    const from = graph.getNode(rel.sourceId)?.properties.filePath;
+    const to = graph.getNode(rel.targetId)?.properties.filePath;
+    if (!from || !to) return;
+    let set = importMap.get(from);
+    if (!set) {
+      set = new Set();
+      importMap.set(from, set);
+    }
+    set.add(to);
+  });
+  return importMap;
+};
+
+/**
+ * Update `graph` (built at `fromCommit`) to reflect `toCommit`.
+ * File contents are read from git at `toCommit`, not the working tree.
+ */
+export const updateGraphFromDiff = async (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  fromCommit: string,
+  toCommit: string,
+  options: IncrementalUpdateOptions = {},
+): Promise<IncrementalUpdateResult> => {
+  const changes = getChangedFiles(repoPath, fromCommit, toCommit);
+  const result = await applyFileChanges(graph, repoPath, changes, createCommitSource(repoPath, toCommit), options);
+  return { fromCommit, toCommit, ...result };
+};
+
+/**
+ * Update `graph` for file-level `changes`, reading the new contents (and
+ * go.mod files for import resolution) from `source`.
+ */
+export const applyFileChanges = async (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  changes: GitFileChange[],
+  source: FileSource,
+  options: IncrementalUpdateOptions = {},
+): Promise<FileChangeResult> => {
+  const nodesBefore = graph.nodeCount;
+
+  // ── 1. Classify paths ──────────────────────────────────────────────
+  const excluded = await constraintExcludedPaths(
+    changes, source, options.build?.allVariants ? null : createBuildContext(options.build),
+  );
+  const inScope = isWholeRepoScope(options.scope) ? null : createScopeFilter(options.scope!, getGoModules(graph));
+  const removedPaths = new Set<string>();
+  const updatedPaths = new Set<string>();
+  /** new path -> old path, for id migration */
+  const renames = new Map<string, string>();
+  for (const change of changes) {
+    if (change.status === 'deleted') {
+      removedPaths.add(change.path);
+      continue;
+    }
+    if (change.status === 'renamed' && change.oldPath) {
+      removedPaths.add(change.oldPath);
+      renames.set(change.path, change.oldPath);
+    }
+    if (!shouldIgnorePath(change.path) && !excluded.has(change.path) && (!inScope || inScope(change.path))) {
+      updatedPaths.add(change.path);
+    }
+    else removedPaths.add(change.path);
+  }
+  const purgePaths = new Set([...removedPaths, ...updatedPaths]);
+
+  // ── 2. Collect purge set, dependents, and cluster edges to carry over ─
+  const purgeIds = new Set<string>();
+  graph.forEachNode(node => {
+    if (node.label === 'Folder') return;
+    if (purgePaths.has(node.properties.filePath)) purgeIds.add(node.id);
+  });
+
+  const dependentPaths = new Set<string>();
+  const carriedEdges: GraphRelationship[] = [];
+  let invalidatedEdges = 0;
+  graph.forEachRelationship(rel => {
+    const sourcePurged = purgeIds.has(rel.sourceId);
+    const targetPurged = purgeIds.has(rel.targetId);
+    if (!sourcePurged && !targetPurged) return;
+
+    if (sourcePurged && CLUSTER_REL_TYPES.has(rel.type)) {
+      carriedEdges.push(rel);
+      return;
+    }
+    if (!sourcePurged && targetPurged && REFERENCE_REL_TYPES.has(rel.type)) {
+      invalidatedEdges++;
+      const dependent = graph.getNode(rel.sourceId)?.properties.filePath;
+      if (dependent && !purgePaths.has(dependent)) dependentPaths.add(dependent);
+    }
+  });
+
+  // Renamed-file nodes: map old ids to the ids the new path will produce
+  const idMigration = new Map<string, string>();
+  for (const [newPath, oldPath] of renames) {
+    graph.forEachNode(node => {
+      if (node.properties.filePath !== oldPath || node.label === 'Folder') return;
+      idMigration.set(node.id, node.label === 'File'
+        ? node.id.replace(oldPath, newPath)
+        : node.id.replace(`:${oldPath}:`, `:${newPath}:`));
+    });
+  }
+
+  // ── 3. Purge ───────────────────────────────────────────────────────
+  const removedNodes = graph.removeNodes(purgeIds);
+
+  // Folders left empty by deletions, deepest first so emptiness cascades up
+  const candidateFolders = new Set<string>();
+  for (const p of removedPaths) parentDirs(p).forEach(d => candidateFolders.add(d));
+  const childCounts = new Map<string, number>();
+  graph.forEachRelationship(rel => {
+    if (rel.type === 'CONTAINS') childCounts.set(rel.sourceId, (childCounts.get(rel.sourceId) ?? 0) + 1);
+  });
+  const emptyFolders: string[] = [];
+  for (const dir of [...candidateFolders].sort((a, b) => b.length - a.length)) {
+    const id = generateId('Folder', dir);
+    if (!graph.getNode(id) || (childCounts.get(id) ?? 0) > 0) continue;
+    emptyFolders.push(id);
+    const parent = parentDirs(dir)[0];
+    if (parent) {
+      const parentId = generateId('Folder', parent);
+      childCounts.set(parentId, (childCounts.get(parentId) ?? 1) - 1);
+    }
+  }
+  const removedFolders = graph.removeNodes(emptyFolders);
+
+  if (options.externalCalls) {
+    for (const [key, call] of options.externalCalls) {
+      if (purgePaths.has(call.filePath) || dependentPaths.has(call.filePath)) {
+        options.externalCalls.delete(key);
+      }
+    }
+  }
+
+  // Go-derived edges depend on whole-package method sets — rebuild them all
+  for (const rel of graph.relationships) {
+    if (GO_DERIVED_REASONS.has(rel.reason)) graph.removeRelationship(rel.id);
+  }
+
+  // ── 4. Re-add structure + parse changed files ──────────────────────
+  processStructure(graph, [...updatedPaths]);
+
+  const symbolTable = createSymbolTable();
+  const importMap = rebuildResolutionState(graph, symbolTable);
+
+  const parsePaths = [...updatedPaths].filter(p => getLanguageFromFilename(p));
+  const relinkPaths = [...dependentPaths].filter(p => getLanguageFromFilename(p));
+  const contents = await source.read([...parsePaths, ...relinkPaths]);
+  const toFiles = (paths: string[]) => paths
+    .filter(p => contents.has(p))
+    .map(p => ({ path: p, content: contents.get(p)! }));
+  const parseFiles = toFiles(parsePaths);
+  const resolveFiles = [...parseFiles, ...toFiles(relinkPaths)];
+
+  const astCache = createASTCache(Math.max(1, resolveFiles.length));
+  await processParsing(graph, parseFiles, symbolTable, astCache);
+  await annotateFiles(graph, parseFiles, undefined, astCache);
+
+  // ── 5. Re-resolve references for changed files + dependents ────────
+  const allPaths: string[] = [];
+  graph.forEachNode(node => { if (node.label === 'File') allPaths.push(node.properties.filePath); });
+  processGoModules(graph, await loadGoModules(allPaths, source.readFile));
+  await processImports(graph, resolveFiles, astCache, importMap, undefined, repoPath, allPaths,
+    source.readFile);
+  await processCalls(graph, resolveFiles, astCache, symbolTable, importMap, undefined, options.externalCalls);
+  await processHeritage(graph, resolveFiles, astCache, symbolTable);
+  await processTypeUsages(graph, resolveFiles, astCache, symbolTable);
+  astCache.clear();
+
+  annotateGoPackages(graph);
+  processGoInterfaces(graph);
+  processGoImplementations(graph);
+
+  // ── 6. Carry community/process membership over to surviving ids ────
+  const renamedSymbols = new Map<string, string>();
+  for (const [oldId, newId] of idMigration) {
+    if (graph.getNode(newId)) renamedSymbols.set(oldId, newId);
+  }
+  for (const rel of carriedEdges) {
+    const sourceId = renamedSymbols.get(rel.sourceId) ?? rel.sourceId;
+    if (!graph.getNode(sourceId) || !graph.getNode(rel.targetId)) continue;
+    graph.addRelationship({
+      ...rel,
+      id: rel.id.split(rel.sourceId).join(sourceId),
+      sourceId,
+    });
+  }
+
+  return {
+    changes,
+    reparsedFiles: parseFiles.map(f => f.path),
+    relinkedFiles: relinkPaths,
+    removedNodes: removedNodes + removedFolders,
+    addedNodes: graph.nodeCount - (nodesBefore - removedNodes - removedFolders),
+    invalidatedEdges,
+    renamedSymbols,
+  };
+};
@@ -0,0 +1,160 @@
+/**
+ * Pluggable Language Parsers
+ *
+ * The built-in languages are parsed with tree-sitter queries (see
+ * tree-sitter-queries.ts) inside the parse workers. Languages without a
+ * grammar here — or repos that want their own take on a built-in one — can
+ * register a LanguageParser instead. Registered parsers run on the main
+ * thread, before the built-in chunks, and take precedence over the built-in
+ * parser for the extensions they claim.
+ *
+ * A parser only reports symbols; the pipeline turns them into nodes, DEFINES
+ * edges from the file, and symbol-table entries, so calls from built-in
+ * languages can resolve to them by name.
+ */
+
+import { KnowledgeGraph, GraphNode, NodeLabel } from '../graph/types.js';
+import { SymbolTable } from './symbol-table.js';
+import { NODE_TABLES } from '../kuzu/schema.js';
+import { generateId } from '../../lib/utils.js';
+import type { ParseListener } from './parsing-processor.js';
+
+/**
+ * Symbol kind: any graph label (`Function`, `Class`, `Trait`, ...) or a
+ * free-form kind such as 'class', 'func', or 'rule'. Case doesn't matter for
+ * labels; kinds with no matching storable label become CodeElement nodes
+ * with the kind kept in their description.
+ */
+export type SymbolKind = NodeLabel | (string & {});
+
+/** A language-agnostic definition reported by a parser */
+export interface ParsedSymbol {
+  name: string;
+  kind: SymbolKind;
+  /** 0-based row of the name */
+  startLine: number;
+  /** 0-based last row of the whole definition */
+  endLine?: number;
+  isExported?: boolean;
+  /** Signature or other one-line summary */
+  description?: string;
+  docComment?: string;
+}
+
+export interface LanguageParser {
+  /** Language name recorded on nodes (`lua`, `elixir`, ...) */
+  language: string;
+  /** File extensions including the dot (`.lua`) */
+  extensions: string[];
+  /** Definitions in one file. Throwing skips the file. */
+  parse: (filePath: string, content: string) => ParsedSymbol[] | Promise<ParsedSymbol[]>;
+}
+
+const registeredParsers: LanguageParser[] = [];
+
+/** Register a parser; later registrations win for a shared extension */
+export const registerLanguageParser = (parser: LanguageParser): void => {
+  registeredParsers.unshift(parser);
+};
+
+export const clearLanguageParsers = (): void => {
+  registeredParsers.length = 0;
+};
+
+/** The registered parser for a path, if any */
+export const getLanguageParser = (filePath: string): LanguageParser | undefined =>
+  registeredParsers.find(parser => parser.extensions.some(ext => filePath.endsWith(ext)));
+
+const KIND_ALIASES: Record<string, NodeLabel> = {
+  func: 'Function',
+  fn: 'Function',
+  def: 'Function',
+  procedure: 'Function',
+  ctor: 'Constructor',
+  field: 'Property',
+  attribute: 'Property',
+  constant: 'Const',
+  var: 'Static',
+  variable: 'Static',
+  alias: 'TypeAlias',
+  package: 'Module',
+};
+
+/** Labels the pipeline creates itself; a parser can't report these */
+const STRUCTURAL_LABELS = new Set<string>(['File', 'Folder', 'Community', 'Process']);
+
+const STORABLE_LABELS = new Map<string, NodeLabel>(
+  NODE_TABLES
+    .filter(label => !STRUCTURAL_LABELS.has(label))
+    .map(label => [label.toLowerCase(), label as NodeLabel]),
+);
+
+/** Storable graph label for a kind; null when it falls back to CodeElement */
+export const resolveSymbolKind = (kind: SymbolKind): NodeLabel | null => {
+  const key = kind.toLowerCase();
+  return STORABLE_LABELS.get(key) ?? KIND_ALIASES[key] ?? null;
+};
+
+/**
+ * Run registered parsers over `files` and add their symbols to the graph.
+ * Returns the number of symbols added.
+ */
+export const processRegisteredParsers = async (
+  graph: KnowledgeGraph,
+  files: { path: string; content: string }[],
+  symbolTable: SymbolTable,
+  listener?: ParseListener,
+): Promise<number> => {
+  let added = 0;
+  for (const file of files) {
+    const parser = getLanguageParser(file.path);
+    if (!parser) continue;
+
+    let symbols: ParsedSymbol[];
+    try {
+      symbols = await parser.parse(file.path, file.content);
+    } catch (err) {
+      console.warn(`  ${parser.language} parser failed on ${file.path}: ${(err as Error).message}`);
+      listener?.onFileDone?.(file.path, { filePath: file.path, stage: 'parse', message: (err as Error).message });
+      continue;
+    }
+
+    const fileId = generateId('File', file.path);
+    for (const symbol of symbols) {
+      const resolved = resolveSymbolKind(symbol.kind);
+      const label: NodeLabel = resolved ?? 'CodeElement';
+      const description = symbol.description ?? (resolved ? undefined : `kind: ${symbol.kind}`);
+      const nodeId = generateId(label, `${file.path}:${symbol.name}`);
+
+      const node: GraphNode = {
+        id: nodeId,
+        label,
+        properties: {
+          name: symbol.name,
+          filePath: file.path,
+          startLine: symbol.startLine,
+          endLine: symbol.startLine,
+          ...(symbol.endLine !== undefined ? { definitionEndLine: symbol.endLine } : {}),
+          language: parser.language,
+          isExported: symbol.isExported ?? false,
+          ...(description !== undefined ? { description } : {}),
+          ...(symbol.docComment ? { docComment: symbol.docComment } : {}),
+        },
+      };
+      graph.addNode(node);
+      listener?.onSymbol?.(node);
+      graph.addRelationship({
+        id: generateId('DEFINES', `${fileId}->${nodeId}`),
+        sourceId: fileId,
+        targetId: nodeId,
+        type: 'DEFINES',
+        confidence: 1.0,
+        reason: '',
+      });
+      symbolTable.add(file.path, symbol.name, nodeId, label);
+      added++;
+    }
+    listener?.onFileDone?.(file.path);
+  }
+  return added;
+};
@@ -2,20 +2,56 @@ import { KnowledgeGraph, GraphNode, GraphRelationship } from '../graph/types.js'
 import Parser from 'tree-sitter';
 import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
 import { LANGUAGE_QUERIES } from './tree-sitter-queries.js';
-import { generateId } from '../../lib/utils.js';
+import { generateId, hashContent } from '../../lib/utils.js';
 import { SymbolTable } from './symbol-table.js';
 import { ASTCache } from './ast-cache.js';
-import { getLanguageFromFilename, yieldToEventLoop } from './utils.js';
+import { getLanguageFromFilename, yieldToEventLoop, createByteOffsetMapper, getSymbolPosition, getBodyHash } from './utils.js';
 import { detectFrameworkFromAST } from './framework-detection.js';
+import { extractGoSymbolMetadata, extractGoImports, isRedundantGoTypeMatch, goSymbolIdName, createGoCallContextExtractor, GoCallContextExtractor } from './go-metadata.js';
+import { claimSymbolId } from '../graph/symbol-ids.js';
+import { fileConstraintExpression, isGoSourceFile } from './build-constraints.js';
+import { extractDocComment } from './doc-comments.js';
+import { annotateSymbols, annotateParsedFiles, hasAnnotators } from './annotators.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
 import { WorkerPool } from './workers/worker-pool.js';
-import type { ParseWorkerResult, ParseWorkerInput, ExtractedImport, ExtractedCall, ExtractedHeritage } from './workers/parse-worker.js';
+import type { ParseWorkerResult, ParseWorkerInput, ExtractedImport, ExtractedCall, ExtractedHeritage, FileParseError } from './workers/parse-worker.js';
+import type { ExtractedTypeRef } from './type-usage-processor.js';
 
 export type FileProgressCallback = (current: number, total: number, filePath: string) => void;
 
+/**
+ * Streaming hooks for showing results before the pipeline returns. Called on
+ * the main thread only — worker results are merged there — so listeners
+ * need no locking. With workers, a chunk's events arrive together once its
+ * workers finish; sequentially, as each file is parsed. Symbols arrive with
+ * their symbol annotators' meta; file annotators run later (see
+ * annotators.ts). Exceptions thrown by a listener abort the run.
+ */
+export interface ParseListener {
+  /** A symbol node was added to the graph */
+  onSymbol?: (node: GraphNode) => void;
+  /**
+   * A file finished parsing. `error` is set when it couldn't be parsed, or
+   * was parsed with syntax errors (its symbols are still indexed then).
+   */
+  onFileDone?: (filePath: string, error?: FileParseError) => void;
+}
+
+/** Per-file error to report: a failure outranks syntax errors */
+const indexFileErrors = (errors: FileParseError[]): Map<string, FileParseError> => {
+  const byFile = new Map<string, FileParseError>();
+  for (const error of errors) {
+    const current = byFile.get(error.filePath);
+    if (!current || current.stage === 'syntax') byFile.set(error.filePath, error);
+  }
+  return byFile;
+};
+
 export interface WorkerExtractedData {
   imports: ExtractedImport[];
   calls: ExtractedCall[];
   heritage: ExtractedHeritage[];
+  typeRefs: ExtractedTypeRef[];
 }
 
 const getDefinitionNodeFromCaptures = (captureMap: Record<string, any>): any | null => {
@@ -163,6 +199,10 @@ const isNodeExported = (node: any, name: string, language: string): boolean => {
   }
 };
 
+const errorMessage = (err: unknown): string => (err instanceof Error ? err.message : String(err));
+
+const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+
 // ============================================================================
 // Worker-based parallel parsing
 // ============================================================================
@@ -174,7 +214,9 @@ const processParsingWithWorkers = async (
   astCache: ASTCache,
   workerPool: WorkerPool,
   onFileProgress?: FileProgressCallback,
-): Promise<WorkerExtractedData> => {
+  errors?: FileParseError[],
+  listener?: ParseListener,
+): Promise<WorkerExtractedData | null> => {
   // Filter to parseable files only
   const parseableFiles: ParseWorkerInput[] = [];
   for (const file of files) {
@@ -182,47 +224,95 @@ const processParsingWithWorkers = async (
     if (lang) parseableFiles.push({ path: file.path, content: file.content });
   }
 
-  if (parseableFiles.length === 0) return { imports: [], calls: [], heritage: [] };
+  if (parseableFiles.length === 0) return { imports: [], calls: [], heritage: [], typeRefs: [] };
 
   const total = files.length;
 
-  // Dispatch to worker pool — pool handles splitting into chunks and sub-batching
-  const chunkResults = await workerPool.dispatch<ParseWorkerInput, ParseWorkerResult>(
-    parseableFiles,
-    (filesProcessed) => {
-      onFileProgress?.(Math.min(filesProcessed, total), total, 'Parsing...');
-    },
-  );
+  // Dispatch to worker pool — pool handles splitting into chunks and sub-batching.
+  // Null tells the caller to fall back to sequential parsing.
+  let chunkResults: ParseWorkerResult[];
+  try {
+    chunkResults = await workerPool.dispatch<ParseWorkerInput, ParseWorkerResult>(
+      parseableFiles,
+      (filesProcessed) => {
+        onFileProgress?.(Math.min(filesProcessed, total), total, 'Parsing...');
+      },
+    );
+  } catch (err) {
+    console.warn('Worker pool parsing failed, falling back to sequential:', err instanceof Error ? err.message : err);
+    return null;
+  }
 
-  // Merge results from all workers into graph and symbol table
+  // Merge results from all workers into graph and symbol table. Chunks come
+  // back in whatever order workers finish, so everything is sorted by file
+  // and position first: the graph's insertion order, and with it the output,
+  // is the same from run to run.
   const allImports: ExtractedImport[] = [];
   const allCalls: ExtractedCall[] = [];
   const allHeritage: ExtractedHeritage[] = [];
+  const allTypeRefs: ExtractedTypeRef[] = [];
+  const chunkErrors: FileParseError[] = [];
+  const parsedNodes: ParseWorkerResult['nodes'] = [];
+  const parsedRelationships: ParseWorkerResult['relationships'] = [];
+  const parsedSymbols: ParseWorkerResult['symbols'] = [];
   for (const result of chunkResults) {
-    for (const node of result.nodes) {
-      graph.addNode({
-        id: node.id,
-        label: node.label as any,
-        properties: node.properties,
-      });
-    }
+    parsedNodes.push(...result.nodes);
+    parsedRelationships.push(...result.relationships);
+    parsedSymbols.push(...result.symbols);
 
-    for (const rel of result.relationships) {
-      graph.addRelationship(rel);
-    }
-
-    for (const sym of result.symbols) {
-      symbolTable.add(sym.filePath, sym.name, sym.nodeId, sym.type);
+    for (const { filePath, imports } of result.fileImports) {
+      const fileNode = graph.getNode(generateId('File', filePath));
+      if (fileNode) fileNode.properties.imports = imports;
     }
 
     allImports.push(...result.imports);
     allCalls.push(...result.calls);
     allHeritage.push(...result.heritage);
+    allTypeRefs.push(...result.typeRefs);
+    chunkErrors.push(...result.errors);
+  }
+
+  parsedNodes.sort((a, b) =>
+    cmp(a.properties.filePath, b.properties.filePath) ||
+    a.properties.startByte - b.properties.startByte ||
+    cmp(a.id, b.id));
+  const position = new Map<string, number>();
+  parsedNodes.forEach((node, i) => position.set(node.id, i));
+  const positionOf = (id: string) => position.get(id) ?? parsedNodes.length;
+  parsedRelationships.sort((a, b) => positionOf(a.targetId) - positionOf(b.targetId) || cmp(a.id, b.id));
+  parsedSymbols.sort((a, b) => positionOf(a.nodeId) - positionOf(b.nodeId) || cmp(a.nodeId, b.nodeId));
+  // Stable: keeps each file's source order
+  const perFile: { filePath: string }[][] = [allImports, allCalls, allHeritage, allTypeRefs, chunkErrors];
+  for (const extracted of perFile) extracted.sort((a, b) => cmp(a.filePath, b.filePath));
+
+  const addedNodes: GraphNode[] = [];
+  const nodesByFile = new Map<string, GraphNode[]>();
+  for (const node of parsedNodes) {
+    const graphNode: GraphNode = {
+      id: node.id,
+      label: node.label as any,
+      properties: node.properties,
+    };
+    graph.addNode(graphNode);
+    addedNodes.push(graphNode);
+    const fileNodes = nodesByFile.get(graphNode.properties.filePath);
+    if (fileNodes) fileNodes.push(graphNode);
+    else nodesByFile.set(graphNode.properties.filePath, [graphNode]);
+  }
+  for (const rel of parsedRelationships) graph.addRelationship(rel);
+  for (const sym of parsedSymbols) symbolTable.add(sym.filePath, sym.name, sym.nodeId, sym.type);
+  await annotateParsedFiles(parseableFiles, nodesByFile, chunkErrors);
+  if (listener?.onSymbol) for (const node of addedNodes) listener.onSymbol(node);
+  errors?.push(...chunkErrors);
+
+  if (listener?.onFileDone) {
+    const fileErrors = indexFileErrors(chunkErrors);
+    for (const file of parseableFiles) listener.onFileDone(file.path, fileErrors.get(file.path));
   }
 
   // Final progress
   onFileProgress?.(total, total, 'done');
-  return { imports: allImports, calls: allCalls, heritage: allHeritage };
+  return { imports: allImports, calls: allCalls, heritage: allHeritage, typeRefs: allTypeRefs };
 };
 
 // ============================================================================
@@ -234,7 +324,9 @@ const processParsingSequential = async (
   files: { path: string; content: string }[],
   symbolTable: SymbolTable,
   astCache: ASTCache,
-  onFileProgress?: FileProgressCallback
+  onFileProgress?: FileProgressCallback,
+  errors?: FileParseError[],
+  listener?: ParseListener,
 ) => {
   const parser = await loadParser();
   const total = files.length;
@@ -250,127 +342,178 @@ const processParsingSequential = async (
 
     if (!language) continue;
 
-    // Skip very large files — they can crash tree-sitter or cause OOM
-    if (file.content.length > 512 * 1024) continue;
-
-    await loadLanguage(language, file.path);
-
-    let tree;
+    const fileErrors: FileParseError[] = [];
+    const fileNodes: GraphNode[] = [];
+    let tree: Parser.Tree | undefined;
     try {
-      tree = parser.parse(file.content, undefined, { bufferSize: 1024 * 256 });
-    } catch (parseError) {
-      console.warn(`Skipping unparseable file: ${file.path}`);
-      continue;
-    }
+      // Skip very large files — they can crash tree-sitter or cause OOM
+      if (file.content.length > 512 * 1024) {
+        fileErrors.push({ filePath: file.path, stage: 'parse', message: 'larger than 512KB, not parsed' });
+        continue;
+      }
 
-    astCache.set(file.path, tree);
+      await loadLanguage(language, file.path);
 
-    const queryString = LANGUAGE_QUERIES[language];
-    if (!queryString) {
-      continue;
-    }
-
-    let query;
-    let matches;
-    try {
-      const language = parser.getLanguage();
-      query = new Parser.Query(language, queryString);
-      matches = query.matches(tree.rootNode);
-    } catch (queryError) {
-      console.warn(`Query error for ${file.path}:`, queryError);
-      continue;
-    }
+      try {
+        tree = parser.parse(file.content, undefined, { bufferSize: 1024 * 256 });
+      } catch (parseError) {
+        console.warn(`Skipping unparseable file: ${file.path}`);
+        fileErrors.push({ filePath: file.path, stage: 'parse', message: errorMessage(parseError) });
+        continue;
+      }
 
-    matches.forEach(match => {
-      const captureMap: Record<string, any> = {};
+      astCache.set(file.path, tree);
+      if (tree.rootNode.hasError) {
+        fileErrors.push({ filePath: file.path, stage: 'syntax', message: 'syntax errors (partially indexed)' });
+      }
 
-      match.captures.forEach(c => {
-        captureMap[c.name] = c.node;
-      });
+      let goCallContext: GoCallContextExtractor | undefined;
+      if (language === SupportedLanguages.Go) {
+        const imports = extractGoImports(tree.rootNode);
+        const fileNode = graph.getNode(generateId('File', file.path));
+        if (fileNode) fileNode.properties.imports = imports;
+        goCallContext = createGoCallContextExtractor(tree.rootNode, imports);
+      }
 
-      if (captureMap['import']) {
-        return;
+      const queryString = LANGUAGE_QUERIES[language];
+      if (!queryString) {
+        continue;
       }
 
-      if (captureMap['call']) {
-        return;
+      let query;
+      let matches;
+      try {
+        const language = parser.getLanguage();
+        query = new Parser.Query(language, queryString);
+        matches = query.matches(tree.rootNode);
+      } catch (queryError) {
+        console.warn(`Query error for ${file.path}:`, queryError);
+        fileErrors.push({ filePath: file.path, stage: 'query', message: errorMessage(queryError) });
+        continue;
       }
 
-      const nameNode = captureMap['name'];
-      if (!nameNode) return;
-
-      const nodeName = nameNode.text;
-
-      let nodeLabel = 'CodeElement';
-
-      if (captureMap['definition.function']) nodeLabel = 'Function';
-      else if (captureMap['definition.class']) nodeLabel = 'Class';
-      else if (captureMap['definition.interface']) nodeLabel = 'Interface';
-      else if (captureMap['definition.method']) nodeLabel = 'Method';
-      else if (captureMap['definition.struct']) nodeLabel = 'Struct';
-      else if (captureMap['definition.enum']) nodeLabel = 'Enum';
-      else if (captureMap['definition.namespace']) nodeLabel = 'Namespace';
-      else if (captureMap['definition.module']) nodeLabel = 'Module';
-      else if (captureMap['definition.trait']) nodeLabel = 'Trait';
-      else if (captureMap['definition.impl']) nodeLabel = 'Impl';
-      else if (captureMap['definition.type']) nodeLabel = 'TypeAlias';
-      else if (captureMap['definition.const']) nodeLabel = 'Const';
-      else if (captureMap['definition.static']) nodeLabel = 'Static';
-      else if (captureMap['definition.typedef']) nodeLabel = 'Typedef';
-      else if (captureMap['definition.macro']) nodeLabel = 'Macro';
-      else if (captureMap['definition.union']) nodeLabel = 'Union';
-      else if (captureMap['definition.property']) nodeLabel = 'Property';
-      else if (captureMap['definition.record']) nodeLabel = 'Record';
-      else if (captureMap['definition.delegate']) nodeLabel = 'Delegate';
-      else if (captureMap['definition.annotation']) nodeLabel = 'Annotation';
-      else if (captureMap['definition.constructor']) nodeLabel = 'Constructor';
-      else if (captureMap['definition.template']) nodeLabel = 'Template';
-
-      const nodeId = generateId(nodeLabel, `${file.path}:${nodeName}`);
-
-      const node: GraphNode = {
-        id: nodeId,
-        label: nodeLabel as any,
-        properties: (() => {
-          const definitionNode = getDefinitionNodeFromCaptures(captureMap);
-          const frameworkHint = definitionNode
-            ? detectFrameworkFromAST(language, definitionNode.text || '')
-            : null;
-
-          return {
-          name: nodeName,
-          filePath: file.path,
-          startLine: nameNode.startPosition.row,
-          endLine: nameNode.endPosition.row,
-          language: language,
-          isExported: isNodeExported(nameNode, nodeName, language),
-          ...(frameworkHint ? {
-            astFrameworkMultiplier: frameworkHint.entryPointMultiplier,
-            astFrameworkReason: frameworkHint.reason,
-          } : {}),
+      try {
+        const toByte = createByteOffsetMapper(file.content);
+
+        const claimedIds = new Map<string, number>();
+        const buildConstraint = language === SupportedLanguages.Go ? fileConstraintExpression(file.path, file.content) : undefined;
+        matches.forEach(match => {
+          const captureMap: Record<string, any> = {};
+
+          match.captures.forEach(c => {
+            captureMap[c.name] = c.node;
+          });
+
+          if (captureMap['import']) {
+            return;
+          }
+
+          if (captureMap['call']) {
+            return;
+          }
+
+          const nameNode = captureMap['name'];
+          if (!nameNode) return;
+
+          const nodeName = nameNode.text;
+
+          let nodeLabel = 'CodeElement';
+
+          if (captureMap['definition.function']) nodeLabel = 'Function';
+          else if (captureMap['definition.class']) nodeLabel = 'Class';
+          else if (captureMap['definition.interface']) nodeLabel = 'Interface';
+          else if (captureMap['definition.method']) nodeLabel = 'Method';
+          else if (captureMap['definition.struct']) nodeLabel = 'Struct';
+          else if (captureMap['definition.enum']) nodeLabel = 'Enum';
+          else if (captureMap['definition.namespace']) nodeLabel = 'Namespace';
+          else if (captureMap['definition.module']) nodeLabel = 'Module';
+          else if (captureMap['definition.trait']) nodeLabel = 'Trait';
+          else if (captureMap['definition.impl']) nodeLabel = 'Impl';
+          else if (captureMap['definition.type']) nodeLabel = 'TypeAlias';
+          else if (captureMap['definition.const']) nodeLabel = 'Const';
+          else if (captureMap['definition.static']) nodeLabel = 'Static';
+          else if (captureMap['definition.typedef']) nodeLabel = 'Typedef';
+          else if (captureMap['definition.macro']) nodeLabel = 'Macro';
+          else if (captureMap['definition.union']) nodeLabel = 'Union';
+          else if (captureMap['definition.property']) nodeLabel = 'Property';
+          else if (captureMap['definition.record']) nodeLabel = 'Record';
+          else if (captureMap['definition.delegate']) nodeLabel = 'Delegate';
+          else if (captureMap['definition.annotation']) nodeLabel = 'Annotation';
+          else if (captureMap['definition.constructor']) nodeLabel = 'Constructor';
+          else if (captureMap['definition.template']) nodeLabel = 'Template';
+
+          if (language === SupportedLanguages.Go && isRedundantGoTypeMatch(nameNode, nodeLabel)) return;
+
+          // Ordinals only for Go, where findEnclosingFunction rebuilds them
+          const nodeId = language === SupportedLanguages.Go
+            ? claimSymbolId(claimedIds, generateId(nodeLabel, `${file.path}:${goSymbolIdName(nameNode, nodeLabel)}`), nameNode.startIndex)
+            : generateId(nodeLabel, `${file.path}:${nodeName}`);
+          if (!nodeId) return;
+
+          const node: GraphNode = {
+            id: nodeId,
+            label: nodeLabel as any,
+            properties: (() => {
+              const definitionNode = getDefinitionNodeFromCaptures(captureMap);
+              const frameworkHint = definitionNode
+                ? detectFrameworkFromAST(language, definitionNode.text || '')
+                : null;
+              const docComment = extractDocComment(nameNode, language);
+
+              return {
+              name: nodeName,
+              filePath: file.path,
+              startLine: nameNode.startPosition.row,
+              endLine: nameNode.endPosition.row,
+              ...(definitionNode ? { definitionEndLine: definitionNode.endPosition.row } : {}),
+              ...getSymbolPosition(file.content, toByte, nameNode, definitionNode),
+              ...(definitionNode ? { bodyHash: getBodyHash(definitionNode, nameNode) } : {}),
+              language: language,
+              isExported: isNodeExported(nameNode, nodeName, language),
+              ...(frameworkHint ? {
+                astFrameworkMultiplier: frameworkHint.entryPointMultiplier,
+                astFrameworkReason: frameworkHint.reason,
+              } : {}),
+              ...(docComment ? { docComment } : {}),
+              ...(language === SupportedLanguages.Go ? extractGoSymbolMetadata(nameNode, nodeLabel, file.path, goCallContext) : {}),
+              ...(buildConstraint ? { buildConstraint } : {}),
+              };
+            })()
           };
-        })()
-      };
 
-      graph.addNode(node);
+          graph.addNode(node);
+          fileNodes.push(node);
 
-      symbolTable.add(file.path, nodeName, nodeId, nodeLabel);
+          symbolTable.add(file.path, nodeName, nodeId, nodeLabel);
 
-      const fileId = generateId('File', file.path);
+          const fileId = generateId('File', file.path);
 
-      const relId = generateId('DEFINES', `${fileId}->${nodeId}`);
+          const relId = generateId('DEFINES', `${fileId}->${nodeId}`);
 
-      const relationship: GraphRelationship = {
-        id: relId,
-        sourceId: fileId,
-        targetId: nodeId,
-        type: 'DEFINES',
-        confidence: 1.0,
-        reason: '',
-      };
+          const relationship: GraphRelationship = {
+            id: relId,
+            sourceId: fileId,
+            targetId: nodeId,
+            type: 'DEFINES',
+            confidence: 1.0,
+            reason: '',
+          };
 
-      graph.addRelationship(relationship);
-    });
+          graph.addRelationship(relationship);
+        });
+      } catch (extractError) {
+        fileErrors.push({ filePath: file.path, stage: 'extract', message: errorMessage(extractError) });
+      }
+    } finally {
+      // Listeners run here, outside the extract try: their exceptions abort
+      // the run instead of passing for extract errors
+      if (tree && hasAnnotators(language)) {
+        annotateSymbols({ path: file.path, content: file.content, language, rootNode: tree.rootNode }, fileNodes, fileErrors);
+      }
+      errors?.push(...fileErrors);
+      if (listener?.onSymbol) for (const node of fileNodes) listener.onSymbol(node);
+      listener?.onFileDone?.(file.path, indexFileErrors(fileErrors).get(file.path));
+    }
   }
 };
 
@@ -385,16 +528,27 @@ export const processParsing = async (
   astCache: ASTCache,
   onFileProgress?: FileProgressCallback,
   workerPool?: WorkerPool,
+  /** Receives files that didn't parse cleanly; parsing continues past them */
+  errors?: FileParseError[],
+  listener?: ParseListener,
 ): Promise<WorkerExtractedData | null> => {
+  // Lets later readers (snippets) tell whether a file changed since parsing
+  for (const file of files) {
+    const fileNode = graph.getNode(generateId('File', file.path));
+    if (!fileNode) continue;
+    fileNode.properties.contentHash = hashContent(file.content);
+    const buildConstraint = isGoSourceFile(file.path) ? fileConstraintExpression(file.path, file.content) : undefined;
+    if (buildConstraint) fileNode.properties.buildConstraint = buildConstraint;
+  }
+
   if (workerPool) {
-    try {
-      return await processParsingWithWorkers(graph, files, symbolTable, astCache, workerPool, onFileProgress);
-    } catch (err) {
-      console.warn('Worker pool parsing failed, falling back to sequential:', err instanceof Error ? err.message : err);
-    }
+    const extracted = await processParsingWithWorkers(
+      graph, files, symbolTable, astCache, workerPool, onFileProgress, errors, listener,
+    );
+    if (extracted) return extracted;
   }
 
   // Fallback: sequential parsing (no pre-extracted data)
-  await processParsingSequential(graph, files, symbolTable, astCache, onFileProgress);
+  await processParsingSequential(graph, files, symbolTable, astCache, onFileProgress, errors, listener);
   return null;
 };
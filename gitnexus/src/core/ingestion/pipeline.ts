@@ -1,17 +1,25 @@
 import { createKnowledgeGraph } from '../graph/graph.js';
 import { processStructure } from './structure-processor.js';
-import { processParsing } from './parsing-processor.js';
+import { processParsing, ParseListener } from './parsing-processor.js';
 import { processImports, processImportsFromExtracted, createImportMap, buildImportResolutionContext } from './import-processor.js';
-import { processCalls, processCallsFromExtracted } from './call-processor.js';
+import { processCalls, processCallsFromExtracted, ExternalCallMap } from './call-processor.js';
 import { processHeritage, processHeritageFromExtracted } from './heritage-processor.js';
+import { processGoInterfaces, processGoImplementations } from './go-interface-processor.js';
+import { loadGoModules, processGoModules, annotateGoPackages, isGoModPath } from './go-module-processor.js';
+import { AnalysisScope, createScopeFilter, isWholeRepoScope } from './analysis-scope.js';
+import { processTypeUsages, processTypeUsagesFromExtracted, ExtractedTypeRef } from './type-usage-processor.js';
 import { processCommunities } from './community-processor.js';
 import { processProcesses } from './process-processor.js';
 import { createSymbolTable } from './symbol-table.js';
 import { createASTCache } from './ast-cache.js';
 import { PipelineProgress, PipelineResult } from '../../types/pipeline.js';
-import { walkRepositoryPaths, readFileContents } from './filesystem-walker.js';
+import { createWorkingTreeSource, createCommitSource, createFSSource, FileSource, ScannedFile, ScanOptions } from './filesystem-walker.js';
+import { VirtualFS, subFS, toFSPath } from './virtual-fs.js';
 import { getLanguageFromFilename } from './utils.js';
+import { getLanguageParser, processRegisteredParsers } from './language-parsers.js';
 import { createWorkerPool, WorkerPool } from './workers/worker-pool.js';
+import { annotateFiles, hasFileAnnotators } from './annotators.js';
+import type { FileParseError } from './workers/parse-worker.js';
 
 const isDev = process.env.NODE_ENV === 'development';
 
@@ -24,14 +32,70 @@ const CHUNK_BYTE_BUDGET = 20 * 1024 * 1024; // 20MB
 /** Max AST trees to keep in LRU cache */
 const AST_CACHE_CAP = 50;
 
+/** Group files into byte-budget chunks, preserving order */
+const chunkByBytes = (files: ScannedFile[]): string[][] => {
+  const chunks: string[][] = [];
+  let currentChunk: string[] = [];
+  let currentBytes = 0;
+  for (const file of files) {
+    if (currentChunk.length > 0 && currentBytes + file.size > CHUNK_BYTE_BUDGET) {
+      chunks.push(currentChunk);
+      currentChunk = [];
+      currentBytes = 0;
+    }
+    currentChunk.push(file.path);
+    currentBytes += file.size;
+  }
+  if (currentChunk.length > 0) chunks.push(currentChunk);
+  return chunks;
+};
+
+export interface PipelineOptions {
+  /**
+   * Analyze this commit (full sha) straight from the git object store
+   * instead of the working tree. See resolveCommit for refs.
+   */
+  commit?: string;
+  /**
+   * Read files from this source instead of the working tree or `commit`
+   * (see runPipelineFromFS)
+   */
+  source?: FileSource;
+  /**
+   * Parse worker threads. Defaults to the CPU count minus one (capped at 8,
+   * see createWorkerPool); 0 parses sequentially on the main thread.
+   */
+  workers?: number;
+  /** .gitignore handling, include/exclude globs, generated-file skipping */
+  scan?: ScanOptions;
+  /**
+   * Analyze only this subtree or these Go packages; calls leaving the scope
+   * are recorded as 'out-of-scope' external calls (see analysis-scope)
+   */
+  scope?: AnalysisScope;
+  /**
+   * Streaming hooks, for live output on large repos: each symbol as it is
+   * added and each file as it finishes. Main thread only; see ParseListener.
+   */
+  onSymbol?: ParseListener['onSymbol'];
+  onFileDone?: ParseListener['onFileDone'];
+}
+
 export const runPipelineFromRepo = async (
   repoPath: string,
-  onProgress: (progress: PipelineProgress) => void
+  onProgress: (progress: PipelineProgress) => void,
+  options: PipelineOptions = {},
 ): Promise<PipelineResult> => {
+  const source = options.source ?? (options.commit
+    ? createCommitSource(repoPath, options.commit, options.scan)
+    : createWorkingTreeSource(repoPath, options.scan));
   const graph = createKnowledgeGraph();
   const symbolTable = createSymbolTable();
   let astCache = createASTCache(AST_CACHE_CAP);
   const importMap = createImportMap();
+  const externalCalls: ExternalCallMap = new Map();
+
+  const listener: ParseListener = { onSymbol: options.onSymbol, onFileDone: options.onFileDone };
 
   const cleanup = () => {
     astCache.clear();
@@ -46,7 +110,9 @@ export const runPipelineFromRepo = async (
       message: 'Scanning repository...',
     });
 
-    const scannedFiles = await walkRepositoryPaths(repoPath, (current, total, filePath) => {
+    // Sorted so node/edge insertion order, and everything derived from it
+    // (community detection, exports), doesn't depend on directory listing order
+    const repoFiles = (await source.scan((current, total, filePath) => {
       const scanProgress = Math.round((current / total) * 15);
       onProgress({
         phase: 'extracting',
@@ -55,7 +121,15 @@ export const runPipelineFromRepo = async (
         detail: filePath,
         stats: { filesProcessed: current, totalFiles: total, nodesCreated: graph.nodeCount },
       });
-    });
+    })).sort((a, b) => (a.path < b.path ? -1 : a.path > b.path ? 1 : 0));
+
+    // Every go.mod, in scope or not, so resolution knows each module's root and replaces
+    const goModules = await loadGoModules(repoFiles.map(f => f.path), source.readFile);
+    const inScope = isWholeRepoScope(options.scope) ? null : createScopeFilter(options.scope!, goModules);
+    const scannedFiles = inScope ? repoFiles.filter(f => inScope(f.path)) : repoFiles;
+    if (inScope && scannedFiles.every(f => isGoModPath(f.path))) {
+      console.warn('  No files in the analysis scope');
+    }
 
     const totalFiles = scannedFiles.length;
 
@@ -76,6 +150,7 @@ export const runPipelineFromRepo = async (
 
     const allPaths = scannedFiles.map(f => f.path);
     processStructure(graph, allPaths);
+    processGoModules(graph, goModules);
 
     onProgress({
       phase: 'structure',
@@ -88,23 +163,24 @@ export const runPipelineFromRepo = async (
     // Group parseable files into byte-budget chunks so only ~20MB of source
     // is in memory at a time. Each chunk is: read → parse → extract → free.
 
-    const parseableScanned = scannedFiles.filter(f => getLanguageFromFilename(f.path));
+    // Registered parsers claim their extensions before the built-in languages
+    const pluginScanned = scannedFiles.filter(f => getLanguageParser(f.path));
+    const parseableScanned = scannedFiles.filter(f => !getLanguageParser(f.path) && getLanguageFromFilename(f.path));
     const totalParseable = parseableScanned.length;
 
-    // Build byte-budget chunks
-    const chunks: string[][] = [];
-    let currentChunk: string[] = [];
-    let currentBytes = 0;
-    for (const file of parseableScanned) {
-      if (currentChunk.length > 0 && currentBytes + file.size > CHUNK_BYTE_BUDGET) {
-        chunks.push(currentChunk);
-        currentChunk = [];
-        currentBytes = 0;
-      }
-      currentChunk.push(file.path);
-      currentBytes += file.size;
+    // Registered language parsers run first (main thread) so their symbols
+    // are in the symbol table when built-in chunks resolve calls
+    for (const chunkPaths of chunkByBytes(pluginScanned)) {
+      const chunkContents = await source.read(chunkPaths);
+      await processRegisteredParsers(
+        graph,
+        chunkPaths.filter(p => chunkContents.has(p)).map(p => ({ path: p, content: chunkContents.get(p)! })),
+        symbolTable,
+        listener,
+      );
     }
-    if (currentChunk.length > 0) chunks.push(currentChunk);
+
+    const chunks = chunkByBytes(parseableScanned);
 
     const numChunks = chunks.length;
 
@@ -122,13 +198,18 @@ export const runPipelineFromRepo = async (
 
     // Create worker pool once, reuse across chunks
     let workerPool: WorkerPool | undefined;
-    try {
-      const workerUrl = new URL('./workers/parse-worker.js', import.meta.url);
-      workerPool = createWorkerPool(workerUrl);
-    } catch (err) {
-      // Worker pool creation failed — sequential fallback
+    if (options.workers !== 0) {
+      try {
+        const workerUrl = new URL('./workers/parse-worker.js', import.meta.url);
+        workerPool = createWorkerPool(workerUrl, options.workers);
+      } catch (err) {
+        // Worker pool creation failed — sequential fallback
+      }
     }
 
+    // Per-file failures; one bad file never aborts the run
+    const parseErrors: FileParseError[] = [];
+
     let filesParsedSoFar = 0;
 
     // AST cache sized for one chunk (sequential fallback uses it for import/call/heritage)
@@ -145,13 +226,15 @@ export const runPipelineFromRepo = async (
     // are already registered). This trades ~5% cross-chunk resolution accuracy for
     // 200-400MB less memory — critical for Linux-kernel-scale repos.
     const sequentialChunkPaths: string[][] = [];
+    // Kept across chunks and resolved at the end (see type-usage-processor)
+    const typeRefs: ExtractedTypeRef[] = [];
 
     try {
       for (let chunkIdx = 0; chunkIdx < numChunks; chunkIdx++) {
         const chunkPaths = chunks[chunkIdx];
 
         // Read content for this chunk only
-        const chunkContents = await readFileContents(repoPath, chunkPaths);
+        const chunkContents = await source.read(chunkPaths);
         const chunkFiles = chunkPaths
           .filter(p => chunkContents.has(p))
           .map(p => ({ path: p, content: chunkContents.get(p)! }));
@@ -171,21 +254,24 @@ export const runPipelineFromRepo = async (
             });
           },
           workerPool,
+          parseErrors,
+          listener,
         );
 
         if (chunkWorkerData) {
           // Imports
-          await processImportsFromExtracted(graph, allPathObjects, chunkWorkerData.imports, importMap, undefined, repoPath, importCtx);
+          await processImportsFromExtracted(graph, allPathObjects, chunkWorkerData.imports, importMap, undefined, repoPath, importCtx, source.readFile);
           // Calls — resolve immediately, then free the array
           if (chunkWorkerData.calls.length > 0) {
-            await processCallsFromExtracted(graph, chunkWorkerData.calls, symbolTable, importMap);
+            await processCallsFromExtracted(graph, chunkWorkerData.calls, symbolTable, importMap, undefined, externalCalls);
           }
           // Heritage — resolve immediately, then free
           if (chunkWorkerData.heritage.length > 0) {
             await processHeritageFromExtracted(graph, chunkWorkerData.heritage, symbolTable);
           }
+          for (const ref of chunkWorkerData.typeRefs) typeRefs.push(ref);
         } else {
-          await processImports(graph, chunkFiles, astCache, importMap, undefined, repoPath, allPaths);
+          await processImports(graph, chunkFiles, astCache, importMap, undefined, repoPath, allPaths, source.readFile);
           sequentialChunkPaths.push(chunkPaths);
         }
 
@@ -199,17 +285,30 @@ export const runPipelineFromRepo = async (
       await workerPool?.terminate();
     }
 
+    // File annotators look symbols up across the graph: run them once every chunk is in
+    if (hasFileAnnotators()) {
+      for (const chunkPaths of chunks) {
+        const chunkContents = await source.read(chunkPaths);
+        await annotateFiles(graph, chunkPaths
+          .filter(p => chunkContents.has(p))
+          .map(p => ({ path: p, content: chunkContents.get(p)! })), parseErrors);
+      }
+    }
+
     // Sequential fallback chunks: re-read source for call/heritage resolution
     for (const chunkPaths of sequentialChunkPaths) {
-      const chunkContents = await readFileContents(repoPath, chunkPaths);
+      const chunkContents = await source.read(chunkPaths);
       const chunkFiles = chunkPaths
         .filter(p => chunkContents.has(p))
         .map(p => ({ path: p, content: chunkContents.get(p)! }));
       astCache = createASTCache(chunkFiles.length);
-      await processCalls(graph, chunkFiles, astCache, symbolTable, importMap);
+      await processCalls(graph, chunkFiles, astCache, symbolTable, importMap, undefined, externalCalls);
       await processHeritage(graph, chunkFiles, astCache, symbolTable);
+      await processTypeUsages(graph, chunkFiles, astCache, symbolTable);
       astCache.clear();
     }
+    await processTypeUsagesFromExtracted(graph, typeRefs, symbolTable);
+    typeRefs.length = 0;
 
     // Free import resolution context — suffix index + resolve cache no longer needed
     // (allPathObjects and importCtx hold ~94MB+ for large repos)
@@ -217,6 +316,14 @@ export const runPipelineFromRepo = async (
     importCtx.resolveCache.clear();
     (importCtx as any).suffixIndex = null;
     (importCtx as any).normalizedFileList = null;
+    importCtx.goPackageIndex = undefined;
+
+    annotateGoPackages(graph);
+
+    // Go interfaces: flatten embedded method sets across files, then match
+    // concrete types against them (structural satisfaction)
+    processGoInterfaces(graph);
+    processGoImplementations(graph);
 
     if (isDev) {
       let importsCount = 0;
@@ -346,9 +453,32 @@ export const runPipelineFromRepo = async (
 
     astCache.clear();
 
-    return { graph, repoPath, totalFileCount: totalFiles, communityResult, processResult };
+    return {
+      graph, repoPath, totalFileCount: totalFiles, communityResult, processResult,
+      externalCalls: [...externalCalls.values()],
+      parseErrors: parseErrors.sort((a, b) =>
+        (a.filePath < b.filePath ? -1 : a.filePath > b.filePath ? 1 : 0) || (a.stage < b.stage ? -1 : a.stage > b.stage ? 1 : 0)),
+      ...(options.commit ? { commit: options.commit } : {}),
+    };
   } catch (error) {
     cleanup();
     throw error;
   }
 };
+
+/**
+ * Analyze the files under `root` in a VirtualFS: an in-memory map, a zip
+ * archive, a git tree (see virtual-fs). File paths in the graph are relative
+ * to `root`, slash-separated on every platform; the result's repoPath is
+ * `root` as an FS path.
+ */
+export const runPipelineFromFS = async (
+  fsys: VirtualFS,
+  root: string,
+  onProgress: (progress: PipelineProgress) => void,
+  options: Omit<PipelineOptions, 'commit' | 'source'> = {},
+): Promise<PipelineResult> => {
+  const fsRoot = toFSPath(root);
+  const source = createFSSource(subFS(fsys, fsRoot), options.scan);
+  return runPipelineFromRepo(fsRoot, onProgress, { ...options, source });
+};
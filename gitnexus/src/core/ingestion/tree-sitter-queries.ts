@@ -1,4 +1,5 @@
 import { SupportedLanguages } from '../../config/supported-languages.js';
+import { GO_PREDECLARED_TYPES } from './go-metadata.js';
 
 /* 
  * Tree-sitter queries for extracting code definitions.
@@ -200,6 +201,9 @@ export const C_QUERIES = `
 (call_expression function: (field_expression field: (field_identifier) @call.name)) @call
 `;
 
+/** Matches a predeclared Go type name, for `#match?` */
+const GO_PREDECLARED_TYPE_PATTERN = `^(${[...GO_PREDECLARED_TYPES].join('|')})$`;
+
 // Go queries - works with tree-sitter-go
 export const GO_QUERIES = `
 ; Functions & Methods
@@ -210,6 +214,12 @@ export const GO_QUERIES = `
 (type_declaration (type_spec name: (type_identifier) @name type: (struct_type))) @definition.struct
 (type_declaration (type_spec name: (type_identifier) @name type: (interface_type))) @definition.interface
 (type_declaration (type_spec name: (type_identifier) @name)) @definition.type
+(type_declaration (type_alias name: (type_identifier) @name)) @definition.type
+
+; Package-level constants & variables (grouped var specs may sit in a var_spec_list)
+(source_file (const_declaration (const_spec name: (identifier) @name) @definition.const))
+(source_file (var_declaration (var_spec name: (identifier) @name) @definition.static))
+(source_file (var_declaration (_ (var_spec name: (identifier) @name) @definition.static)))
 
 ; Imports
 (import_declaration (import_spec path: (interpreted_string_literal) @import.source)) @import
@@ -218,6 +228,13 @@ export const GO_QUERIES = `
 ; Calls
 (call_expression function: (identifier) @call.name) @call
 (call_expression function: (selector_expression field: (field_identifier) @call.name)) @call
+; Generic instantiation that parses as an index: Map[int](xs). Only with a predeclared type name as
+; the index, which a map or slice index practically never is: Map[User](xs) reads the same as
+; handlers[name](w, r) and is left out. Map[int, string](xs) uses type_arguments, matched above
+(call_expression function: (index_expression operand: (identifier) @call.name index: (identifier) @_type_arg
+  (#match? @_type_arg "${GO_PREDECLARED_TYPE_PATTERN}"))) @call
+(call_expression function: (index_expression operand: (selector_expression field: (field_identifier) @call.name) index: (identifier) @_type_arg
+  (#match? @_type_arg "${GO_PREDECLARED_TYPE_PATTERN}"))) @call
 `;
 
 // C++ queries - works with tree-sitter-cpp
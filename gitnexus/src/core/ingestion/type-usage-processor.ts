@@ -0,0 +1,121 @@
+/**
+ * Type Usage Processor
+ *
+ * Resolves Go type references (see extractGoTypeReferences) to the type's
+ * node and records them as USES edges from the enclosing declaration, one
+ * edge per (declaration, type) pair with every reference site on it.
+ *
+ * Resolution follows Go scoping: an unqualified name is a type in the same
+ * package (directory), `pkg.T` is T in the imported package (located through
+ * the repo's go.mod files, see graph/modules.ts). References to
+ * types outside the repo are dropped. Unlike calls, references are resolved
+ * once after every chunk is parsed, so a type in a later chunk still counts.
+ */
+
+import { KnowledgeGraph, TypeUsageSite } from '../graph/types.js';
+import { getGoModules, createGoModuleResolver, GoModuleResolver } from '../graph/modules.js';
+import { SymbolTable } from './symbol-table.js';
+import { ASTCache } from './ast-cache.js';
+import { loadParser, loadLanguage } from '../tree-sitter/parser-loader.js';
+import { generateId, goPackageDir } from '../../lib/utils.js';
+import { getLanguageFromFilename, yieldToEventLoop } from './utils.js';
+import { SupportedLanguages } from '../../config/supported-languages.js';
+import { extractGoTypeReferences, GoTypeReference } from './go-metadata.js';
+
+/** A type reference as extracted at parse time (workers or sequential) */
+export interface ExtractedTypeRef extends GoTypeReference {
+  filePath: string;
+}
+
+const GO_TYPE_LABELS = new Set(['Struct', 'Interface', 'TypeAlias']);
+
+/** Is repo directory `dir` the package at import path `pkg`? By path shape without go.mod files */
+const goPackageMatches = (goModules: GoModuleResolver, pkg: string, fromFile: string, dir: string): boolean =>
+  goModules.modules.length > 0
+    ? goModules.resolveImportDir(pkg, fromFile) === dir
+    : dir !== '' && (pkg === dir || pkg.endsWith('/' + dir));
+
+const resolveTypeRef = (
+  ref: ExtractedTypeRef,
+  symbolTable: SymbolTable,
+  goModules: GoModuleResolver,
+): { nodeId: string; confidence: number; reason: string } | null => {
+  const defs = symbolTable.lookupFuzzy(ref.typeName)
+    .filter(d => GO_TYPE_LABELS.has(d.type) && d.filePath.endsWith('.go'));
+  if (defs.length === 0) return null;
+
+  if (ref.qualifier) {
+    if (!ref.qualifierPackage) return null;
+    const def = defs.find(d => goPackageMatches(goModules, ref.qualifierPackage!, ref.filePath, goPackageDir(d.filePath)));
+    return def ? { nodeId: def.nodeId, confidence: 0.9, reason: 'import-resolved' } : null;
+  }
+  const fileDir = goPackageDir(ref.filePath);
+  const def = defs.find(d => goPackageDir(d.filePath) === fileDir);
+  return def ? { nodeId: def.nodeId, confidence: 0.95, reason: 'same-package' } : null;
+};
+
+/** Add USES edges for resolved references; repeat sites on an edge are ignored */
+export const processTypeUsagesFromExtracted = async (
+  graph: KnowledgeGraph,
+  refs: ExtractedTypeRef[],
+  symbolTable: SymbolTable,
+) => {
+  const goModules = createGoModuleResolver(getGoModules(graph));
+  for (let i = 0; i < refs.length; i++) {
+    if (i % 5000 === 0) await yieldToEventLoop();
+    const ref = refs[i];
+    const resolved = resolveTypeRef(ref, symbolTable, goModules);
+    if (!resolved || !graph.getNode(ref.sourceId)) continue;
+
+    const site: TypeUsageSite = { line: ref.line, column: ref.column, usage: ref.usage, wrapping: ref.wrapping };
+    const relId = generateId('USES', `${ref.sourceId}->${resolved.nodeId}`);
+    const existing = graph.getRelationship(relId);
+    if (existing) {
+      const sites = existing.usageSites ?? (existing.usageSites = []);
+      if (!sites.some(s => s.line === site.line && s.column === site.column)) sites.push(site);
+      continue;
+    }
+    graph.addRelationship({
+      id: relId,
+      sourceId: ref.sourceId,
+      targetId: resolved.nodeId,
+      type: 'USES',
+      confidence: resolved.confidence,
+      reason: resolved.reason,
+      usageSites: [site],
+    });
+  }
+};
+
+/** Sequential path: extract references from cached (or re-parsed) ASTs */
+export const processTypeUsages = async (
+  graph: KnowledgeGraph,
+  files: { path: string; content: string }[],
+  astCache: ASTCache,
+  symbolTable: SymbolTable,
+) => {
+  const parser = await loadParser();
+  const refs: ExtractedTypeRef[] = [];
+
+  for (let i = 0; i < files.length; i++) {
+    const file = files[i];
+    if (i % 20 === 0) await yieldToEventLoop();
+    if (getLanguageFromFilename(file.path) !== SupportedLanguages.Go) continue;
+
+    let tree = astCache.get(file.path);
+    if (!tree) {
+      await loadLanguage(SupportedLanguages.Go, file.path);
+      try {
+        tree = parser.parse(file.content, undefined, { bufferSize: 1024 * 256 });
+      } catch {
+        continue;
+      }
+      astCache.set(file.path, tree);
+    }
+    for (const ref of extractGoTypeReferences(tree.rootNode, file.path)) {
+      refs.push({ ...ref, filePath: file.path });
+    }
+  }
+
+  await processTypeUsagesFromExtracted(graph, refs, symbolTable);
+};
@@ -1,4 +1,5 @@
 import { SupportedLanguages } from '../../config/supported-languages.js';
+import { hashContent } from '../../lib/utils.js';
 
 /**
  * Yield control to the event loop so spinners/progress can render.
@@ -6,6 +7,98 @@ import { SupportedLanguages } from '../../config/supported-languages.js';
  */
 export const yieldToEventLoop = (): Promise<void> => new Promise(resolve => setImmediate(resolve));
 
+/**
+ * Map string indices (UTF-16 code units — what tree-sitter's node bindings
+ * report as startIndex/endIndex) to UTF-8 byte offsets in `content`.
+ * ASCII-only content maps one-to-one; anything else builds a lookup table
+ * on first use.
+ */
+export const createByteOffsetMapper = (content: string): ((index: number) => number) => {
+  if (Buffer.byteLength(content, 'utf8') === content.length) return index => index;
+  let table: Uint32Array | null = null;
+  return index => {
+    if (!table) {
+      table = new Uint32Array(content.length + 1);
+      let bytes = 0;
+      for (let i = 0; i < content.length; i++) {
+        table[i] = bytes;
+        const code = content.charCodeAt(i);
+        // A surrogate pair is 4 bytes: count them all on the high half
+        if (code >= 0xd800 && code <= 0xdbff) bytes += 4;
+        else if (code >= 0xdc00 && code <= 0xdfff) bytes += 0;
+        else bytes += code < 0x80 ? 1 : code < 0x800 ? 2 : 3;
+      }
+      table[content.length] = bytes;
+    }
+    return table[Math.max(0, Math.min(index, content.length))];
+  };
+};
+
+/**
+ * Precise location of a definition: byte column of the name on its row, and
+ * the byte span of the whole definition node (body included). Falls back to
+ * the name's span when there is no definition node.
+ */
+export const getSymbolPosition = (
+  content: string,
+  toByte: (index: number) => number,
+  nameNode: any,
+  definitionNode: any | null,
+): { startColumn: number; startByte: number; endByte: number } => {
+  const lineStart = content.lastIndexOf('\n', nameNode.startIndex - 1) + 1;
+  const span = definitionNode ?? nameNode;
+  return {
+    startColumn: toByte(nameNode.startIndex) - toByte(lineStart),
+    startByte: toByte(span.startIndex),
+    endByte: toByte(span.endIndex),
+  };
+};
+
+/** Go literals gofmt may re-case (`0X1F` -> `0x1F`, `1E5` -> `1e5`) without changing the value */
+const NUMBER_LITERALS = new Set(['int_literal', 'float_literal', 'imaginary_literal']);
+
+/**
+ * Hash of a definition's syntax tree, for telling whether an implementation
+ * changed and for finding identical ones. The tree is re-printed as
+ * `(type leaf ...)` and hashed (sha256), which leaves out:
+ *   - whitespace, line breaks and indentation (structure is in the tree)
+ *   - comments
+ *   - separators gofmt adds or removes: commas (a trailing `,` in a
+ *     multi-line list) and `;` between statements (kept in for clauses)
+ *   - the symbol's own name, so a copy under another name hashes the same
+ * Every other token is kept, so any edit a compiler would see changes the
+ * hash. It depends only on the tree, so it's the same on every machine.
+ */
+export const getBodyHash = (definitionNode: any, nameNode?: any): string => {
+  const out: string[] = [];
+  const stack: any[] = [definitionNode];
+  while (stack.length > 0) {
+    const node = stack.pop();
+    if (typeof node === 'string') {
+      out.push(node);
+      continue;
+    }
+    if (node.type.includes('comment')) continue;
+    if (nameNode && node !== definitionNode && node.startIndex === nameNode.startIndex && node.endIndex === nameNode.endIndex) continue;
+    const children = node.children ?? [];
+    // String contents aren't all tokens of their own, so literals are kept whole
+    if (children.length === 0 || node.type.includes('string')) {
+      let text: string = node.text ?? '';
+      if (!text.trim() || text === ',' || (text === ';' && node.parent?.type !== 'for_clause')) continue;
+      if (NUMBER_LITERALS.has(node.type)) text = text.toLowerCase();
+      else if (node.type === 'raw_string_literal') text = text.replace(/\r/g, '');
+      out.push(node.isNamed === false ? text : `${node.type}:${text}`);
+      continue;
+    }
+    if (node.isNamed !== false) {
+      out.push(`(${node.type}`);
+      stack.push(')');
+    }
+    for (let i = children.length - 1; i >= 0; i--) stack.push(children[i]);
+  }
+  return hashContent(out.join(' '));
+};
+
 /**
  * Map file extension to SupportedLanguage enum
  */
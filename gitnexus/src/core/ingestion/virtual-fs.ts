@@ -0,0 +1,257 @@
+/**
+ * Virtual File Systems
+ *
+ * A minimal read-only file system interface, modeled on Go's io/fs, so the
+ * pipeline can analyze sources that aren't a directory on disk: an
+ * in-memory map (tests), a zip archive, or a git commit. See createFSSource
+ * for the FileSource on top and runPipelineFromFS for the entry point.
+ *
+ * Paths follow io/fs conventions on every platform: slash-separated,
+ * relative to the FS root, no `.`/`..`/empty elements, no leading or
+ * trailing slash, and `.` for the root itself (see isValidFSPath). Native
+ * paths (`src\\main.go` on Windows) must go through toFSPath first.
+ */
+
+import fs from 'fs/promises';
+import path from 'path';
+import zlib from 'zlib';
+import { listFilesAtCommit, readFilesAtCommit } from '../../storage/git.js';
+
+export interface VirtualFSEntry {
+  name: string;
+  isDirectory: boolean;
+  /** File size in bytes, when known without reading the file */
+  size?: number;
+}
+
+export interface VirtualFS {
+  /** Entries of a directory (`.` for the root); throws when there is no such directory */
+  readDir: (dir: string) => Promise<VirtualFSEntry[]>;
+  /** Content of a file; throws when there is no such file */
+  readFile: (filePath: string) => Promise<string | Buffer>;
+  /** Optional batch read for stores where one call beats many; missing paths are left out */
+  readFiles?: (filePaths: string[]) => Promise<Map<string, string>>;
+}
+
+/** io/fs ValidPath, plus: no backslashes, so native Windows paths can't pass as FS paths */
+export const isValidFSPath = (p: string): boolean => {
+  if (p === '.') return true;
+  if (!p || p.includes('\\')) return false;
+  return p.split('/').every(element => element !== '' && element !== '.' && element !== '..');
+};
+
+/** Slash-separated FS path for a native relative path (`.\\src\\a.go` -> `src/a.go`) */
+export const toFSPath = (nativePath: string): string => {
+  const normalized = path.posix.normalize(nativePath.replace(/\\/g, '/')).replace(/^(\.\/)+|\/+$/g, '');
+  return normalized === '' ? '.' : normalized;
+};
+
+/** Join FS paths: joinFSPath('.', 'a') = 'a', joinFSPath('a', 'b') = 'a/b' */
+export const joinFSPath = (dir: string, name: string): string => (dir === '.' || dir === '' ? name : `${dir}/${name}`);
+
+const checkPath = (p: string): void => {
+  if (!isValidFSPath(p)) throw new Error(`Invalid FS path: ${p}`);
+};
+
+/** Error as thrown by fs/promises for a missing path, so callers can check `code` */
+const notExist = (p: string): Error => Object.assign(new Error(`ENOENT: no such file or directory, ${p}`), { code: 'ENOENT' });
+
+// ============================================================================
+// FLAT FILE LISTS
+// ============================================================================
+
+/**
+ * An FS over a flat list of files, directories implied by the paths (as in
+ * zip archives and git trees). `read` returns null for a missing file.
+ */
+const createIndexedFS = (
+  files: Iterable<{ path: string; size?: number }>,
+  read: (filePath: string) => Promise<string | Buffer | null>,
+  readMany?: (filePaths: string[]) => Promise<Map<string, string>>,
+): VirtualFS => {
+  const dirs = new Map<string, Map<string, VirtualFSEntry>>([['.', new Map()]]);
+  const fileSet = new Set<string>();
+  for (const file of files) {
+    if (!isValidFSPath(file.path) || file.path === '.') continue;
+    fileSet.add(file.path);
+    const parts = file.path.split('/');
+    let dir = '.';
+    parts.forEach((name, i) => {
+      const isFile = i === parts.length - 1;
+      const entries = dirs.get(dir)!;
+      if (!entries.has(name)) {
+        entries.set(name, { name, isDirectory: !isFile, ...(isFile && file.size !== undefined ? { size: file.size } : {}) });
+      }
+      dir = joinFSPath(dir, name);
+      if (!isFile && !dirs.has(dir)) dirs.set(dir, new Map());
+    });
+  }
+
+  return {
+    readDir: async (dir) => {
+      checkPath(dir);
+      const entries = dirs.get(dir);
+      if (!entries) throw notExist(dir);
+      return [...entries.values()];
+    },
+    readFile: async (filePath) => {
+      checkPath(filePath);
+      const content = fileSet.has(filePath) ? await read(filePath) : null;
+      if (content === null) throw notExist(filePath);
+      return content;
+    },
+    ...(readMany ? {
+      readFiles: (filePaths: string[]) => readMany(filePaths.filter(p => isValidFSPath(p) && fileSet.has(p))),
+    } : {}),
+  };
+};
+
+/**
+ * In-memory FS from path -> content, like Go's fstest.MapFS. Directories
+ * are implied; invalid paths are ignored.
+ */
+export const createMemoryFS = (files: Record<string, string | Buffer> | Map<string, string | Buffer>): VirtualFS => {
+  const map = files instanceof Map ? files : new Map(Object.entries(files));
+  return createIndexedFS(
+    [...map].map(([p, content]) => ({ path: p, size: typeof content === 'string' ? Buffer.byteLength(content) : content.length })),
+    async (p) => map.get(p) ?? null,
+  );
+};
+
+/**
+ * Files of a git commit, read from the object store (see listFilesAtCommit).
+ * The tree is listed once, on first use.
+ */
+export const createCommitFS = (repoPath: string, commit: string): VirtualFS => {
+  let index: VirtualFS | null = null;
+  const load = (): VirtualFS => {
+    if (!index) {
+      index = createIndexedFS(
+        listFilesAtCommit(repoPath, commit),
+        async (p) => readFilesAtCommit(repoPath, commit, [p]).get(p) ?? null,
+        async (paths) => readFilesAtCommit(repoPath, commit, paths),
+      );
+    }
+    return index;
+  };
+  return {
+    readDir: (dir) => load().readDir(dir),
+    readFile: (filePath) => load().readFile(filePath),
+    readFiles: (filePaths) => load().readFiles!(filePaths),
+  };
+};
+
+// ============================================================================
+// DIRECTORIES
+// ============================================================================
+
+/** A directory on disk, like Go's os.DirFS; FS paths are mapped to native ones */
+export const createDirectoryFS = (dirPath: string): VirtualFS => {
+  const native = (p: string): string => {
+    checkPath(p);
+    return p === '.' ? dirPath : path.join(dirPath, ...p.split('/'));
+  };
+  return {
+    readDir: async (dir) => {
+      const entries = await fs.readdir(native(dir), { withFileTypes: true });
+      return entries
+        .filter(e => e.isDirectory() || e.isFile())
+        .map(e => ({ name: e.name, isDirectory: e.isDirectory() }));
+    },
+    readFile: (filePath) => fs.readFile(native(filePath)),
+  };
+};
+
+/** The subtree at `dir`, like Go's fs.Sub */
+export const subFS = (fsys: VirtualFS, dir: string): VirtualFS => {
+  checkPath(dir);
+  if (dir === '.') return fsys;
+  const full = (p: string): string => {
+    checkPath(p);
+    return p === '.' ? dir : `${dir}/${p}`;
+  };
+  return {
+    readDir: (p) => fsys.readDir(full(p)),
+    readFile: (p) => fsys.readFile(full(p)),
+    ...(fsys.readFiles ? {
+      readFiles: async (paths: string[]) => {
+        const prefix = `${dir}/`;
+        const contents = await fsys.readFiles!(paths.map(full));
+        return new Map([...contents].map(([p, content]) => [p.substring(prefix.length), content]));
+      },
+    } : {}),
+  };
+};
+
+// ============================================================================
+// ZIP ARCHIVES
+// ============================================================================
+
+const ZIP_END_OF_CENTRAL_DIRECTORY = 0x06054b50;
+const ZIP_CENTRAL_FILE_HEADER = 0x02014b50;
+const ZIP_LOCAL_FILE_HEADER = 0x04034b50;
+const ZIP_STORED = 0;
+const ZIP_DEFLATED = 8;
+
+interface ZipEntry {
+  path: string;
+  size: number;
+  compressedSize: number;
+  method: number;
+  localHeaderOffset: number;
+}
+
+/**
+ * A zip archive held in memory. Stored and deflated entries are supported;
+ * ZIP64 archives and encrypted entries are not. Entries with unsafe names
+ * (absolute, `..`) are left out.
+ */
+export const createZipFS = (data: Buffer): VirtualFS => {
+  // The end record is the last thing in the file, followed by a comment of up to 64KB
+  let end = -1;
+  for (let i = data.length - 22; i >= Math.max(0, data.length - 22 - 0xffff); i--) {
+    if (data.readUInt32LE(i) === ZIP_END_OF_CENTRAL_DIRECTORY) {
+      end = i;
+      break;
+    }
+  }
+  if (end < 0) throw new Error('Not a zip archive');
+  const entryCount = data.readUInt16LE(end + 10);
+  let offset = data.readUInt32LE(end + 16);
+  if (offset === 0xffffffff || entryCount === 0xffff) throw new Error('ZIP64 archives are not supported');
+
+  const entries = new Map<string, ZipEntry>();
+  for (let i = 0; i < entryCount; i++) {
+    if (data.readUInt32LE(offset) !== ZIP_CENTRAL_FILE_HEADER) throw new Error('Corrupt zip central directory');
+    const flags = data.readUInt16LE(offset + 8);
+    const method = data.readUInt16LE(offset + 10);
+    const compressedSize = data.readUInt32LE(offset + 20);
+    const size = data.readUInt32LE(offset + 24);
+    const nameLength = data.readUInt16LE(offset + 28);
+    const extraLength = data.readUInt16LE(offset + 30);
+    const commentLength = data.readUInt16LE(offset + 32);
+    const localHeaderOffset = data.readUInt32LE(offset + 42);
+    // Some Windows tools write backslashes despite the spec
+    const name = data.subarray(offset + 46, offset + 46 + nameLength).toString('utf-8').replace(/\\/g, '/');
+    offset += 46 + nameLength + extraLength + commentLength;
+
+    const encrypted = (flags & 0x1) !== 0;
+    if (name.endsWith('/') || encrypted || !isValidFSPath(name)) continue;
+    entries.set(name, { path: name, size, compressedSize, method, localHeaderOffset });
+  }
+
+  const extract = (entry: ZipEntry): Buffer => {
+    const header = entry.localHeaderOffset;
+    if (data.readUInt32LE(header) !== ZIP_LOCAL_FILE_HEADER) throw new Error(`Corrupt zip entry: ${entry.path}`);
+    const start = header + 30 + data.readUInt16LE(header + 26) + data.readUInt16LE(header + 28);
+    const raw = data.subarray(start, start + entry.compressedSize);
+    if (entry.method === ZIP_STORED) return Buffer.from(raw);
+    if (entry.method === ZIP_DEFLATED) return zlib.inflateRawSync(raw);
+    throw new Error(`Unsupported zip compression method ${entry.method}: ${entry.path}`);
+  };
+
+  return createIndexedFS(entries.values(), async (p) => {
+    const entry = entries.get(p);
+    return entry ? extract(entry) : null;
+  });
+};
@@ -0,0 +1,208 @@
+/**
+ * Watch Mode
+ *
+ * Keeps a graph built from the working tree current while files change, for
+ * long-running dev servers. File events (fs.watch, recursive) are debounced
+ * and applied in batches with applyFileChanges, so only changed files are
+ * re-parsed; each batch is reported with the symbols it added, removed and
+ * modified (see diffSymbols), which is enough for a connected UI to update
+ * without refetching the graph.
+ *
+ * Debouncing matters because editors rarely write a file once: they write a
+ * temp file and rename it over the original, or truncate and write. Events
+ * are collected until the tree has been quiet for `debounceMs`, and a path
+ * is judged by what is on disk at that point: temp files that came and went
+ * never show up, a file replaced by rename is a plain modification.
+ *
+ * The scan filters of the one-shot analyze apply (built-in ignores,
+ * .gitignore, include/exclude, size limit, generated files). A file that
+ * stops passing them, e.g. by a new .gitignore line or a `Code generated`
+ * header, is removed from the graph on its next change. Communities and
+ * processes are not re-detected, as with every incremental update.
+ *
+ * Recursive fs.watch needs Node 20 or later on Linux.
+ */
+
+import fs from 'fs';
+import path from 'path';
+import { KnowledgeGraph, GraphNode } from '../graph/types.js';
+import { indexSymbolsByKey, diffSymbols, GraphDiff } from '../graph/graph-diff.js';
+import { applyFileChanges, FileChangeResult } from './incremental.js';
+import { createWorkingTreeSource, filterRepositoryPaths, ScanOptions } from './filesystem-walker.js';
+import { AnalysisScope } from './analysis-scope.js';
+import { ExternalCallMap } from './call-processor.js';
+import { toFSPath, joinFSPath } from './virtual-fs.js';
+import { generateId } from '../../lib/utils.js';
+import { GitFileChange } from '../../storage/git.js';
+
+export interface WatchEvent extends GraphDiff {
+  /** Files applied in this batch (added, modified or deleted) */
+  files: GitFileChange[];
+  /** Details of the graph update */
+  update: FileChangeResult;
+}
+
+export interface WatchOptions {
+  /** Called after each batch of changes has been applied to the graph */
+  onChange: (event: WatchEvent) => void;
+  /** Called when a batch fails to apply or the watcher fails; watching goes on */
+  onError?: (error: unknown) => void;
+  /** Stops watching when aborted, like close() */
+  signal?: AbortSignal;
+  /** Quiet period before a batch is applied (default 200ms) */
+  debounceMs?: number;
+  /** Same filters as the analyze that built the graph */
+  scan?: ScanOptions;
+  /** Scope of the analyze that built the graph (see analysis-scope) */
+  scope?: AnalysisScope;
+  /** External-call collector from the original run; entries for touched files are replaced */
+  externalCalls?: ExternalCallMap;
+}
+
+export interface RepositoryWatcher {
+  /** Apply pending changes now instead of waiting for the quiet period */
+  flush: () => Promise<void>;
+  /** Stop watching; resolves once an update in progress has finished */
+  close: () => Promise<void>;
+}
+
+const DEFAULT_DEBOUNCE_MS = 200;
+
+/** Symbols of the given files, keyed as in graph diffs */
+const symbolsIn = (graph: KnowledgeGraph, files: Set<string>): Map<string, GraphNode> => {
+  const symbols = new Map<string, GraphNode>();
+  for (const [key, node] of indexSymbolsByKey(graph)) {
+    if (files.has(node.properties.filePath)) symbols.set(key, node);
+  }
+  return symbols;
+};
+
+/** Files the graph holds at or under `fsPath` (a path that may have been a directory) */
+const graphFilesUnder = (graph: KnowledgeGraph, fsPath: string): string[] => {
+  const prefix = `${fsPath}/`;
+  const files: string[] = [];
+  graph.forEachNode(node => {
+    if (node.label !== 'File') return;
+    const filePath = node.properties.filePath;
+    if (fsPath === '.' || filePath === fsPath || filePath.startsWith(prefix)) files.push(filePath);
+  });
+  return files;
+};
+
+/** Files on disk at or under `fsPath`, skipping dot-directories */
+const diskFilesUnder = async (repoPath: string, fsPath: string): Promise<string[]> => {
+  const full = fsPath === '.' ? repoPath : path.join(repoPath, ...fsPath.split('/'));
+  let stat: fs.Stats;
+  try {
+    stat = await fs.promises.stat(full);
+  } catch {
+    return [];
+  }
+  if (stat.isFile()) return [fsPath];
+  if (!stat.isDirectory()) return [];
+  const files: string[] = [];
+  for (const entry of await fs.promises.readdir(full, { withFileTypes: true })) {
+    if (entry.name.startsWith('.')) continue;
+    files.push(...await diskFilesUnder(repoPath, joinFSPath(fsPath, entry.name)));
+  }
+  return files;
+};
+
+/**
+ * Watch the working tree at `repoPath` and keep `graph` (built from it, e.g.
+ * by runPipelineFromRepo) up to date. Batches are applied one at a time;
+ * changes arriving meanwhile go into the next batch.
+ */
+export const watchRepository = (
+  graph: KnowledgeGraph,
+  repoPath: string,
+  options: WatchOptions,
+): RepositoryWatcher => {
+  const debounceMs = options.debounceMs ?? DEFAULT_DEBOUNCE_MS;
+  const source = createWorkingTreeSource(repoPath, options.scan);
+  const pending = new Set<string>();
+  let timer: NodeJS.Timeout | null = null;
+  let running: Promise<void> = Promise.resolve();
+  let closed = false;
+
+  const reportError = (error: unknown) => {
+    if (options.onError) options.onError(error);
+    else console.warn(`  Watch update failed: ${error instanceof Error ? error.message : String(error)}`);
+  };
+
+  /** Turn pending paths into file changes against what the graph holds */
+  const collectChanges = async (paths: string[]): Promise<GitFileChange[]> => {
+    const candidates = new Set<string>();
+    for (const p of paths) {
+      for (const file of graphFilesUnder(graph, p)) candidates.add(file);
+      for (const file of await diskFilesUnder(repoPath, p)) candidates.add(file);
+    }
+    const kept = new Set((await filterRepositoryPaths(repoPath, [...candidates], undefined, options.scan)).map(f => f.path));
+    const changes: GitFileChange[] = [];
+    for (const file of [...candidates].sort()) {
+      const inGraph = graph.getNode(generateId('File', file)) !== undefined;
+      if (kept.has(file)) changes.push({ status: inGraph ? 'modified' : 'added', path: file });
+      else if (inGraph) changes.push({ status: 'deleted', path: file });
+    }
+    return changes;
+  };
+
+  const applyPending = async () => {
+    if (pending.size === 0) return;
+    const paths = [...pending];
+    pending.clear();
+    const files = await collectChanges(paths);
+    if (files.length === 0) return;
+
+    const touched = new Set(files.map(f => f.path));
+    const before = symbolsIn(graph, touched);
+    const update = await applyFileChanges(graph, repoPath, files, source, {
+      ...(options.externalCalls ? { externalCalls: options.externalCalls } : {}),
+      ...(options.scan?.build ? { build: options.scan.build } : {}),
+      ...(options.scope ? { scope: options.scope } : {}),
+    });
+    const diff = diffSymbols(before, symbolsIn(graph, touched));
+    options.onChange({ files, update, ...diff });
+  };
+
+  const flush = (): Promise<void> => {
+    if (timer) {
+      clearTimeout(timer);
+      timer = null;
+    }
+    running = running.then(applyPending).catch(reportError);
+    return running;
+  };
+
+  const onEvent = (_event: string, filename: string | Buffer | null) => {
+    if (closed) return;
+    if (filename === null) {
+      // The platform didn't say what changed; every file is a candidate
+      pending.add('.');
+    } else {
+      const fsPath = toFSPath(filename.toString());
+      if (fsPath === '.' || fsPath.startsWith('../') || fsPath.split('/').some(part => part.startsWith('.'))) return;
+      pending.add(fsPath);
+    }
+    if (timer) clearTimeout(timer);
+    timer = setTimeout(() => { void flush(); }, debounceMs);
+  };
+
+  const watcher = fs.watch(repoPath, { recursive: true }, onEvent);
+  watcher.on('error', reportError);
+
+  const close = async () => {
+    if (!closed) {
+      closed = true;
+      if (timer) clearTimeout(timer);
+      watcher.close();
+      options.signal?.removeEventListener('abort', onAbort);
+    }
+    await running;
+  };
+  const onAbort = () => { void close(); };
+  if (options.signal?.aborted) void close();
+  else options.signal?.addEventListener('abort', onAbort);
+
+  return { flush, close };
+};
@@ -18,9 +18,15 @@ const _require = createRequire(import.meta.url);
 let Swift: any = null;
 try { Swift = _require('tree-sitter-swift'); } catch {}
 import { LANGUAGE_QUERIES } from '../tree-sitter-queries.js';
-import { getLanguageFromFilename } from '../utils.js';
+import { getLanguageFromFilename, createByteOffsetMapper, getSymbolPosition, getBodyHash } from '../utils.js';
 import { detectFrameworkFromAST } from '../framework-detection.js';
+import { extractGoSymbolMetadata, isRedundantGoTypeMatch, goSymbolIdName, GoSymbolMetadata, createGoCallContextExtractor, GoCallContext, extractGoImports, extractGoTypeReferences } from '../go-metadata.js';
+import type { ExtractedTypeRef } from '../type-usage-processor.js';
+import type { FileImport } from '../../graph/types.js';
+import { extractDocComment } from '../doc-comments.js';
 import { generateId } from '../../../lib/utils.js';
+import { claimSymbolId } from '../../graph/symbol-ids.js';
+import { fileConstraintExpression } from '../build-constraints.js';
 
 // ============================================================================
 // Types for serializable results
@@ -34,12 +40,17 @@ interface ParsedNode {
     filePath: string;
     startLine: number;
     endLine: number;
+    definitionEndLine?: number;
+    startColumn: number;
+    startByte: number;
+    endByte: number;
+    bodyHash?: string;
     language: string;
     isExported: boolean;
     astFrameworkMultiplier?: number;
     astFrameworkReason?: string;
     description?: string;
-  };
+  } & GoSymbolMetadata;
 }
 
 interface ParsedRelationship {
@@ -64,11 +75,19 @@ export interface ExtractedImport {
   language: string;
 }
 
-export interface ExtractedCall {
+export interface ExtractedCall extends GoCallContext {
   filePath: string;
   calledName: string;
   /** generateId of enclosing function, or generateId('File', filePath) for top-level */
   sourceId: string;
+  /** Call-site row (0-based) */
+  line: number;
+}
+
+/** Per-file import list, attached to the File node */
+export interface ExtractedFileImports {
+  filePath: string;
+  imports: FileImport[];
 }
 
 export interface ExtractedHeritage {
@@ -79,6 +98,18 @@ export interface ExtractedHeritage {
   kind: string;
 }
 
+/**
+ * A file that didn't parse cleanly. 'syntax' files were still indexed
+ * (tree-sitter recovers around errors), as were 'annotate' ones (a symbol
+ * or file annotator threw, see annotators.ts); for the others, symbols
+ * extracted before the failure are kept and the rest of the file is skipped.
+ */
+export interface FileParseError {
+  filePath: string;
+  stage: 'syntax' | 'parse' | 'query' | 'extract' | 'annotate';
+  message: string;
+}
+
 export interface ParseWorkerResult {
   nodes: ParsedNode[];
   relationships: ParsedRelationship[];
@@ -86,6 +117,9 @@ export interface ParseWorkerResult {
   imports: ExtractedImport[];
   calls: ExtractedCall[];
   heritage: ExtractedHeritage[];
+  fileImports: ExtractedFileImports[];
+  typeRefs: ExtractedTypeRef[];
+  errors: FileParseError[];
   fileCount: number;
 }
 
@@ -253,6 +287,15 @@ const findEnclosingFunctionId = (node: any, filePath: string): string | null =>
       let funcName: string | null = null;
       let label = 'Function';
 
+      // Go ids carry the receiver and an ordinal (see goSymbolIdName), so build them from the tree
+      if (filePath.endsWith('.go') && (current.type === 'function_declaration' || current.type === 'method_declaration')) {
+        const nameNode = current.childForFieldName?.('name');
+        if (nameNode) {
+          const goLabel = current.type === 'method_declaration' ? 'Method' : 'Function';
+          return generateId(goLabel, `${filePath}:${goSymbolIdName(nameNode, goLabel)}`);
+        }
+      }
+
       if (current.type === 'init_declaration' || current.type === 'deinit_declaration') {
         const funcName = current.type === 'init_declaration' ? 'init' : 'deinit';
         const label = 'Constructor';
@@ -474,6 +517,9 @@ const processBatch = (files: ParseWorkerInput[], onProgress?: (filesProcessed: n
     imports: [],
     calls: [],
     heritage: [],
+    fileImports: [],
+    typeRefs: [],
+    errors: [],
     fileCount: 0,
   };
 
@@ -641,6 +687,8 @@ function extractEloquentRelationDescription(methodNode: any): string | null {
   return null;
 }
 
+const errorMessage = (err: unknown): string => (err instanceof Error ? err.message : String(err));
+
 const processFileGroup = (
   files: ParseWorkerInput[],
   language: SupportedLanguages,
@@ -652,150 +700,201 @@ const processFileGroup = (
   try {
     const lang = parser.getLanguage();
     query = new Parser.Query(lang, queryString);
-  } catch {
+  } catch (err) {
+    const message = errorMessage(err);
+    for (const file of files) result.errors.push({ filePath: file.path, stage: 'query', message });
     return;
   }
 
   for (const file of files) {
     // Skip very large files — they can crash tree-sitter or cause OOM
-    if (file.content.length > 512 * 1024) continue;
+    if (file.content.length > 512 * 1024) {
+      result.errors.push({ filePath: file.path, stage: 'parse', message: 'larger than 512KB, not parsed' });
+      continue;
+    }
 
     let tree;
     try {
       tree = parser.parse(file.content, undefined, { bufferSize: 1024 * 256 });
-    } catch {
+    } catch (err) {
+      result.errors.push({ filePath: file.path, stage: 'parse', message: errorMessage(err) });
       continue;
     }
 
     result.fileCount++;
     onFileProcessed?.();
+    if (tree.rootNode.hasError) {
+      result.errors.push({ filePath: file.path, stage: 'syntax', message: 'syntax errors (partially indexed)' });
+    }
 
     let matches;
     try {
       matches = query.matches(tree.rootNode);
-    } catch {
+    } catch (err) {
+      result.errors.push({ filePath: file.path, stage: 'query', message: errorMessage(err) });
       continue;
     }
 
-    for (const match of matches) {
-      const captureMap: Record<string, any> = {};
-      for (const c of match.captures) {
-        captureMap[c.name] = c.node;
-      }
-
-      // Extract import paths before skipping
-      if (captureMap['import'] && captureMap['import.source']) {
-        const rawImportPath = captureMap['import.source'].text.replace(/['"<>]/g, '');
-        result.imports.push({
-          filePath: file.path,
-          rawImportPath,
-          language: language,
-        });
-        continue;
-      }
-
-      // Extract call sites
-      if (captureMap['call']) {
-        const callNameNode = captureMap['call.name'];
-        if (callNameNode) {
-          const calledName = callNameNode.text;
-          if (!BUILT_INS.has(calledName)) {
-            const callNode = captureMap['call'];
-            const sourceId = findEnclosingFunctionId(callNode, file.path)
-              || generateId('File', file.path);
-            result.calls.push({ filePath: file.path, calledName, sourceId });
-          }
+    try {
+      const toByte = createByteOffsetMapper(file.content);
+      const goImports = language === SupportedLanguages.Go ? extractGoImports(tree.rootNode) : null;
+      if (goImports) {
+        result.fileImports.push({ filePath: file.path, imports: goImports });
+        for (const ref of extractGoTypeReferences(tree.rootNode, file.path, goImports)) {
+          result.typeRefs.push({ ...ref, filePath: file.path });
         }
-        continue;
       }
+      const goCallContext = goImports
+        ? createGoCallContextExtractor(tree.rootNode, goImports)
+        : null;
 
-      // Extract heritage (extends/implements)
-      if (captureMap['heritage.class']) {
-        if (captureMap['heritage.extends']) {
-          result.heritage.push({
-            filePath: file.path,
-            className: captureMap['heritage.class'].text,
-            parentName: captureMap['heritage.extends'].text,
-            kind: 'extends',
-          });
+      const claimedIds = new Map<string, number>();
+      const buildConstraint = language === SupportedLanguages.Go ? fileConstraintExpression(file.path, file.content) : undefined;
+      for (const match of matches) {
+        const captureMap: Record<string, any> = {};
+        for (const c of match.captures) {
+          captureMap[c.name] = c.node;
         }
-        if (captureMap['heritage.implements']) {
-          result.heritage.push({
-            filePath: file.path,
-            className: captureMap['heritage.class'].text,
-            parentName: captureMap['heritage.implements'].text,
-            kind: 'implements',
-          });
-        }
-        if (captureMap['heritage.trait']) {
-          result.heritage.push({
+
+        // Extract import paths before skipping
+        if (captureMap['import'] && captureMap['import.source']) {
+          const rawImportPath = captureMap['import.source'].text.replace(/['"<>]/g, '');
+          result.imports.push({
             filePath: file.path,
-            className: captureMap['heritage.class'].text,
-            parentName: captureMap['heritage.trait'].text,
-            kind: 'trait-impl',
+            rawImportPath,
+            language: language,
           });
-        }
-        if (captureMap['heritage.extends'] || captureMap['heritage.implements'] || captureMap['heritage.trait']) {
           continue;
         }
-      }
 
-      const nodeLabel = getLabelFromCaptures(captureMap);
-      if (!nodeLabel) continue;
+        // Extract call sites
+        if (captureMap['call']) {
+          const callNameNode = captureMap['call.name'];
+          if (callNameNode) {
+            const calledName = callNameNode.text;
+            if (!BUILT_INS.has(calledName)) {
+              const callNode = captureMap['call'];
+              const sourceId = findEnclosingFunctionId(callNode, file.path)
+                || generateId('File', file.path);
+              result.calls.push({
+                filePath: file.path,
+                calledName,
+                sourceId,
+                line: callNode.startPosition.row,
+                ...(goCallContext ? goCallContext(callNode) : {}),
+              });
+            }
+          }
+          continue;
+        }
 
-      const nameNode = captureMap['name'];
-      const nodeName = nameNode.text;
-      const nodeId = generateId(nodeLabel, `${file.path}:${nodeName}`);
+        // Extract heritage (extends/implements)
+        if (captureMap['heritage.class']) {
+          if (captureMap['heritage.extends']) {
+            result.heritage.push({
+              filePath: file.path,
+              className: captureMap['heritage.class'].text,
+              parentName: captureMap['heritage.extends'].text,
+              kind: 'extends',
+            });
+          }
+          if (captureMap['heritage.implements']) {
+            result.heritage.push({
+              filePath: file.path,
+              className: captureMap['heritage.class'].text,
+              parentName: captureMap['heritage.implements'].text,
+              kind: 'implements',
+            });
+          }
+          if (captureMap['heritage.trait']) {
+            result.heritage.push({
+              filePath: file.path,
+              className: captureMap['heritage.class'].text,
+              parentName: captureMap['heritage.trait'].text,
+              kind: 'trait-impl',
+            });
+          }
+          if (captureMap['heritage.extends'] || captureMap['heritage.implements'] || captureMap['heritage.trait']) {
+            continue;
+          }
+        }
 
-      let description: string | undefined;
-      if (language === SupportedLanguages.PHP) {
-        if (nodeLabel === 'Property' && captureMap['definition.property']) {
-          description = extractPhpPropertyDescription(nodeName, captureMap['definition.property']) ?? undefined;
-        } else if (nodeLabel === 'Method' && captureMap['definition.method']) {
-          description = extractEloquentRelationDescription(captureMap['definition.method']) ?? undefined;
+        const nodeLabel = getLabelFromCaptures(captureMap);
+        if (!nodeLabel) continue;
+
+        const nameNode = captureMap['name'];
+        if (language === SupportedLanguages.Go && isRedundantGoTypeMatch(nameNode, nodeLabel)) continue;
+        const nodeName = nameNode.text;
+        // Ordinals only for Go, where findEnclosingFunctionId rebuilds them
+        const nodeId = language === SupportedLanguages.Go
+          ? claimSymbolId(claimedIds, generateId(nodeLabel, `${file.path}:${goSymbolIdName(nameNode, nodeLabel)}`), nameNode.startIndex)
+          : generateId(nodeLabel, `${file.path}:${nodeName}`);
+        if (!nodeId) continue;
+
+        let description: string | undefined;
+        if (language === SupportedLanguages.PHP) {
+          if (nodeLabel === 'Property' && captureMap['definition.property']) {
+            description = extractPhpPropertyDescription(nodeName, captureMap['definition.property']) ?? undefined;
+          } else if (nodeLabel === 'Method' && captureMap['definition.method']) {
+            description = extractEloquentRelationDescription(captureMap['definition.method']) ?? undefined;
+          }
         }
-      }
 
-      const definitionNode = getDefinitionNodeFromCaptures(captureMap);
-      const frameworkHint = definitionNode
-        ? detectFrameworkFromAST(language, definitionNode.text || '')
-        : null;
+        const goMetadata = language === SupportedLanguages.Go
+          ? extractGoSymbolMetadata(nameNode, nodeLabel, file.path, goCallContext ?? undefined)
+          : undefined;
+        const docComment = extractDocComment(nameNode, language);
+
+        const definitionNode = getDefinitionNodeFromCaptures(captureMap);
+        const frameworkHint = definitionNode
+          ? detectFrameworkFromAST(language, definitionNode.text || '')
+          : null;
+
+        result.nodes.push({
+          id: nodeId,
+          label: nodeLabel,
+          properties: {
+            name: nodeName,
+            filePath: file.path,
+            startLine: nameNode.startPosition.row,
+            endLine: nameNode.endPosition.row,
+            ...(definitionNode ? { definitionEndLine: definitionNode.endPosition.row } : {}),
+            ...getSymbolPosition(file.content, toByte, nameNode, definitionNode),
+            ...(definitionNode ? { bodyHash: getBodyHash(definitionNode, nameNode) } : {}),
+            language: language,
+            isExported: isNodeExported(nameNode, nodeName, language),
+            ...(frameworkHint ? {
+              astFrameworkMultiplier: frameworkHint.entryPointMultiplier,
+              astFrameworkReason: frameworkHint.reason,
+            } : {}),
+            ...(description !== undefined ? { description } : {}),
+            ...(docComment ? { docComment } : {}),
+            ...goMetadata,
+            ...(buildConstraint ? { buildConstraint } : {}),
+          },
+        });
 
-      result.nodes.push({
-        id: nodeId,
-        label: nodeLabel,
-        properties: {
-          name: nodeName,
+        result.symbols.push({
           filePath: file.path,
-          startLine: nameNode.startPosition.row,
-          endLine: nameNode.endPosition.row,
-          language: language,
-          isExported: isNodeExported(nameNode, nodeName, language),
-          ...(frameworkHint ? {
-            astFrameworkMultiplier: frameworkHint.entryPointMultiplier,
-            astFrameworkReason: frameworkHint.reason,
-          } : {}),
-          ...(description !== undefined ? { description } : {}),
-        },
-      });
-
-      result.symbols.push({
-        filePath: file.path,
-        name: nodeName,
-        nodeId,
-        type: nodeLabel,
-      });
+          name: nodeName,
+          nodeId,
+          type: nodeLabel,
+        });
 
-      const fileId = generateId('File', file.path);
-      const relId = generateId('DEFINES', `${fileId}->${nodeId}`);
-      result.relationships.push({
-        id: relId,
-        sourceId: fileId,
-        targetId: nodeId,
-        type: 'DEFINES',
-        confidence: 1.0,
-        reason: '',
-      });
+        const fileId = generateId('File', file.path);
+        const relId = generateId('DEFINES', `${fileId}->${nodeId}`);
+        result.relationships.push({
+          id: relId,
+          sourceId: fileId,
+          targetId: nodeId,
+          type: 'DEFINES',
+          confidence: 1.0,
+          reason: '',
+        });
+      }
+    } catch (err) {
+      // One bad file shouldn't fail the whole sub-batch
+      result.errors.push({ filePath: file.path, stage: 'extract', message: errorMessage(err) });
     }
   }
 };
@@ -807,7 +906,7 @@ const processFileGroup = (
 /** Accumulated result across sub-batches */
 let accumulated: ParseWorkerResult = {
   nodes: [], relationships: [], symbols: [],
-  imports: [], calls: [], heritage: [], fileCount: 0,
+  imports: [], calls: [], heritage: [], fileImports: [], typeRefs: [], errors: [], fileCount: 0,
 };
 let cumulativeProcessed = 0;
 
@@ -818,6 +917,9 @@ const mergeResult = (target: ParseWorkerResult, src: ParseWorkerResult) => {
   target.imports.push(...src.imports);
   target.calls.push(...src.calls);
   target.heritage.push(...src.heritage);
+  target.fileImports.push(...src.fileImports);
+  target.typeRefs.push(...src.typeRefs);
+  target.errors.push(...src.errors);
   target.fileCount += src.fileCount;
 };
 
@@ -839,7 +941,7 @@ parentPort!.on('message', (msg: any) => {
     if (msg && msg.type === 'flush') {
       parentPort!.postMessage({ type: 'result', data: accumulated });
       // Reset for potential reuse
-      accumulated = { nodes: [], relationships: [], symbols: [], imports: [], calls: [], heritage: [], fileCount: 0 };
+      accumulated = { nodes: [], relationships: [], symbols: [], imports: [], calls: [], heritage: [], fileImports: [], typeRefs: [], errors: [], fileCount: 0 };
       cumulativeProcessed = 0;
       return;
     }
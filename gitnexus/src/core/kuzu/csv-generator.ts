@@ -18,6 +18,9 @@ import path from 'path';
 import { KnowledgeGraph, GraphNode, NodeLabel } from '../graph/types.js';
 import { NodeTableName } from './schema.js';
 
+/** Reads a repo-relative file's content (e.g. from a commit); null if missing */
+export type SourceFileReader = (relativePath: string) => Promise<string | null>;
+
 /** Flush buffered rows to disk every N rows */
 const FLUSH_EVERY = 500;
 
@@ -41,6 +44,12 @@ const escapeCSVField = (value: string | number | undefined | null): string => {
   return `"${str.replace(/"/g, '""')}"`;
 };
 
+/** Annotator meta as JSON with sorted keys, so a key:value pair reads the same in every row */
+export const metaJSON = (meta: Record<string, string> | undefined): string => {
+  if (!meta || Object.keys(meta).length === 0) return '';
+  return JSON.stringify(Object.fromEntries(Object.entries(meta).sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0))));
+};
+
 const escapeCSVNumber = (value: number | undefined | null, defaultValue: number = -1): string => {
   if (value === undefined || value === null) return String(defaultValue);
   return String(value);
@@ -71,10 +80,12 @@ class FileContentCache {
   private accessOrder: string[] = [];
   private maxSize: number;
   private repoPath: string;
+  private readFile?: SourceFileReader;
 
-  constructor(repoPath: string, maxSize: number = 3000) {
+  constructor(repoPath: string, maxSize: number = 3000, readFile?: SourceFileReader) {
     this.repoPath = repoPath;
     this.maxSize = maxSize;
+    this.readFile = readFile;
   }
 
   async get(relativePath: string): Promise<string> {
@@ -82,8 +93,9 @@ class FileContentCache {
     const cached = this.cache.get(relativePath);
     if (cached !== undefined) return cached;
     try {
-      const fullPath = path.join(this.repoPath, relativePath);
-      const content = await fs.readFile(fullPath, 'utf-8');
+      const content = this.readFile
+        ? (await this.readFile(relativePath)) ?? ''
+        : await fs.readFile(path.join(this.repoPath, relativePath), 'utf-8');
       this.set(relativePath, content);
       return content;
     } catch {
@@ -124,7 +136,9 @@ const extractContent = async (
   if (startLine === undefined || endLine === undefined) return '';
 
   const lines = content.split('\n');
-  const start = Math.max(0, startLine - 2);
+  // Reach back far enough to include the whole doc comment (searchable via FTS)
+  const docLines = node.properties.docComment ? node.properties.docComment.split('\n').length : 0;
+  const start = Math.max(0, startLine - Math.max(2, docLines));
   const end = Math.min(lines.length - 1, endLine + 2);
   const snippet = lines.slice(start, end + 1).join('\n');
   const MAX_SNIPPET = 5000;
@@ -197,6 +211,7 @@ export const streamAllCSVsToDisk = async (
   graph: KnowledgeGraph,
   repoPath: string,
   csvDir: string,
+  readFile?: SourceFileReader,
 ): Promise<StreamedCSVResult> => {
   // Remove stale CSVs from previous crashed runs, then recreate
   try { await fs.rm(csvDir, { recursive: true, force: true }); } catch {}
@@ -207,12 +222,12 @@ export const streamAllCSVsToDisk = async (
   const prevMax = process.getMaxListeners();
   process.setMaxListeners(prevMax + 40);
 
-  const contentCache = new FileContentCache(repoPath);
+  const contentCache = new FileContentCache(repoPath, 3000, readFile);
 
   // Create writers for every node type up-front
   const fileWriter = new BufferedCSVWriter(path.join(csvDir, 'file.csv'), 'id,name,filePath,content');
   const folderWriter = new BufferedCSVWriter(path.join(csvDir, 'folder.csv'), 'id,name,filePath');
-  const codeElementHeader = 'id,name,filePath,startLine,endLine,isExported,content,description';
+  const codeElementHeader = 'id,name,filePath,startLine,endLine,isExported,content,description,meta';
   const functionWriter = new BufferedCSVWriter(path.join(csvDir, 'function.csv'), codeElementHeader);
   const classWriter = new BufferedCSVWriter(path.join(csvDir, 'class.csv'), codeElementHeader);
   const interfaceWriter = new BufferedCSVWriter(path.join(csvDir, 'interface.csv'), codeElementHeader);
@@ -222,7 +237,7 @@ export const streamAllCSVsToDisk = async (
   const processWriter = new BufferedCSVWriter(path.join(csvDir, 'process.csv'), 'id,label,heuristicLabel,processType,stepCount,communities,entryPointId,terminalId');
 
   // Multi-language node types share the same CSV shape (no isExported column)
-  const multiLangHeader = 'id,name,filePath,startLine,endLine,content,description';
+  const multiLangHeader = 'id,name,filePath,startLine,endLine,content,description,meta';
   const MULTI_LANG_TYPES = ['Struct', 'Enum', 'Macro', 'Typedef', 'Union', 'Namespace', 'Trait', 'Impl',
     'TypeAlias', 'Const', 'Static', 'Property', 'Record', 'Delegate', 'Annotation', 'Constructor', 'Template', 'Module'] as const;
   const multiLangWriters = new Map<string, BufferedCSVWriter>();
@@ -306,6 +321,7 @@ export const streamAllCSVsToDisk = async (
             node.properties.isExported ? 'true' : 'false',
             escapeCSVField(content),
             escapeCSVField((node.properties as any).description || ''),
+            escapeCSVField(metaJSON(node.properties.meta)),
           ].join(','));
         } else {
           // Multi-language node types (Struct, Impl, Trait, Macro, etc.)
@@ -320,6 +336,7 @@ export const streamAllCSVsToDisk = async (
               escapeCSVNumber(node.properties.endLine, -1),
               escapeCSVField(content),
               escapeCSVField((node.properties as any).description || ''),
+              escapeCSVField(metaJSON(node.properties.meta)),
             ].join(','));
           }
         }
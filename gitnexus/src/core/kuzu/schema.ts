@@ -65,6 +65,7 @@ CREATE NODE TABLE Function (
   isExported BOOLEAN,
   content STRING,
   description STRING,
+  meta STRING,
   PRIMARY KEY (id)
 )`;
 
@@ -78,6 +79,7 @@ CREATE NODE TABLE Class (
   isExported BOOLEAN,
   content STRING,
   description STRING,
+  meta STRING,
   PRIMARY KEY (id)
 )`;
 
@@ -91,6 +93,7 @@ CREATE NODE TABLE Interface (
   isExported BOOLEAN,
   content STRING,
   description STRING,
+  meta STRING,
   PRIMARY KEY (id)
 )`;
 
@@ -104,6 +107,7 @@ CREATE NODE TABLE Method (
   isExported BOOLEAN,
   content STRING,
   description STRING,
+  meta STRING,
   PRIMARY KEY (id)
 )`;
 
@@ -117,6 +121,7 @@ CREATE NODE TABLE CodeElement (
   isExported BOOLEAN,
   content STRING,
   description STRING,
+  meta STRING,
   PRIMARY KEY (id)
 )`;
 
@@ -160,6 +165,7 @@ CREATE NODE TABLE Process (
 
 // Generic code element with startLine/endLine for C, C++, Rust, Go, Java, C#
 // description: optional metadata (e.g. Eloquent $fillable fields, relationship targets)
+// meta: annotator key/values as a JSON object (see annotators.ts); '' when none
 const CODE_ELEMENT_BASE = (name: string) => `
 CREATE NODE TABLE \`${name}\` (
   id STRING,
@@ -169,6 +175,7 @@ CREATE NODE TABLE \`${name}\` (
   endLine INT64,
   content STRING,
   description STRING,
+  meta STRING,
   PRIMARY KEY (id)
 )`;
 
@@ -324,6 +331,7 @@ CREATE REL TABLE ${REL_TABLE_NAME} (
   FROM \`TypeAlias\` TO Community,
   FROM \`TypeAlias\` TO \`Trait\`,
   FROM \`TypeAlias\` TO Class,
+  FROM \`TypeAlias\` TO Interface,
   FROM \`Const\` TO Community,
   FROM \`Static\` TO Community,
   FROM \`Property\` TO Community,
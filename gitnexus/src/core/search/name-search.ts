@@ -0,0 +1,187 @@
+/**
+ * Fuzzy Symbol Name Search
+ *
+ * In-memory lookup over the knowledge graph for when the exact name isn't
+ * known. Unlike BM25 (which searches indexed content in KuzuDB), this only
+ * looks at symbol names and needs no database.
+ *
+ * Match tiers, best first — a name is scored by the best tier it reaches:
+ *   exact      `getname`  -> GetName
+ *   acronym    `US`       -> UserService  (initials typed with matching case)
+ *   prefix     `getn`     -> GetName
+ *   initials   `US`       -> UserService  (first letter of each camelCase/snake_case word)
+ *   substring  `name`     -> GetName
+ *   subsequence `usrsvc`  -> UserService  (letters in order; word starts score higher)
+ * Matching is case-insensitive; an exact-case match breaks ties within a tier.
+ */
+
+import { KnowledgeGraph, NodeLabel } from '../graph/types.js';
+import { getSymbolKind, GraphJSONKind } from '../graph/json-export.js';
+
+export type NameMatchType = 'exact' | 'acronym' | 'prefix' | 'initials' | 'substring' | 'subsequence';
+
+export interface NameSearchResult {
+  id: string;
+  name: string;
+  label: NodeLabel;
+  kind: GraphJSONKind;
+  filePath: string;
+  startLine?: number;
+  score: number;
+  matchType: NameMatchType;
+  /** Annotator metadata (see annotators.ts) */
+  meta?: Record<string, string>;
+}
+
+export interface NameSearchOptions {
+  /** Restrict to these kinds ('func', 'method', 'type', 'const', 'var', ...) */
+  kinds?: GraphJSONKind[];
+  /** Restrict to these graph labels (`Struct`, `Interface`, ...) */
+  labels?: NodeLabel[];
+  /** Restrict to symbols with this annotator metadata: a value, or `true` for any */
+  meta?: Record<string, string | true>;
+  /** Max results (default 20) */
+  limit?: number;
+}
+
+/** Structural nodes, skipped unless asked for by kind/label */
+const NON_SYMBOL_LABELS = new Set<string>(['Project', 'Package', 'Module', 'Folder', 'File', 'Community', 'Process']);
+
+const TIER_BASE: Record<NameMatchType, number> = {
+  exact: 1000,
+  acronym: 900,
+  prefix: 800,
+  initials: 600,
+  substring: 400,
+  subsequence: 200,
+};
+
+/**
+ * Word boundaries of an identifier: `getHTTPServer_v2` -> [0, 3, 7, 12, 13]
+ * (get, HTTP, Server, v, 2). Separators themselves are not word starts.
+ */
+const wordStarts = (name: string): number[] => {
+  const starts: number[] = [];
+  for (let i = 0; i < name.length; i++) {
+    const c = name[i];
+    if (c === '_' || c === '-' || c === '$' || c === '.') continue;
+    const prev = name[i - 1];
+    const next = name[i + 1];
+    const isUpper = c !== c.toLowerCase();
+    const isDigit = c >= '0' && c <= '9';
+    if (i === 0 || prev === '_' || prev === '-' || prev === '$' || prev === '.') starts.push(i);
+    else if (isDigit && !(prev >= '0' && prev <= '9')) starts.push(i);
+    else if (isUpper && prev === prev.toLowerCase() && prev !== prev.toUpperCase()) starts.push(i);
+    // End of an acronym: the `S` in `HTTPServer`
+    else if (isUpper && prev !== prev.toLowerCase() && next && next !== next.toUpperCase()) starts.push(i);
+    else if (!isUpper && !isDigit && prev >= '0' && prev <= '9') starts.push(i);
+  }
+  return starts;
+};
+
+/**
+ * Subsequence score in [0, 1): rewards matches on word starts and
+ * consecutive runs, penalizes gaps. -1 if `query` isn't a subsequence.
+ */
+const subsequenceScore = (query: string, lowerName: string, starts: Set<number>): number => {
+  let qi = 0;
+  let prevMatch = -1;
+  let points = 0;
+  for (let i = 0; i < lowerName.length && qi < query.length; i++) {
+    if (lowerName[i] !== query[qi]) continue;
+    points += starts.has(i) ? 3 : prevMatch === i - 1 ? 2 : 1;
+    prevMatch = i;
+    qi++;
+  }
+  if (qi < query.length) return -1;
+  return points / (3 * query.length + (lowerName.length - query.length) + 1);
+};
+
+/** Score one name against a query; null when it doesn't match at all */
+export const scoreNameMatch = (
+  query: string,
+  name: string,
+): { score: number; matchType: NameMatchType } | null => {
+  const q = query.toLowerCase();
+  const lower = name.toLowerCase();
+  if (q.length === 0) return null;
+  const caseBonus = name.startsWith(query) ? 50 : 0;
+  // Shorter names rank higher within a tier: `Get` over `GetAll` for "get"
+  const lengthPenalty = Math.min(99, lower.length - q.length);
+
+  if (lower === q) return { score: TIER_BASE.exact + caseBonus, matchType: 'exact' };
+
+  const starts = wordStarts(name);
+  const initials = starts.map(i => lower[i]).join('');
+  // Covering every word beats covering a prefix of them
+  const uncovered = Math.min(49, initials.length - q.length);
+  const coverage = uncovered === 0 ? 50 : 0;
+  if (q.length > 1 && starts.map(i => name[i]).join('').startsWith(query)) {
+    return { score: TIER_BASE.acronym + coverage - uncovered, matchType: 'acronym' };
+  }
+  if (lower.startsWith(q)) return { score: TIER_BASE.prefix + caseBonus - lengthPenalty, matchType: 'prefix' };
+  if (initials.startsWith(q)) {
+    return { score: TIER_BASE.initials + coverage - uncovered, matchType: 'initials' };
+  }
+
+  const idx = lower.indexOf(q);
+  if (idx >= 0) {
+    const onBoundary = starts.includes(idx) ? 100 : 0;
+    return { score: TIER_BASE.substring + onBoundary - Math.min(99, idx + lengthPenalty), matchType: 'substring' };
+  }
+
+  const sub = subsequenceScore(q, lower, new Set(starts));
+  if (sub >= 0) return { score: TIER_BASE.subsequence + Math.round(sub * 199), matchType: 'subsequence' };
+  return null;
+};
+
+/**
+ * Search symbol names. Results are sorted by score, then shorter name, then
+ * name, file path, and id, so identical scores always come back in the same
+ * order.
+ */
+export const searchSymbolsByName = (
+  graph: KnowledgeGraph,
+  query: string,
+  options: NameSearchOptions = {},
+): NameSearchResult[] => {
+  const trimmed = query.trim();
+  if (!trimmed) return [];
+  const kinds = options.kinds ? new Set(options.kinds) : null;
+  const labels = options.labels ? new Set<string>(options.labels) : null;
+  const metaFilter = Object.entries(options.meta ?? {});
+  const limit = options.limit ?? 20;
+
+  const results: NameSearchResult[] = [];
+  graph.forEachNode(node => {
+    const kind = getSymbolKind(node.label);
+    if (kinds && !kinds.has(kind)) return;
+    if (labels && !labels.has(node.label)) return;
+    if (!kinds && !labels && NON_SYMBOL_LABELS.has(node.label)) return;
+    const meta = node.properties.meta;
+    if (metaFilter.some(([key, value]) => meta?.[key] === undefined || (value !== true && meta[key] !== value))) return;
+
+    const match = scoreNameMatch(trimmed, node.properties.name);
+    if (!match) return;
+    results.push({
+      id: node.id,
+      name: node.properties.name,
+      label: node.label,
+      kind,
+      filePath: node.properties.filePath,
+      startLine: node.properties.startLine,
+      score: match.score,
+      matchType: match.matchType,
+      ...(meta ? { meta } : {}),
+    });
+  });
+
+  const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+  results.sort((a, b) =>
+    b.score - a.score ||
+    a.name.length - b.name.length ||
+    cmp(a.name, b.name) ||
+    cmp(a.filePath, b.filePath) ||
+    cmp(a.id, b.id));
+  return limit > 0 ? results.slice(0, limit) : results;
+};
@@ -0,0 +1,134 @@
+/**
+ * Signature Search
+ *
+ * Find Go funcs and methods by shape rather than name: "returns error last
+ * and takes a context.Context first". Works off the signature strings
+ * recorded at parse time, so no database is needed.
+ *
+ * A query constrains the parameter list, the result list, or both. Each is
+ * a list of patterns matched against the whole list, in order:
+ *   `?`      exactly one type, any type
+ *   `...`    any number of types, including none (a bare `...`; `...T` is a
+ *            variadic parameter of type T)
+ *   a type   that type, by its string form (`*User`, `[]byte`, `map[string]any`)
+ * A type pattern with a package qualifier (`context.Context`) must match
+ * exactly; one without (`Context`, `*User`) also matches qualified types
+ * (`context.Context`, `*models.User`). Whitespace is ignored and
+ * `interface{}` equals `any`.
+ *
+ *   { params: ['context.Context', '...'] }   first parameter is a context
+ *   { returns: ['...', 'error'] }             last result is an error
+ *   { params: ['...', 'context.Context', '...'] }  takes a context anywhere
+ *   { params: [] }                            takes no parameters
+ */
+
+import { KnowledgeGraph, GraphNode, NodeLabel } from '../graph/types.js';
+import { parseSignature } from '../graph/signatures.js';
+import { normalizeGoType } from '../ingestion/go-metadata.js';
+import { isGoTestFile } from '../../lib/utils.js';
+
+export interface SignatureQuery {
+  /** Parameter type patterns; any parameters when omitted */
+  params?: string[];
+  /** Result type patterns; any results when omitted */
+  returns?: string[];
+  /** Receiver base type for methods (`Server`); funcs never match when set */
+  receiver?: string;
+  /** Only 'func' or only 'method' (default both) */
+  kind?: 'func' | 'method';
+  /** Only exported names */
+  exportedOnly?: boolean;
+  /** Leave out _test.go files */
+  excludeTests?: boolean;
+}
+
+export interface SignatureSearchResult {
+  id: string;
+  name: string;
+  label: NodeLabel;
+  filePath: string;
+  startLine?: number;
+  receiverType?: string;
+  signature: string;
+  params: string[];
+  results: string[];
+}
+
+const QUALIFIED = /[A-Za-z_][A-Za-z0-9_]*\.[A-Za-z_]/;
+const QUALIFIERS = /\b[A-Za-z_][A-Za-z0-9_]*\.(?=[A-Za-z_])/g;
+
+const compact = (type: string): string => normalizeGoType(type).replace(/\s+/g, '');
+
+/** Does one type match one type pattern? */
+export const matchesTypePattern = (pattern: string, type: string): boolean => {
+  if (pattern === '?') return true;
+  const want = compact(pattern);
+  const have = compact(type);
+  if (want === have) return true;
+  return !QUALIFIED.test(want) && have.replace(QUALIFIERS, '') === want;
+};
+
+/** Does a type list match a pattern list (with `?` and `...` wildcards)? */
+export const matchesTypeList = (patterns: string[], types: string[]): boolean => {
+  // reachable[j]: the patterns so far can consume exactly types[0..j)
+  let reachable = new Array<boolean>(types.length + 1).fill(false);
+  reachable[0] = true;
+  for (const pattern of patterns) {
+    const next = new Array<boolean>(types.length + 1).fill(false);
+    if (pattern.trim() === '...') {
+      let any = false;
+      for (let j = 0; j <= types.length; j++) {
+        any = any || reachable[j];
+        next[j] = any;
+      }
+    } else {
+      for (let j = 0; j < types.length; j++) {
+        if (reachable[j] && matchesTypePattern(pattern, types[j])) next[j + 1] = true;
+      }
+    }
+    reachable = next;
+  }
+  return reachable[types.length];
+};
+
+/** Does a func/method node match the query? False for other nodes */
+export const matchesSignature = (node: GraphNode, query: SignatureQuery): boolean => {
+  if (node.label !== 'Function' && node.label !== 'Method') return false;
+  const p = node.properties;
+  if (!p.signature) return false;
+  if (query.kind === 'func' && node.label !== 'Function') return false;
+  if ((query.kind === 'method' || query.receiver) && node.label !== 'Method') return false;
+  if (query.receiver && p.receiverType !== query.receiver.replace(/^\*/, '')) return false;
+  if (query.exportedOnly && !p.isExported) return false;
+  if (query.excludeTests && isGoTestFile(p.filePath)) return false;
+
+  const parsed = parseSignature(p.signature);
+  if (!parsed) return false;
+  if (query.params && !matchesTypeList(query.params, parsed.params)) return false;
+  if (query.returns && !matchesTypeList(query.returns, parsed.results)) return false;
+  return true;
+};
+
+/** Funcs and methods matching the query, sorted by file, line, name */
+export const searchBySignature = (graph: KnowledgeGraph, query: SignatureQuery): SignatureSearchResult[] => {
+  const results: SignatureSearchResult[] = [];
+  graph.forEachNode(node => {
+    if (!matchesSignature(node, query)) return;
+    const p = node.properties;
+    const parsed = parseSignature(p.signature!)!;
+    results.push({
+      id: node.id,
+      name: p.name,
+      label: node.label,
+      filePath: p.filePath,
+      ...(p.startLine !== undefined ? { startLine: p.startLine } : {}),
+      ...(p.receiverType ? { receiverType: p.receiverType } : {}),
+      signature: p.signature!,
+      params: parsed.params,
+      results: parsed.results,
+    });
+  });
+  const cmp = (a: string, b: string) => (a < b ? -1 : a > b ? 1 : 0);
+  return results.sort((a, b) =>
+    cmp(a.filePath, b.filePath) || (a.startLine ?? 0) - (b.startLine ?? 0) || cmp(a.name, b.name));
+};
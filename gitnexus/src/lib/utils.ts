@@ -1,3 +1,29 @@
+import { createHash } from 'crypto';
+
 export const generateId = (label: string, name: string): string => {
   return `${label}:${name}`
-}
\ No newline at end of file
+}
+
+/**
+ * Part of a symbol id after the file path: `Name`, `Recv.Name` for Go
+ * methods, and `#n` for the n-th (n >= 2) declaration with the same id in
+ * one file, such as several `func init()` or `var _ = ...`. See symbol-ids.
+ */
+export const symbolIdName = (name: string, receiverType?: string, ordinal: number = 1): string =>
+  `${receiverType ? `${receiverType}.` : ''}${name}${ordinal > 1 ? `#${ordinal}` : ''}`;
+
+/**
+ * Directory of a repo-relative, '/'-separated path: the Go package directory
+ * of a source file. '' for a file at the repo root.
+ */
+export const goPackageDir = (filePath: string): string => {
+  const idx = filePath.lastIndexOf('/');
+  return idx >= 0 ? filePath.substring(0, idx) : '';
+};
+
+/** Is the path a Go test file (`_test.go`)? */
+export const isGoTestFile = (filePath: string): boolean => filePath.endsWith('_test.go');
+
+/** Hex sha256 of file content, as read (UTF-8) */
+export const hashContent = (content: string): string =>
+  createHash('sha256').update(content, 'utf8').digest('hex');
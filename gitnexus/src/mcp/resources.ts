@@ -331,6 +331,8 @@ relationships:
   - MEMBER_OF: Symbol belongs to community
   - STEP_IN_PROCESS: Symbol is step N in process
 
+symbol_meta: "Symbol tables have a meta column: annotator key/values as JSON text, '' when none. Filter with CONTAINS, e.g. WHERE f.meta CONTAINS '\"route\":\"/users\"'"
+
 relationship_table: "All relationships use a single CodeRelation table with a 'type' property. Properties: type (STRING), confidence (DOUBLE), reason (STRING), step (INT32)"
 
 example_queries:
@@ -2,7 +2,8 @@
  * HTTP API Server
  *
  * REST API for browser-based clients to query the local .gitnexus/ index.
- * Also hosts the MCP server over StreamableHTTP for remote AI tool access.
+ * Also hosts the MCP server over StreamableHTTP for remote AI tool access,
+ * and the in-memory graph queries with per-commit graphs (see graph-api.ts).
  *
  * Security: binds to 127.0.0.1 by default (use --host to override).
  * CORS is restricted to localhost and the deployed site.
@@ -22,6 +23,7 @@ import { hybridSearch } from '../core/search/hybrid-search.js';
 // at server startup — crashes on unsupported Node ABI versions (#89)
 import { LocalBackend } from '../mcp/local/local-backend.js';
 import { mountMCPEndpoints } from './mcp-http.js';
+import { mountGraphEndpoints, createRepoGraphProvider, GraphProvider } from './graph-api.js';
 
 const buildGraph = async (): Promise<{ nodes: GraphNode[]; relationships: GraphRelationship[] }> => {
   const nodes: GraphNode[] = [];
@@ -138,6 +140,19 @@ export const createServer = async (port: number, host: string = '127.0.0.1') =>
     return repos[0]; // default to first
   };
 
+  // Graph queries (symbols, callers, definitions, export; ?commit=) and /healthz
+  const graphProviders = new Map<string, GraphProvider>();
+  mountGraphEndpoints(app, async (repoName) => {
+    const entry = await resolveRepo(repoName);
+    if (!entry) return null;
+    let provider = graphProviders.get(entry.path);
+    if (!provider) {
+      provider = createRepoGraphProvider(entry.path);
+      graphProviders.set(entry.path, provider);
+    }
+    return provider;
+  });
+
   // List all registered repos
   app.get('/api/repos', async (_req, res) => {
     try {
@@ -0,0 +1,465 @@
+/**
+ * Graph Query API
+ *
+ * JSON endpoints over the in-memory knowledge graph, for running GitNexus
+ * as a service: symbol search, callers and callees, go to definition, and
+ * the JSON graph export. Unlike the Kuzu-backed routes in api.ts these need
+ * no prior `gitnexus analyze`: graphs are built by the pipeline on first
+ * use and kept in the graph cache (.gitnexus/cache/).
+ *
+ * Every route takes `commit` (anything git can resolve to a commit) to
+ * query that commit's graph instead of the working tree's; it is built on
+ * demand, and the most recently used ones stay in memory. Without `commit`
+ * the working tree is analyzed on first request, and again whenever HEAD or
+ * the working tree has changed since (see getWorkingTreeState). `file`
+ * parameters must stay inside the repo (403 otherwise). Lines and columns
+ * are 0-based, columns in bytes, as everywhere in the graph.
+ *
+ *   GET /healthz                                       liveness; never builds a graph
+ *   GET /api/symbols?q=Serve&kind=func,method&limit=20 name search (searchSymbolsByName)
+ *   GET /api/symbols?q=Handle&meta=route               ... of symbols with annotator metadata (or meta=route=/users)
+ *   GET /api/callers?symbol=Serve&depth=2              callers, breadth-first (findCallers)
+ *   GET /api/callees?symbol=Server.Serve               calls out of a symbol, external ones included
+ *   GET /api/definition?file=cmd/main.go&line=9&column=4  go to definition (Go files)
+ *   GET /api/local-issues?symbol=Server.Serve          unused and shadowed locals (or file=, strict=true)
+ *   GET /api/duplicates?minLines=3                     functions with identical bodies (findDuplicateBodies)
+ *   GET /api/export?idScheme=qualified                 the graph as written by --export-json
+ *
+ * `symbol` is a node id, a name, or `Recv.Method`; a name that matches more
+ * than one symbol is a 409 listing the candidates. Errors are `{ error }`
+ * with a 4xx/5xx status, like the rest of the API.
+ */
+
+import http from 'http';
+import path from 'path';
+import express from 'express';
+import type { Express, Request, Response } from 'express';
+import { LRUCache } from 'lru-cache';
+import { KnowledgeGraph, GraphNode } from '../core/graph/types.js';
+import { findCallers, getCallEdges } from '../core/graph/call-graph.js';
+import { createDefinitionResolver, DefinitionFileReader, DefinitionNotFoundError } from '../core/graph/definition.js';
+import { createLocalIssueAnalyzer } from '../core/graph/local-issues.js';
+import { findDuplicateBodies } from '../core/graph/duplicate-bodies.js';
+import { writeGraphJSON, GraphJSONKind } from '../core/graph/json-export.js';
+import type { SymbolIdScheme } from '../core/graph/symbol-ids.js';
+import { searchSymbolsByName } from '../core/search/name-search.js';
+import { createCommitSource, createWorkingTreeSource } from '../core/ingestion/filesystem-walker.js';
+import { loadOrBuild } from '../storage/graph-cache.js';
+import { resolveCommit, getWorkingTreeState } from '../storage/git.js';
+import { PipelineResult } from '../types/pipeline.js';
+
+export interface GraphSnapshot {
+  result: PipelineResult;
+  /** Repository the graph was built from */
+  repoPath: string;
+  /** Commit the graph was built from; undefined for a dirty working tree */
+  commit?: string;
+  /** Reads files as of the graph, for definitions */
+  readFile: DefinitionFileReader;
+}
+
+export interface GraphProvider {
+  /** The graph at `ref` (sha, branch, tag), or the working tree's when omitted */
+  get: (ref?: string) => Promise<GraphSnapshot>;
+}
+
+export interface RepoGraphProviderOptions {
+  /** Graph of the working tree, when the caller already built it */
+  initial?: PipelineResult;
+  /** Commit graphs kept in memory (default 4); the graph cache keeps the rest on disk */
+  maxCommits?: number;
+  /** How long a working-tree check is reused, in ms (default 1000) */
+  stateTtlMs?: number;
+}
+
+/** An error with the HTTP status to answer with */
+export class GraphQueryError extends Error {
+  constructor(
+    public readonly status: number,
+    message: string,
+    public readonly details: Record<string, unknown> = {},
+  ) {
+    super(message);
+    this.name = 'GraphQueryError';
+  }
+}
+
+const DEFAULT_MAX_COMMITS = 4;
+const DEFAULT_STATE_TTL_MS = 1000;
+
+/**
+ * `file` as a repo-relative path. Throws a 403 when it resolves outside the
+ * repo, like /api/file.
+ */
+export const resolveRepoFile = (repoPath: string, file: string): string => {
+  const repoRoot = path.resolve(repoPath);
+  const fullPath = path.resolve(repoRoot, file);
+  if (!fullPath.startsWith(repoRoot + path.sep) && fullPath !== repoRoot) {
+    throw new GraphQueryError(403, 'Path traversal denied');
+  }
+  return path.relative(repoRoot, fullPath).split(path.sep).join('/');
+};
+
+/**
+ * Graphs of the repository at `repoPath`: the working tree's, rebuilt when
+ * HEAD or the tree changes, and commits' on demand through the graph cache.
+ * Concurrent requests for one graph share a single build; a failed build is
+ * retried next time. The working tree is checked asynchronously, at most
+ * once per `stateTtlMs`, and concurrent requests share the check.
+ */
+export const createRepoGraphProvider = (repoPath: string, options: RepoGraphProviderOptions = {}): GraphProvider => {
+  const snapshotOf = (result: PipelineResult): GraphSnapshot => {
+    const source = result.commit ? createCommitSource(repoPath, result.commit) : createWorkingTreeSource(repoPath);
+    return {
+      result,
+      repoPath,
+      ...(result.commit ? { commit: result.commit } : {}),
+      // Paths come from requests as well as from the graph
+      readFile: async (file) => source.readFile(resolveRepoFile(repoPath, file)),
+    };
+  };
+
+  const stateTtlMs = options.stateTtlMs ?? DEFAULT_STATE_TTL_MS;
+  let stateCheck: { state: Promise<string>; at: number } | null = null;
+  const checkState = (): Promise<string> => {
+    if (!stateCheck || Date.now() - stateCheck.at > stateTtlMs) {
+      // Pending checks never expire; the TTL starts once the check settles
+      const check = { state: getWorkingTreeState(repoPath), at: Infinity };
+      check.state.finally(() => { check.at = Date.now(); });
+      stateCheck = check;
+    }
+    return stateCheck.state;
+  };
+
+  let workingTree: { state: string; snapshot: Promise<GraphSnapshot> } | null = null;
+  const initial = options.initial;
+  const initialTree = initial
+    ? checkState().then((state) => { workingTree ??= { state, snapshot: Promise.resolve(snapshotOf(initial)) }; })
+    : Promise.resolve();
+  const commits = new LRUCache<string, Promise<GraphSnapshot>>({ max: options.maxCommits ?? DEFAULT_MAX_COMMITS });
+
+  const build = (sha?: string): Promise<GraphSnapshot> =>
+    loadOrBuild(repoPath, sha).then(({ result }) => snapshotOf(sha ? { ...result, commit: sha } : result));
+
+  return {
+    get: (ref) => {
+      if (!ref) {
+        return initialTree.then(checkState).then((state) => {
+          if (!workingTree || state !== workingTree.state) {
+            const pending = build();
+            pending.catch(() => {
+              if (workingTree?.snapshot === pending) workingTree = null;
+            });
+            workingTree = { state, snapshot: pending };
+          }
+          return workingTree.snapshot;
+        });
+      }
+      let sha: string;
+      try {
+        sha = resolveCommit(repoPath, ref);
+      } catch {
+        return Promise.reject(new GraphQueryError(404, `Unknown commit: ${ref}`));
+      }
+      let snapshot = commits.get(sha);
+      if (!snapshot) {
+        const pending = build(sha);
+        pending.catch(() => {
+          if (commits.get(sha) === pending) commits.delete(sha);
+        });
+        commits.set(sha, pending);
+        snapshot = pending;
+      }
+      return snapshot;
+    },
+  };
+};
+
+// ============================================================================
+// QUERIES
+// ============================================================================
+
+export type GraphQueryParams = Record<string, string | undefined>;
+
+const SYMBOL_KINDS = new Set<string>(['func', 'method', 'type', 'field', 'const', 'var', 'package', 'module', 'file', 'folder']);
+const MAX_SEARCH_LIMIT = 100;
+
+const required = (params: GraphQueryParams, name: string): string => {
+  const value = params[name]?.trim();
+  if (!value) throw new GraphQueryError(400, `Missing "${name}" query parameter`);
+  return value;
+};
+
+const integer = (params: GraphQueryParams, name: string, fallback?: number): number => {
+  const raw = params[name];
+  if (raw === undefined || raw === '') {
+    if (fallback !== undefined) return fallback;
+    throw new GraphQueryError(400, `Missing "${name}" query parameter`);
+  }
+  const value = Number(raw);
+  if (!Number.isInteger(value) || value < 0) throw new GraphQueryError(400, `"${name}" must be a non-negative integer`);
+  return value;
+};
+
+const summarize = (node: GraphNode) => ({
+  id: node.id,
+  name: node.properties.name,
+  label: node.label,
+  filePath: node.properties.filePath,
+  ...(node.properties.startLine !== undefined ? { startLine: node.properties.startLine } : {}),
+  ...(node.properties.receiverType ? { receiverType: node.properties.receiverType } : {}),
+  ...(node.properties.meta ? { meta: node.properties.meta } : {}),
+});
+
+/** The node a `symbol` parameter names: an id, a name, or `Recv.Method` */
+export const resolveSymbolParam = (graph: KnowledgeGraph, symbol: string): GraphNode => {
+  const byId = graph.getNode(symbol);
+  if (byId) return byId;
+
+  const exact = (name: string) => searchSymbolsByName(graph, name, { limit: MAX_SEARCH_LIMIT })
+    .filter(r => r.matchType === 'exact')
+    .map(r => graph.getNode(r.id)!);
+  let matches = exact(symbol);
+  const dot = symbol.lastIndexOf('.');
+  if (matches.length === 0 && dot > 0) {
+    const receiver = symbol.substring(0, dot);
+    matches = exact(symbol.substring(dot + 1)).filter(n => n.label === 'Method' && n.properties.receiverType === receiver);
+  }
+  if (matches.length === 0) throw new GraphQueryError(404, `Unknown symbol: ${symbol}`);
+  if (matches.length > 1) {
+    throw new GraphQueryError(409, `Ambiguous symbol: ${symbol} (${matches.length} matches; pass a node id)`, {
+      matches: matches.map(summarize),
+    });
+  }
+  return matches[0];
+};
+
+const resolvers = new WeakMap<GraphSnapshot, ReturnType<typeof createDefinitionResolver>>();
+
+const definitionResolver = (snapshot: GraphSnapshot) => {
+  let resolver = resolvers.get(snapshot);
+  if (!resolver) {
+    resolver = createDefinitionResolver(snapshot.result.graph, snapshot.readFile);
+    resolvers.set(snapshot, resolver);
+  }
+  return resolver;
+};
+
+const analyzers = new WeakMap<GraphSnapshot, ReturnType<typeof createLocalIssueAnalyzer>>();
+
+const localIssueAnalyzer = (snapshot: GraphSnapshot) => {
+  let analyzer = analyzers.get(snapshot);
+  if (!analyzer) {
+    analyzer = createLocalIssueAnalyzer(snapshot.result.graph, snapshot.readFile);
+    analyzers.set(snapshot, analyzer);
+  }
+  return analyzer;
+};
+
+/** JSON query routes: the snapshot and query string in, the response body out */
+export const GRAPH_QUERY_ROUTES: Record<string, (snapshot: GraphSnapshot, params: GraphQueryParams) => Promise<object>> = {
+  '/api/symbols': async (snapshot, params) => {
+    const query = required(params, 'q');
+    const kinds = params.kind?.split(',').map(k => k.trim()).filter(Boolean);
+    const unknown = kinds?.find(k => !SYMBOL_KINDS.has(k));
+    if (unknown) throw new GraphQueryError(400, `Unknown kind: ${unknown}`);
+    const limit = Math.max(1, Math.min(MAX_SEARCH_LIMIT, integer(params, 'limit', 20)));
+    // `meta=key` (any value) or `meta=key=value`; the value may itself contain `=`
+    const [metaKey, ...metaValue] = params.meta?.split('=') ?? [];
+    const meta = metaKey ? { [metaKey]: metaValue.length > 0 ? metaValue.join('=') : true } : null;
+    const results = searchSymbolsByName(snapshot.result.graph, query, {
+      limit,
+      ...(kinds?.length ? { kinds: kinds as GraphJSONKind[] } : {}),
+      ...(meta ? { meta } : {}),
+    });
+    return { ...(snapshot.commit ? { commit: snapshot.commit } : {}), results };
+  },
+
+  '/api/callers': async (snapshot, params) => {
+    const node = resolveSymbolParam(snapshot.result.graph, required(params, 'symbol'));
+    const callers = findCallers(snapshot.result.graph, node.id, integer(params, 'depth', 1));
+    return { ...(snapshot.commit ? { commit: snapshot.commit } : {}), symbol: summarize(node), callers };
+  },
+
+  '/api/callees': async (snapshot, params) => {
+    const node = resolveSymbolParam(snapshot.result.graph, required(params, 'symbol'));
+    const externalCalls = (snapshot.result.externalCalls ?? []).filter(c => c.sourceId === node.id);
+    const callees = getCallEdges(snapshot.result.graph, externalCalls).filter(e => e.callerId === node.id);
+    return { ...(snapshot.commit ? { commit: snapshot.commit } : {}), symbol: summarize(node), callees };
+  },
+
+  '/api/definition': async (snapshot, params) => {
+    const file = resolveRepoFile(snapshot.repoPath, required(params, 'file'));
+    const line = integer(params, 'line');
+    const column = integer(params, 'column');
+    try {
+      const definition = await definitionResolver(snapshot).findDefinition(file, line, column);
+      return { ...(snapshot.commit ? { commit: snapshot.commit } : {}), definition };
+    } catch (err) {
+      if (err instanceof GraphQueryError) throw err;
+      if (err instanceof DefinitionNotFoundError) {
+        throw new GraphQueryError(404, err.message, {
+          reason: err.reason,
+          ...(err.identifier ? { identifier: err.identifier } : {}),
+        });
+      }
+      const message = err instanceof Error ? err.message : String(err);
+      throw new GraphQueryError(message.startsWith('Cannot read') ? 404 : 400, message);
+    }
+  },
+
+  '/api/local-issues': async (snapshot, params) => {
+    const strict = params.strict === 'true' || params.strict === '1';
+    const analyzer = localIssueAnalyzer(snapshot);
+    const commit = snapshot.commit ? { commit: snapshot.commit } : {};
+    try {
+      if (params.file) {
+        const file = resolveRepoFile(snapshot.repoPath, params.file);
+        return { ...commit, issues: await analyzer.getFileLocalIssues(file, { strict }) };
+      }
+      const node = resolveSymbolParam(snapshot.result.graph, required(params, 'symbol'));
+      return { ...commit, symbol: summarize(node), issues: await analyzer.getLocalIssues(node.id, { strict }) };
+    } catch (err) {
+      if (err instanceof GraphQueryError) throw err;
+      const message = err instanceof Error ? err.message : String(err);
+      throw new GraphQueryError(message.startsWith('Cannot read') ? 404 : 400, message);
+    }
+  },
+
+  '/api/duplicates': async (snapshot, params) => {
+    const groups = findDuplicateBodies(snapshot.result.graph, { minLines: integer(params, 'minLines', 3) });
+    return { ...(snapshot.commit ? { commit: snapshot.commit } : {}), groups };
+  },
+};
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+const queryParams = (req: Request): GraphQueryParams => {
+  const params: GraphQueryParams = {};
+  for (const [key, value] of Object.entries(req.query)) {
+    if (typeof value === 'string') params[key] = value;
+  }
+  return params;
+};
+
+const sendError = (res: Response, err: unknown): void => {
+  if (res.headersSent) {
+    // Mid-stream (export): the status is gone, so cut the response short
+    res.destroy(err instanceof Error ? err : undefined);
+    return;
+  }
+  if (err instanceof GraphQueryError) {
+    res.status(err.status).json({ error: err.message, ...err.details });
+    return;
+  }
+  res.status(500).json({ error: err instanceof Error ? err.message : 'Graph query failed' });
+};
+
+/**
+ * Mount /healthz and the graph query routes on `app`. `providerFor` picks
+ * the repository from the `repo` parameter; null answers 404.
+ */
+export const mountGraphEndpoints = (
+  app: Express,
+  providerFor: (repo?: string) => Promise<GraphProvider | null>,
+): void => {
+  const snapshotFor = async (params: GraphQueryParams): Promise<GraphSnapshot> => {
+    const provider = await providerFor(params.repo);
+    if (!provider) throw new GraphQueryError(404, 'Repository not found');
+    return provider.get(params.commit?.trim() || undefined);
+  };
+
+  app.get('/healthz', (_req, res) => {
+    res.json({ status: 'ok' });
+  });
+
+  for (const [route, handler] of Object.entries(GRAPH_QUERY_ROUTES)) {
+    app.get(route, async (req, res) => {
+      try {
+        const params = queryParams(req);
+        res.json(await handler(await snapshotFor(params), params));
+      } catch (err) {
+        sendError(res, err);
+      }
+    });
+  }
+
+  app.get('/api/export', async (req, res) => {
+    try {
+      const params = queryParams(req);
+      const idScheme = params.idScheme ?? 'path';
+      if (idScheme !== 'path' && idScheme !== 'qualified') {
+        throw new GraphQueryError(400, `Unknown idScheme: ${idScheme} (expected path or qualified)`);
+      }
+      const snapshot = await snapshotFor(params);
+      res.type('application/json');
+      await writeGraphJSON(snapshot.result.graph, res, {
+        repoPath: snapshot.result.repoPath,
+        ...(snapshot.commit ? { commit: snapshot.commit } : {}),
+        ...(snapshot.result.externalCalls ? { externalCalls: snapshot.result.externalCalls } : {}),
+        idScheme: idScheme as SymbolIdScheme,
+      });
+      res.end();
+    } catch (err) {
+      sendError(res, err);
+    }
+  });
+};
+
+export interface GraphServerOptions {
+  port: number;
+  host?: string;
+  /** Shuts the server down when aborted, like close() */
+  signal?: AbortSignal;
+  /** How long close() waits for requests in flight before dropping them (default 10s) */
+  shutdownTimeoutMs?: number;
+}
+
+export interface GraphServer {
+  server: http.Server;
+  url: string;
+  /** Stop accepting connections, let requests in flight finish, then resolve */
+  close: () => Promise<void>;
+}
+
+const DEFAULT_SHUTDOWN_TIMEOUT_MS = 10_000;
+
+/**
+ * A standalone server for one repository's graphs: /healthz and the graph
+ * query routes, no Kuzu index or MCP. Resolves once it is listening.
+ */
+export const startGraphServer = async (provider: GraphProvider, options: GraphServerOptions): Promise<GraphServer> => {
+  const host = options.host ?? '127.0.0.1';
+  const app = express();
+  mountGraphEndpoints(app, async () => provider);
+
+  const server = await new Promise<http.Server>((resolve, reject) => {
+    const listening = app.listen(options.port, host, () => resolve(listening));
+    listening.once('error', reject);
+  });
+
+  let closing: Promise<void> | null = null;
+  const close = (): Promise<void> => {
+    closing ??= new Promise<void>(resolve => {
+      const timeout = setTimeout(() => server.closeAllConnections(), options.shutdownTimeoutMs ?? DEFAULT_SHUTDOWN_TIMEOUT_MS);
+      timeout.unref();
+      server.close(() => {
+        clearTimeout(timeout);
+        options.signal?.removeEventListener('abort', onAbort);
+        resolve();
+      });
+      // Keep-alive connections with no request in flight would hold close() open
+      server.closeIdleConnections();
+    });
+    return closing;
+  };
+  const onAbort = () => { void close(); };
+  if (options.signal?.aborted) void close();
+  else options.signal?.addEventListener('abort', onAbort);
+
+  const address = server.address();
+  const port = typeof address === 'object' && address ? address.port : options.port;
+  return { server, url: `http://${host}:${port}`, close };
+};
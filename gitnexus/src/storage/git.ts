@@ -1,4 +1,9 @@
-import { execSync } from 'child_process';
+import { execSync, execFile, execFileSync, spawnSync } from 'child_process';
+import fs from 'fs';
+import path from 'path';
+import { promisify } from 'util';
+
+const execFileAsync = promisify(execFile);
 
 // Git utilities for repository detection, commit tracking, and diff analysis
 
@@ -19,6 +24,74 @@ export const getCurrentCommit = (repoPath: string): string => {
   }
 };
 
+/**
+ * Fingerprint of the working tree: HEAD plus the status, size and mtime of
+ * every changed path. It changes when HEAD moves or a file is edited, added
+ * or removed, including further edits to an already modified file. Async:
+ * `git status` walks the whole tree, too slow to block a server on. Empty
+ * when repoPath isn't a git repo.
+ */
+export const getWorkingTreeState = async (repoPath: string): Promise<string> => {
+  let head: string;
+  let status: string;
+  try {
+    const git = (args: string[]) => execFileAsync('git', args, { cwd: repoPath, maxBuffer: 256 * 1024 * 1024 });
+    const [headOut, statusOut] = await Promise.all([
+      git(['rev-parse', 'HEAD']).catch(() => ({ stdout: '' })),
+      git(['status', '--porcelain', '-z', '--untracked-files=all', '--', ':/', ':(exclude,top).gitnexus']),
+    ]);
+    head = headOut.stdout.toString().trim();
+    status = statusOut.stdout.toString();
+  } catch {
+    return '';
+  }
+  const entries = status.split('\0').filter(Boolean);
+  const stats = await Promise.all(entries.map(async entry => {
+    // `XY path`; the original path of a rename follows as its own entry
+    const filePath = entry.length > 3 && entry[2] === ' ' ? entry.substring(3) : entry;
+    try {
+      const s = await fs.promises.stat(path.join(repoPath, filePath));
+      return `${s.size}:${s.mtimeMs}`;
+    } catch {
+      return '-'; // Deleted
+    }
+  }));
+  return [head, ...entries.map((entry, i) => `${entry}\t${stats[i]}`)].join('\n');
+};
+
+/**
+ * Uncommitted changes in the working tree, untracked files included.
+ * Ignored files and GitNexus's own .gitnexus/ directory don't count.
+ */
+export const isWorkingTreeDirty = (repoPath: string): boolean => {
+  try {
+    return execFileSync('git', ['status', '--porcelain', '-z', '--untracked-files=normal', '--', ':/', ':(exclude,top).gitnexus'], {
+      cwd: repoPath,
+    })
+      .toString()
+      .length > 0;
+  } catch {
+    return true;
+  }
+};
+
+/**
+ * The subset of `paths` (repo-relative) that .gitignore, .git/info/exclude
+ * or the global excludes file ignore. Tracked files are never reported, as
+ * in git itself. Empty when repoPath isn't inside a work tree.
+ */
+export const getIgnoredPaths = (repoPath: string, paths: string[]): Set<string> => {
+  if (paths.length === 0) return new Set();
+  const result = spawnSync('git', ['check-ignore', '--stdin', '-z'], {
+    cwd: repoPath,
+    input: paths.join('\0') + '\0',
+    maxBuffer: 256 * 1024 * 1024,
+  });
+  // 0: some paths ignored, 1: none, 128: not a repository
+  if (result.status !== 0) return new Set();
+  return new Set(result.stdout.toString().split('\0').filter(Boolean));
+};
+
 /**
  * Find the git repository root from any path inside the repo
  */
@@ -31,3 +104,345 @@ export const getGitRoot = (fromPath: string): string | null => {
     return null;
   }
 };
+
+/**
+ * Resolve a ref (branch, tag, short sha) to a full commit sha. Throws when
+ * the ref doesn't name a commit. Works in bare repositories.
+ */
+export const resolveCommit = (repoPath: string, ref: string): string =>
+  execFileSync('git', ['rev-parse', '--verify', '--quiet', `${ref}^{commit}`], { cwd: repoPath })
+    .toString()
+    .trim();
+
+export interface GitTreeEntry {
+  path: string;
+  /** Blob size in bytes */
+  size: number;
+}
+
+/**
+ * Regular files in a commit's tree, recursively. Submodules and symlinks
+ * are skipped. Reads the object store only — no checkout, works in bare
+ * repositories.
+ */
+export const listFilesAtCommit = (repoPath: string, ref: string): GitTreeEntry[] => {
+  const output = execFileSync('git', ['ls-tree', '-r', '-l', '-z', '--full-tree', ref], {
+    cwd: repoPath,
+    maxBuffer: 256 * 1024 * 1024,
+  }).toString();
+
+  // "<mode> <type> <oid> <size>\t<path>\0"
+  const entries: GitTreeEntry[] = [];
+  for (const record of output.split('\0')) {
+    const tab = record.indexOf('\t');
+    if (tab < 0) continue;
+    const [mode, type, , size] = record.substring(0, tab).split(/\s+/);
+    if (type !== 'blob' || mode === '120000') continue;
+    entries.push({ path: record.substring(tab + 1), size: parseInt(size, 10) || 0 });
+  }
+  return entries;
+};
+
+export type GitChangeStatus = 'added' | 'modified' | 'deleted' | 'renamed' | 'copied' | 'type-changed';
+
+export interface GitFileChange {
+  status: GitChangeStatus;
+  /** Repo-relative path (the new path for renames/copies) */
+  path: string;
+  /** Previous path for renames/copies */
+  oldPath?: string;
+}
+
+const STATUS_CODES: Record<string, GitChangeStatus> = {
+  A: 'added', M: 'modified', D: 'deleted', R: 'renamed', C: 'copied', T: 'type-changed',
+};
+
+/**
+ * Files changed between two commits, with rename detection.
+ * Throws when either ref is unknown — callers decide whether to fall back
+ * to a full re-index.
+ */
+export const getChangedFiles = (repoPath: string, fromRef: string, toRef: string): GitFileChange[] => {
+  const output = execFileSync('git', ['diff', '--name-status', '-M', '-z', fromRef, toRef, '--'], {
+    cwd: repoPath,
+    maxBuffer: 256 * 1024 * 1024,
+  }).toString();
+
+  // -z output: STATUS\0path\0 — or STATUS\0old\0new\0 for R/C
+  const tokens = output.split('\0').filter(Boolean);
+  const changes: GitFileChange[] = [];
+  for (let i = 0; i < tokens.length; i++) {
+    const status = STATUS_CODES[tokens[i][0]];
+    if (!status) continue;
+    if (status === 'renamed' || status === 'copied') {
+      changes.push({ status, oldPath: tokens[i + 1], path: tokens[i + 2] });
+      i += 2;
+    } else {
+      changes.push({ status, path: tokens[i + 1] });
+      i += 1;
+    }
+  }
+  return changes;
+};
+
+/**
+ * Read file contents as of a commit in one `git cat-file --batch` call.
+ * Paths missing at that commit are left out of the result.
+ */
+export const readFilesAtCommit = (repoPath: string, ref: string, paths: string[]): Map<string, string> => {
+  const contents = new Map<string, string>();
+  if (paths.length === 0) return contents;
+
+  const output = execFileSync('git', ['cat-file', '--batch'], {
+    cwd: repoPath,
+    input: paths.map(p => `${ref}:${p}`).join('\n') + '\n',
+    maxBuffer: 1024 * 1024 * 1024,
+  });
+
+  // Each entry: "<oid> <type> <size>\n<content>\n", or "<spec> missing\n"
+  let offset = 0;
+  for (const filePath of paths) {
+    const headerEnd = output.indexOf(0x0a, offset);
+    if (headerEnd < 0) break;
+    const header = output.subarray(offset, headerEnd).toString();
+    offset = headerEnd + 1;
+    if (header.endsWith(' missing')) continue;
+    const parts = header.split(' ');
+    if (parts.length !== 3) continue;
+    const size = parseInt(parts[2], 10);
+    if (parts[1] === 'blob') {
+      contents.set(filePath, output.subarray(offset, offset + size).toString('utf-8'));
+    }
+    offset += size + 1;
+  }
+  return contents;
+};
+
+export interface BlameLine {
+  commit: string;
+  author: string;
+  authorEmail: string;
+  /** Author time, seconds since epoch */
+  authorTime: number;
+}
+
+/**
+ * `git blame` for a whole file, one entry per line (index 0 = first line).
+ * Blaming at `ref` (rather than the working tree) keeps line numbers aligned
+ * with a graph built at that commit. `-M` follows lines moved within the
+ * file, `-w` ignores whitespace-only changes.
+ */
+export const blameFile = (repoPath: string, filePath: string, ref: string = 'HEAD'): BlameLine[] => {
+  const output = execFileSync('git', ['blame', '--line-porcelain', '-w', '-M', ref, '--', filePath], {
+    cwd: repoPath,
+    maxBuffer: 256 * 1024 * 1024,
+  }).toString();
+
+  // --line-porcelain repeats the full header for every line:
+  // "<sha> <orig> <final> [<count>]", "author X", "author-mail <x>", ..., "\t<content>"
+  const lines: BlameLine[] = [];
+  let current: Partial<BlameLine> = {};
+  for (const row of output.split('\n')) {
+    if (row.startsWith('\t')) {
+      lines.push({
+        commit: current.commit ?? '',
+        author: current.author ?? '',
+        authorEmail: current.authorEmail ?? '',
+        authorTime: current.authorTime ?? 0,
+      });
+      current = {};
+    } else if (/^[0-9a-f]{40,64} \d+ \d+/.test(row)) {
+      current.commit = row.substring(0, row.indexOf(' '));
+    } else if (row.startsWith('author ')) {
+      current.author = row.substring('author '.length);
+    } else if (row.startsWith('author-mail ')) {
+      current.authorEmail = row.substring('author-mail '.length).replace(/^<|>$/g, '');
+    } else if (row.startsWith('author-time ')) {
+      current.authorTime = parseInt(row.substring('author-time '.length), 10);
+    }
+  }
+  return lines;
+};
+
+/** One `@@ -oldStart,oldCount +newStart,newCount @@` hunk (1-based lines) */
+export interface DiffHunk {
+  oldStart: number;
+  oldCount: number;
+  newStart: number;
+  newCount: number;
+}
+
+export interface CommitFileDiff {
+  /** Path after the commit; null when the file was deleted */
+  path: string | null;
+  /** Path before the commit; null when the file was added */
+  oldPath: string | null;
+  hunks: DiffHunk[];
+}
+
+export interface CommitDiff {
+  commit: string;
+  /** Author time, seconds since epoch */
+  authorTime: number;
+  files: CommitFileDiff[];
+}
+
+export interface CommitHistoryOptions {
+  /** Newest commit to start from (default HEAD) */
+  ref?: string;
+  /** Only commits after this date (anything `git log --since` accepts) */
+  since?: string | Date;
+  maxCommits?: number;
+}
+
+const HUNK_HEADER = /^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@/;
+
+const C_ESCAPES: Record<string, number> = { a: 7, b: 8, t: 9, n: 10, v: 11, f: 12, r: 13, '"': 34, '\\': 92 };
+
+/**
+ * A path as git prints it: unusual names (non-ASCII under core.quotePath,
+ * spaces in some headers, control characters) come C-quoted, with octal
+ * escapes for UTF-8 bytes: `"a/\303\244.go"` -> `a/ä.go`.
+ */
+const unquoteGitPath = (p: string): string => {
+  if (p.length < 2 || !p.startsWith('"') || !p.endsWith('"')) return p;
+  const bytes: number[] = [];
+  for (let i = 1; i < p.length - 1; i++) {
+    if (p[i] !== '\\') {
+      const ch = String.fromCodePoint(p.codePointAt(i)!);
+      bytes.push(...Buffer.from(ch, 'utf8'));
+      i += ch.length - 1;
+    } else if (/[0-7]/.test(p[i + 1])) {
+      bytes.push(parseInt(p.substring(i + 1, i + 4), 8));
+      i += 3;
+    } else {
+      const next = p[++i];
+      bytes.push(C_ESCAPES[next] ?? next.charCodeAt(0));
+    }
+  }
+  return Buffer.from(bytes).toString('utf8');
+};
+
+/** A `diff --git`, `---` or `+++` path to a repo path; null for /dev/null */
+const stripDiffPrefix = (p: string): string | null => {
+  // `---`/`+++` names with a space get a trailing tab
+  const unquoted = unquoteGitPath(p.endsWith('\t') ? p.slice(0, -1) : p);
+  return unquoted === '/dev/null' ? null : unquoted.replace(/^[ab]\//, '');
+};
+
+/** The two paths of a `diff --git a/x b/y` line, either of them possibly quoted */
+const splitDiffGitPaths = (paths: string): [string, string] | null => {
+  if (paths.startsWith('"')) {
+    let end = 1;
+    while (end < paths.length && paths[end] !== '"') end += paths[end] === '\\' ? 2 : 1;
+    return end < paths.length ? [paths.substring(0, end + 1), paths.substring(end + 2)] : null;
+  }
+  const quoted = paths.lastIndexOf(' "b/');
+  const split = quoted > 0 ? quoted : paths.lastIndexOf(' b/');
+  return split > 0 ? [paths.substring(0, split), paths.substring(split + 1)] : null;
+};
+
+/**
+ * Zero-context diffs of the first-parent history, newest first. Merges are
+ * diffed against their first parent so line numbers chain from one commit
+ * to the next.
+ */
+export const getCommitHistoryDiffs = (repoPath: string, options: CommitHistoryOptions = {}): CommitDiff[] => {
+  const args = ['log', '--first-parent', '-m', '-M', '-U0', '-p', '--no-color', '--no-ext-diff',
+    '--format=%x00%H %at'];
+  if (options.maxCommits) args.push(`--max-count=${options.maxCommits}`);
+  if (options.since) {
+    args.push(`--since=${options.since instanceof Date ? options.since.toISOString() : options.since}`);
+  }
+  args.push(options.ref || 'HEAD', '--');
+
+  const output = execFileSync('git', args, {
+    cwd: repoPath,
+    maxBuffer: 1024 * 1024 * 1024,
+  }).toString();
+
+  const commits: CommitDiff[] = [];
+  for (const chunk of output.split('\0')) {
+    if (!chunk.trim()) continue;
+    const lines = chunk.split('\n');
+    const [commit, time] = lines[0].split(' ');
+    const entry: CommitDiff = { commit, authorTime: parseInt(time, 10) || 0, files: [] };
+    let file: CommitFileDiff | null = null;
+    // Between `diff --git` and the first hunk; after that `--- `/`+++ ` are content lines
+    let inHeader = false;
+    for (let i = 1; i < lines.length; i++) {
+      const line = lines[i];
+      if (line.startsWith('diff --git ')) {
+        // Defaults for diffs without ---/+++ lines (pure renames, mode changes)
+        const paths = splitDiffGitPaths(line.substring('diff --git '.length));
+        file = paths
+          ? { oldPath: stripDiffPrefix(paths[0]), path: stripDiffPrefix(paths[1]), hunks: [] }
+          : { path: null, oldPath: null, hunks: [] };
+        entry.files.push(file);
+        inHeader = true;
+      } else if (!file) {
+        continue;
+      } else if (inHeader && line.startsWith('--- ')) {
+        file.oldPath = stripDiffPrefix(line.substring(4));
+      } else if (inHeader && line.startsWith('+++ ')) {
+        file.path = stripDiffPrefix(line.substring(4));
+      } else if (inHeader && line.startsWith('rename from ')) {
+        file.oldPath = unquoteGitPath(line.substring('rename from '.length));
+      } else if (inHeader && line.startsWith('rename to ')) {
+        file.path = unquoteGitPath(line.substring('rename to '.length));
+      } else if (line.startsWith('@@')) {
+        inHeader = false;
+        const m = HUNK_HEADER.exec(line);
+        if (m) {
+          file.hunks.push({
+            oldStart: parseInt(m[1], 10),
+            oldCount: m[2] === undefined ? 1 : parseInt(m[2], 10),
+            newStart: parseInt(m[3], 10),
+            newCount: m[4] === undefined ? 1 : parseInt(m[4], 10),
+          });
+        }
+      }
+    }
+    commits.push(entry);
+  }
+  return commits;
+};
+
+export interface CommitInfo {
+  commit: string;
+  author: string;
+  authorEmail: string;
+  /** Author time, seconds since epoch */
+  authorTime: number;
+  subject: string;
+}
+
+/**
+ * Oldest commit reachable from `ref` that changed the number of matches of
+ * `pattern` (a POSIX extended regex) in `filePath` — as `git log -S` does,
+ * so for a declaration that is the commit that first added it. Without a
+ * pattern, the commit that added the file. Renames of the file are followed.
+ * Null when nothing matches.
+ */
+export const findIntroducingCommit = (
+  repoPath: string,
+  filePath: string,
+  pattern: string | null,
+  ref: string = 'HEAD',
+): CommitInfo | null => {
+  // --follow ignores --reverse, so the oldest commit is the last line
+  const args = ['log', '--follow', '--no-color', '--format=%H%x00%an%x00%ae%x00%at%x00%s'];
+  if (pattern) args.push('--pickaxe-regex', `-S${pattern}`);
+  else args.push('--diff-filter=A');
+  args.push(ref, '--', filePath);
+
+  const output = execFileSync('git', args, {
+    cwd: repoPath,
+    maxBuffer: 256 * 1024 * 1024,
+  }).toString();
+
+  const oldest = output.split('\n').filter(line => line.includes('\0')).pop();
+  if (!oldest) return null;
+  const [commit, author, authorEmail, time, subject] = oldest.split('\0');
+  return { commit, author, authorEmail, authorTime: parseInt(time, 10) || 0, subject: subject ?? '' };
+};
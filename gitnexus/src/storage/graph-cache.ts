@@ -0,0 +1,188 @@
+/**
+ * Graph Cache
+ *
+ * Built pipeline results serialized under .gitnexus/cache/, so commands that
+ * re-analyze a commit they've seen before (diff, breaking-change checks)
+ * load it instead of re-parsing the tree.
+ *
+ * Cache key:
+ *   - a commit: its sha
+ *   - the working tree: HEAD's sha when clean (and the graph is then built
+ *     from HEAD, so both share one entry), otherwise `tree-<hash>` of every
+ *     scanned path + content, so any edit, addition or deletion misses
+ *   - either way suffixed with a digest of the Go build context ($GOOS,
+ *     $GOARCH, $CGO_ENABLED decide which files are parsed), so a graph built
+ *     for one platform is never loaded for another
+ * Each entry also records GRAPH_CACHE_VERSION and the package version;
+ * entries written by another version are treated as misses and overwritten.
+ * Bump GRAPH_CACHE_VERSION whenever parsing or node properties change
+ * without a release.
+ *
+ * Entries are gzipped JSON, written to a temp file and renamed into place so
+ * a concurrent reader never sees a partial file. Unreadable entries are
+ * rebuilt.
+ */
+
+import fs from 'fs/promises';
+import path from 'path';
+import { promisify } from 'util';
+import { gzip, gunzip } from 'zlib';
+import { createHash } from 'crypto';
+import { createRequire } from 'node:module';
+import { runPipelineFromRepo } from '../core/ingestion/pipeline.js';
+import { createKnowledgeGraph } from '../core/graph/graph.js';
+import { createWorkingTreeSource } from '../core/ingestion/filesystem-walker.js';
+import { PipelineProgress, PipelineResult, SerializablePipelineResult } from '../types/pipeline.js';
+import { getStoragePath } from './repo-manager.js';
+import { getCurrentCommit, isWorkingTreeDirty } from './git.js';
+import { hashContent } from '../lib/utils.js';
+import { createBuildContext, describeBuildContext } from '../core/ingestion/build-constraints.js';
+
+const _require = createRequire(import.meta.url);
+const pkg = _require('../../package.json');
+
+/** Bump when graph contents change for the same source (parser, schema) */
+export const GRAPH_CACHE_VERSION = 11;
+
+const gzipAsync = promisify(gzip);
+const gunzipAsync = promisify(gunzip);
+
+/** Paths read per batch while hashing a dirty working tree */
+const HASH_BATCH_SIZE = 256;
+
+interface CacheEntry extends SerializablePipelineResult {
+  cacheVersion: number;
+  toolVersion: string;
+  key: string;
+  builtAt: string;
+  commit?: string;
+  communityResult?: PipelineResult['communityResult'];
+  processResult?: PipelineResult['processResult'];
+  externalCalls?: PipelineResult['externalCalls'];
+  parseErrors?: PipelineResult['parseErrors'];
+}
+
+export interface LoadOrBuildOptions {
+  onProgress?: (progress: PipelineProgress) => void;
+  /** Rebuild even when a valid entry exists (the new result is still cached) */
+  refresh?: boolean;
+}
+
+export interface LoadOrBuildResult {
+  result: PipelineResult;
+  key: string;
+  /** Loaded from cache rather than built */
+  cached: boolean;
+}
+
+export const getCacheDir = (repoPath: string): string =>
+  path.join(getStoragePath(repoPath), 'cache');
+
+/**
+ * Hash of the working tree as the pipeline would see it: scanned paths
+ * (ignore rules and size limit applied) and their contents.
+ */
+export const getFileSetHash = async (repoPath: string): Promise<string> => {
+  const source = createWorkingTreeSource(repoPath);
+  const paths = (await source.scan()).map(f => f.path).sort();
+  const hash = createHash('sha256');
+  for (let start = 0; start < paths.length; start += HASH_BATCH_SIZE) {
+    const batch = paths.slice(start, start + HASH_BATCH_SIZE);
+    const contents = await source.read(batch);
+    for (const p of batch) {
+      const content = contents.get(p);
+      hash.update(`${p}\0${content === undefined ? '-' : hashContent(content)}\n`);
+    }
+  }
+  return hash.digest('hex');
+};
+
+/**
+ * Cache key for a commit, or for the working tree when `sha` is omitted.
+ * `commit` is what to build from: the sha, HEAD for a clean tree, or
+ * undefined for a dirty one.
+ */
+export const getCacheKey = async (repoPath: string, sha?: string): Promise<{ key: string; commit?: string }> => {
+  // The pipeline builds with the environment's build context (see header)
+  const context = hashContent(describeBuildContext(createBuildContext())).slice(0, 12);
+  if (sha) return { key: `${sha}-${context}`, commit: sha };
+  const head = getCurrentCommit(repoPath);
+  if (head && !isWorkingTreeDirty(repoPath)) return { key: `${head}-${context}`, commit: head };
+  return { key: `tree-${await getFileSetHash(repoPath)}-${context}` };
+};
+
+const entryPath = (repoPath: string, key: string): string =>
+  path.join(getCacheDir(repoPath), `${key}.json.gz`);
+
+const readEntry = async (repoPath: string, key: string): Promise<PipelineResult | null> => {
+  try {
+    const raw = await gunzipAsync(await fs.readFile(entryPath(repoPath, key)));
+    const entry = JSON.parse(raw.toString('utf-8')) as CacheEntry;
+    if (entry.cacheVersion !== GRAPH_CACHE_VERSION || entry.toolVersion !== pkg.version || entry.key !== key) {
+      return null;
+    }
+    const graph = createKnowledgeGraph();
+    entry.nodes.forEach(node => graph.addNode(node));
+    entry.relationships.forEach(rel => graph.addRelationship(rel));
+    return {
+      graph,
+      repoPath,
+      totalFileCount: entry.totalFileCount,
+      ...(entry.communityResult ? { communityResult: entry.communityResult } : {}),
+      ...(entry.processResult ? { processResult: entry.processResult } : {}),
+      ...(entry.externalCalls ? { externalCalls: entry.externalCalls } : {}),
+      ...(entry.parseErrors ? { parseErrors: entry.parseErrors } : {}),
+      ...(entry.commit ? { commit: entry.commit } : {}),
+    };
+  } catch {
+    return null;
+  }
+};
+
+const writeEntry = async (repoPath: string, key: string, result: PipelineResult): Promise<void> => {
+  const entry: CacheEntry = {
+    cacheVersion: GRAPH_CACHE_VERSION,
+    toolVersion: pkg.version,
+    key,
+    builtAt: new Date().toISOString(),
+    nodes: [...result.graph.iterNodes()],
+    relationships: [...result.graph.iterRelationships()],
+    repoPath: result.repoPath,
+    totalFileCount: result.totalFileCount,
+    ...(result.commit ? { commit: result.commit } : {}),
+    ...(result.communityResult ? { communityResult: result.communityResult } : {}),
+    ...(result.processResult ? { processResult: result.processResult } : {}),
+    ...(result.externalCalls ? { externalCalls: result.externalCalls } : {}),
+    ...(result.parseErrors ? { parseErrors: result.parseErrors } : {}),
+  };
+  const target = entryPath(repoPath, key);
+  const tmp = `${target}.${process.pid}.tmp`;
+  await fs.mkdir(path.dirname(target), { recursive: true });
+  await fs.writeFile(tmp, await gzipAsync(JSON.stringify(entry)));
+  await fs.rename(tmp, target);
+};
+
+/**
+ * The pipeline result for `sha` (full commit sha; see resolveCommit), or for
+ * the working tree when omitted, from cache if a valid entry exists.
+ * Failing to write the cache never fails the build.
+ */
+export const loadOrBuild = async (
+  repoPath: string,
+  sha?: string,
+  options: LoadOrBuildOptions = {},
+): Promise<LoadOrBuildResult> => {
+  const { key, commit } = await getCacheKey(repoPath, sha);
+  if (!options.refresh) {
+    const cached = await readEntry(repoPath, key);
+    if (cached) return { result: cached, key, cached: true };
+  }
+
+  const result = await runPipelineFromRepo(repoPath, options.onProgress ?? (() => {}), commit ? { commit } : {});
+  try {
+    await writeEntry(repoPath, key, result);
+  } catch {
+    // Read-only checkout, disk full: the result is still good
+  }
+  return { result, key, cached: false };
+};
@@ -10,10 +10,28 @@ import fs from 'fs/promises';
 import path from 'path';
 import os from 'os';
 
+/**
+ * Analyze options that change what the index contains. An index built with
+ * other ones (or none, for a full default run) is out of date.
+ */
+export interface IndexOptions {
+  root?: string;
+  packages?: string[];
+  include?: string[];
+  exclude?: string[];
+  skipGenerated?: boolean;
+  goos?: string;
+  goarch?: string;
+  tags?: string[];
+  allVariants?: boolean;
+}
+
 export interface RepoMeta {
   repoPath: string;
   lastCommit: string;
   indexedAt: string;
+  /** Scope and build options of the run; absent for a default run */
+  indexOptions?: IndexOptions;
   stats?: {
     files?: number;
     nodes?: number;
@@ -67,6 +85,30 @@ export const getStoragePaths = (repoPath: string) => {
   };
 };
 
+/**
+ * Storage paths for an analysis of a past commit (`analyze --commit`), kept
+ * apart from the working-tree index under .gitnexus/commits/<sha>
+ */
+export const getCommitStoragePaths = (repoPath: string, commit: string) => {
+  const storagePath = path.join(getStoragePath(repoPath), 'commits', commit);
+  return {
+    storagePath,
+    kuzuPath: path.join(storagePath, 'kuzu'),
+    metaPath: path.join(storagePath, 'meta.json'),
+  };
+};
+
+/** Do two runs' index options produce the same index? Order of list entries doesn't matter. */
+export const sameIndexOptions = (a: IndexOptions = {}, b: IndexOptions = {}): boolean => {
+  const canonical = (options: IndexOptions) => JSON.stringify(
+    Object.entries(options)
+      .filter(([, value]) => value !== undefined && value !== false && !(Array.isArray(value) && value.length === 0))
+      .map(([key, value]) => [key, Array.isArray(value) ? [...value].sort() : value])
+      .sort(([x], [y]) => (x < y ? -1 : x > y ? 1 : 0)),
+  );
+  return canonical(a) === canonical(b);
+};
+
 /**
  * Load metadata from an indexed repo
  */
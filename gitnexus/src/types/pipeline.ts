@@ -1,6 +1,8 @@
 import { GraphNode, GraphRelationship, KnowledgeGraph } from '../core/graph/types.js';
 import { CommunityDetectionResult } from '../core/ingestion/community-processor.js';
 import { ProcessDetectionResult } from '../core/ingestion/process-processor.js';
+import { ExternalCall } from '../core/graph/call-graph.js';
+import type { FileParseError } from '../core/ingestion/workers/parse-worker.js';
 
 export type PipelinePhase = 'idle' | 'extracting' | 'structure' | 'parsing' | 'imports' | 'calls' | 'heritage' | 'communities' | 'processes' | 'enriching' | 'complete' | 'error';
 
@@ -25,6 +27,12 @@ export interface PipelineResult {
   totalFileCount: number;
   communityResult?: CommunityDetectionResult;
   processResult?: ProcessDetectionResult;
+  /** Calls with no target node (other packages, unresolved) — see getCallEdges */
+  externalCalls?: ExternalCall[];
+  /** Files that failed to parse, or parsed with syntax errors, sorted by path */
+  parseErrors?: FileParseError[];
+  /** Set when files were read from this commit instead of the working tree */
+  commit?: string;
 }
 
 // Serializable version for Web Worker communication